@@ -17,41 +17,73 @@ limitations under the License.
 package main
 
 import (
+	"bytes"
 	"compress/gzip"
 	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
 	"fmt"
 	"io"
-	"log"
+	"io/ioutil"
 	"net"
 	"net/http"
 	"net/http/pprof"
 	"os"
+	"os/signal"
+	"sort"
 	"strconv"
 	"strings"
+	"syscall"
+	"time"
 
-	"github.com/golang/glog"
 	"github.com/openshift/origin/pkg/util/proc"
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/prometheus/client_golang/prometheus/promhttp"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/prometheus/common/expfmt"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/labels"
+	utilnet "k8s.io/apimachinery/pkg/util/net"
 	clientset "k8s.io/client-go/kubernetes"
 	_ "k8s.io/client-go/plugin/pkg/client/auth"
+	"k8s.io/client-go/rest"
 	"k8s.io/client-go/tools/clientcmd"
 
 	kcollectors "k8s.io/kube-state-metrics/pkg/collectors"
+	"k8s.io/kube-state-metrics/pkg/customresource"
+	"k8s.io/kube-state-metrics/pkg/log"
+	"k8s.io/kube-state-metrics/pkg/metrics"
 	"k8s.io/kube-state-metrics/pkg/options"
 	"k8s.io/kube-state-metrics/pkg/version"
 )
 
 const (
-	metricsPath = "/metrics"
 	healthzPath = "/healthz"
+	readyzPath  = "/readyz"
+
+	collectorsPath = "/debug/collectors"
+
+	unixSocketPrefix = "unix://"
+
+	customResourceConfigDirPollInterval = 10 * time.Second
+
+	// watchCacheMetricsInterval is the interval the number of objects held by
+	// the collectors is updated in.
+	watchCacheMetricsInterval = 30 * time.Second
+
+	openMetricsContentType = "application/openmetrics-text"
+
+	// exitCodeInitialSyncTimeout is the exit code if the collectors do not
+	// sync within --initial-sync-timeout.
+	exitCodeInitialSyncTimeout = 3
 )
 
 // promLogger implements promhttp.Logger
 type promLogger struct{}
 
 func (pl promLogger) Println(v ...interface{}) {
-	glog.Error(v...)
+	log.Error(v...)
 }
 
 func main() {
@@ -60,7 +92,7 @@ func main() {
 
 	err := opts.Parse()
 	if err != nil {
-		glog.Fatalf("Error: %s", err)
+		log.Fatalf("Error: %s", err)
 	}
 
 	if opts.Version {
@@ -73,98 +105,437 @@ func main() {
 		os.Exit(0)
 	}
 
+	if opts.LogFormat == options.LogFormatJSON {
+		log.SetJSONOutput(os.Stderr)
+	}
+
+	// The context is cancelled on SIGTERM or interrupt, stopping the
+	// reflectors and triggering the shutdown of the HTTP servers.
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	sigs := make(chan os.Signal, 1)
+	signal.Notify(sigs, os.Interrupt, syscall.SIGTERM)
+	go func() {
+		sig := <-sigs
+		log.Infof("Received %v, shutting down", sig)
+		cancel()
+	}()
+
 	// TODO: Probably not necessary to pass all of opts into builder, right?
-	collectorBuilder := kcollectors.NewBuilder(context.TODO(), opts)
+	collectorBuilder := kcollectors.NewBuilder(ctx, opts)
 
 	if len(opts.Collectors) == 0 {
-		glog.Info("Using default collectors")
+		log.Info("Using default collectors")
 		collectorBuilder.WithEnabledCollectors(options.DefaultCollectors)
 	} else {
 		collectorBuilder.WithEnabledCollectors(opts.Collectors)
 	}
 
 	if len(opts.Namespaces) == 0 {
-		glog.Info("Using all namespace")
+		log.Info("Using all namespace")
 		collectorBuilder.WithNamespaces(options.DefaultNamespaces)
 	} else {
 		if opts.Namespaces.IsAllNamespaces() {
-			glog.Info("Using all namespace")
+			log.Info("Using all namespace")
 		} else {
-			glog.Infof("Using %s namespaces", opts.Namespaces)
+			log.Infof("Using %s namespaces", opts.Namespaces)
 		}
 		collectorBuilder.WithNamespaces(opts.Namespaces)
 	}
+	if len(opts.NamespacesDenylist) > 0 {
+		log.Infof("Excluding %s namespaces", opts.NamespacesDenylist)
+		collectorBuilder.WithNamespacesDenylist(opts.NamespacesDenylist)
+	}
+
+	labelSelector, err := labels.Parse(opts.LabelSelector)
+	if err != nil {
+		log.Fatalf("Failed to parse label selector: %v", err)
+	}
+	if !labelSelector.Empty() {
+		log.Infof("Only watching objects matching the label selector %q", labelSelector.String())
+	}
+	collectorBuilder.WithLabelSelector(labelSelector)
+
+	fieldSelector, err := fields.ParseSelector(opts.FieldSelector)
+	if err != nil {
+		log.Fatalf("Failed to parse field selector: %v", err)
+	}
+	if !fieldSelector.Empty() {
+		log.Infof("Only watching pods and nodes matching the field selector %q", fieldSelector.String())
+	}
+	collectorBuilder.WithFieldSelector(fieldSelector)
+
+	if opts.ResourceName != "" {
+		log.Infof("Only watching the object named %q", opts.ResourceName)
+		collectorBuilder.WithResourceName(opts.ResourceName)
+	}
+
+	if opts.ResourceScope != options.ResourceScopeAll {
+		log.Infof("Only enabling the collectors of %s resources", opts.ResourceScope)
+	}
+	collectorBuilder.WithResourceScope(opts.ResourceScope)
+
+	if opts.ResyncPeriod > 0 {
+		log.Infof("Relisting all objects every %v", opts.ResyncPeriod)
+	}
+	collectorBuilder.WithResyncPeriod(opts.ResyncPeriod)
 
 	if opts.MetricWhitelist.IsEmpty() && opts.MetricBlacklist.IsEmpty() {
-		glog.Info("No metric whitelist or blacklist set. No filtering of metrics will be done.")
+		log.Info("No metric whitelist or blacklist set. No filtering of metrics will be done.")
 	}
 	if !opts.MetricWhitelist.IsEmpty() && !opts.MetricBlacklist.IsEmpty() {
-		glog.Fatal("Whitelist and blacklist are both set. They are mutually exclusive, only one of them can be set.")
+		log.Fatal("Whitelist and blacklist are both set. They are mutually exclusive, only one of them can be set.")
 	}
 	if !opts.MetricWhitelist.IsEmpty() {
-		glog.Infof("A metric whitelist has been configured. Only the following metrics will be exposed: %s.", opts.MetricWhitelist.String())
+		log.Infof("A metric whitelist has been configured. Only the following metrics will be exposed: %s.", opts.MetricWhitelist.String())
 	}
 	if !opts.MetricBlacklist.IsEmpty() {
-		glog.Infof("A metric blacklist has been configured. The following metrics will not be exposed: %s.", opts.MetricBlacklist.String())
+		log.Infof("A metric blacklist has been configured. The following metrics will not be exposed: %s.", opts.MetricBlacklist.String())
 	}
 
+	metricWhitelist, err := opts.MetricWhitelist.Matcher(opts.MetricNameRegex)
+	if err != nil {
+		log.Fatalf("Failed to compile metric whitelist: %v", err)
+	}
+	collectorBuilder.WithMetricWhitelist(metricWhitelist)
+
+	metricBlacklist, err := opts.MetricBlacklist.Matcher(opts.MetricNameRegex)
+	if err != nil {
+		log.Fatalf("Failed to compile metric blacklist: %v", err)
+	}
+	collectorBuilder.WithMetricBlacklist(metricBlacklist)
+
+	if opts.MetricPrefix != "" {
+		log.Infof("Prefixing all metric names with %q", opts.MetricPrefix)
+	}
+	collectorBuilder.WithMetricPrefix(opts.MetricPrefix)
+
+	if len(opts.MetricLabels) > 0 {
+		log.Infof("Adding the labels %s to all metrics", opts.MetricLabels.String())
+	}
+	collectorBuilder.WithMetricLabels(opts.MetricLabels)
+
+	if opts.EnableMetricTimestamps {
+		log.Info("Exposing metrics with the time their object was last observed as timestamp")
+	}
+	collectorBuilder.WithMetricTimestamps(opts.EnableMetricTimestamps)
+
+	if opts.DisableCreatedMetrics {
+		log.Info("Not exposing the creation timestamp metrics")
+	}
+	collectorBuilder.WithCreatedMetricsDisabled(opts.DisableCreatedMetrics)
+
+	if opts.TotalShards > 1 {
+		if opts.PodName != "" {
+			log.Infof("Using shard %d of %d in pod %s/%s", opts.Shard, opts.TotalShards, opts.PodNamespace, opts.PodName)
+		} else {
+			log.Infof("Using shard %d of %d", opts.Shard, opts.TotalShards)
+		}
+	}
+	collectorBuilder.WithSharding(opts.Shard, opts.TotalShards)
+
 	proc.StartReaper()
 
-	kubeClient, err := createKubeClient(opts.Apiserver, opts.Kubeconfig)
+	if len(opts.KubeconfigContexts) == 0 {
+		kubeClient, restConfig, err := createKubeClient(opts, "")
+		if err != nil {
+			log.Fatalf("Failed to create client: %v", err)
+		}
+		collectorBuilder.WithKubeClient(kubeClient)
+		collectorBuilder.WithRESTConfig(restConfig)
+	}
+	for _, context := range opts.KubeconfigContexts {
+		kubeClient, restConfig, err := createKubeClient(opts, context)
+		if err != nil {
+			log.Fatalf("Failed to create client for context %s: %v", context, err)
+		}
+		collectorBuilder.AddCluster(context, kubeClient, restConfig)
+	}
+
+	if opts.CustomResourceConfig != "" {
+		customResources, err := customresource.LoadConfig(opts.CustomResourceConfig)
+		if err != nil {
+			log.Fatalf("Failed to load custom resource config: %v", err)
+		}
+		collectorBuilder.WithCustomResources(customResources)
+	}
+
+	collectorBuilder.CheckPermissions()
+
+	if opts.DisableGolangTelemetry {
+		log.Info("Not exposing go_* and process_* self metrics")
+	}
+	ksmMetricsRegistry := newTelemetryRegistry(opts.DisableGolangTelemetry)
+
+	registry := kcollectors.NewRegistry(collectorBuilder.Build())
+
+	if opts.CustomResourceConfigDir != "" {
+		watcher := customresource.NewDirWatcher(opts.CustomResourceConfigDir, func(path string, c *customresource.Config) {
+			if c == nil {
+				log.Infof("Removing custom resource collectors of %s", path)
+				registry.Delete(path)
+				return
+			}
+			log.Infof("Loading custom resource collectors of %s", path)
+			collectorCtx, stop := context.WithCancel(ctx)
+			registry.Set(path, collectorBuilder.BuildCustomResourceCollectors(collectorCtx, c), stop)
+		})
+		if err := watcher.Sync(); err != nil {
+			log.Fatalf("Failed to read custom resource config directory: %v", err)
+		}
+		go watcher.Run(ctx, customResourceConfigDirPollInterval)
+	}
+
+	go registry.RunWatchCacheMetrics(ctx, watchCacheMetricsInterval)
+
+	servers := []*http.Server{
+		telemetryServer(ksmMetricsRegistry, opts.TelemetryHost, opts.TelemetryPort, opts.TelemetryPath),
+		metricsServer(registry, opts, opts.Host, opts.Port),
+	}
+
+	tlsConfig, err := createTLSConfig(opts.TLSClientCAFile)
 	if err != nil {
-		glog.Fatalf("Failed to create client: %v", err)
+		log.Fatalf("Failed to create TLS config: %v", err)
 	}
-	collectorBuilder.WithKubeClient(kubeClient)
 
-	ksmMetricsRegistry := prometheus.NewRegistry()
-	ksmMetricsRegistry.Register(kcollectors.ResourcesPerScrapeMetric)
-	ksmMetricsRegistry.Register(kcollectors.ScrapeErrorTotalMetric)
-	ksmMetricsRegistry.Register(prometheus.NewProcessCollector(os.Getpid(), ""))
-	ksmMetricsRegistry.Register(prometheus.NewGoCollector())
-	go telemetryServer(ksmMetricsRegistry, opts.TelemetryHost, opts.TelemetryPort)
+	errs := make(chan error, len(servers))
+	for _, server := range servers {
+		server.TLSConfig = tlsConfig
+		listener, err := listen(server.Addr, os.FileMode(opts.SocketMode))
+		if err != nil {
+			log.Fatalf("Failed to listen on %s: %v", server.Addr, err)
+		}
+		// Log the resolved address, which differs from the configured one
+		// when listening on port 0.
+		log.Infof("Listening on %s", listener.Addr())
+		go func(server *http.Server, listener net.Listener) {
+			var err error
+			if opts.TLSCertFile != "" {
+				err = server.ServeTLS(listener, opts.TLSCertFile, opts.TLSPrivateKeyFile)
+			} else {
+				err = server.Serve(listener)
+			}
+			if err != http.ErrServerClosed {
+				errs <- err
+			}
+		}(server, listener)
+	}
+
+	syncErrs := make(chan error, 1)
+	if opts.InitialSyncTimeout > 0 {
+		go func() {
+			if err := registry.WaitForSync(ctx, opts.InitialSyncTimeout); err != nil && ctx.Err() == nil {
+				syncErrs <- err
+			}
+		}()
+	}
 
-	collectors := collectorBuilder.Build()
+	select {
+	case err := <-errs:
+		log.Fatalf("Failed to serve: %v", err)
+	case err := <-syncErrs:
+		// Exit with a distinct code, making e.g. missing RBAC permissions
+		// obvious from the crash-looping pod instead of running without
+		// exposing any metrics.
+		log.Errorf("Failed initial sync: %v", err)
+		os.Exit(exitCodeInitialSyncTimeout)
+	case <-ctx.Done():
+	}
 
-	// TODO: Reenable white and blacklisting
-	// metricsServer(metrics.FilteredGatherer(registry, opts.MetricWhitelist, opts.MetricBlacklist), opts.Host, opts.Port)
-	serveMetrics(collectors, opts.Host, opts.Port)
+	// Give in-flight scrapes the grace period to complete. Shutting down
+	// closes the listeners, which removes Unix domain socket files.
+	shutdownCtx, shutdownCancel := context.WithTimeout(context.Background(), opts.ShutdownGracePeriod)
+	defer shutdownCancel()
+	for _, server := range servers {
+		if err := server.Shutdown(shutdownCtx); err != nil {
+			log.Errorf("Failed to shut down server %s gracefully: %v", server.Addr, err)
+		}
+	}
 }
 
-func createKubeClient(apiserver string, kubeconfig string) (clientset.Interface, error) {
-	config, err := clientcmd.BuildConfigFromFlags(apiserver, kubeconfig)
+// createKubeClient creates a client for the cluster of the given context of the
+// kubeconfig file, or of its current context if context is empty.
+func createKubeClient(opts *options.Options, context string) (clientset.Interface, *rest.Config, error) {
+	var config *rest.Config
+	var err error
+	if context == "" {
+		config, err = clientcmd.BuildConfigFromFlags(opts.Apiserver, opts.Kubeconfig)
+	} else {
+		rules := clientcmd.NewDefaultClientConfigLoadingRules()
+		rules.ExplicitPath = opts.Kubeconfig
+		overrides := &clientcmd.ConfigOverrides{CurrentContext: context}
+		config, err = clientcmd.NewNonInteractiveDeferredLoadingClientConfig(rules, overrides).ClientConfig()
+	}
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 
 	config.UserAgent = version.GetVersion().String()
+	config.QPS = opts.APIServerQPS
+	if config.QPS == 0 {
+		config.QPS = rest.DefaultQPS
+	}
+	config.Burst = opts.APIServerBurst
+	if config.Burst == 0 {
+		config.Burst = rest.DefaultBurst
+	}
+	config.Timeout = opts.APIServerTimeout
+	log.Infof("Using apiserver QPS %v, burst %d and timeout %v", config.QPS, config.Burst, config.Timeout)
+
+	if opts.ImpersonateUser != "" {
+		config.Impersonate = impersonationConfig(opts)
+		log.Infof("Impersonating user %q with groups %v", config.Impersonate.UserName, config.Impersonate.Groups)
+	}
+	if opts.ImpersonateUID != "" {
+		config.WrapTransport = wrapImpersonateUID(opts.ImpersonateUID, config.WrapTransport)
+		log.Infof("Impersonating UID %q", opts.ImpersonateUID)
+	}
+
+	// Resources without a typed client are requested as JSON, hence the
+	// config is returned before it is tailored to the built-in resources.
+	restConfig := rest.CopyConfig(config)
 	config.AcceptContentTypes = "application/vnd.kubernetes.protobuf,application/json"
 	config.ContentType = "application/vnd.kubernetes.protobuf"
 
 	kubeClient, err := clientset.NewForConfig(config)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 
 	// Informers don't seem to do a good job logging error messages when it
 	// can't reach the server, making debugging hard. This makes it easier to
 	// figure out if apiserver is configured incorrectly.
-	glog.Infof("Testing communication with server")
+	log.Infof("Testing communication with server")
 	v, err := kubeClient.Discovery().ServerVersion()
 	if err != nil {
-		return nil, fmt.Errorf("ERROR communicating with apiserver: %v", err)
+		return nil, nil, fmt.Errorf("ERROR communicating with apiserver: %v", err)
 	}
-	glog.Infof("Running with Kubernetes cluster version: v%s.%s. git version: %s. git tree state: %s. commit: %s. platform: %s",
+	log.Infof("Running with Kubernetes cluster version: v%s.%s. git version: %s. git tree state: %s. commit: %s. platform: %s",
 		v.Major, v.Minor, v.GitVersion, v.GitTreeState, v.GitCommit, v.Platform)
-	glog.Infof("Communication with server successful")
+	log.Infof("Communication with server successful")
+
+	return kubeClient, restConfig, nil
+}
+
+// impersonationConfig returns the config impersonating the user and groups set
+// with --as and --as-group.
+func impersonationConfig(opts *options.Options) rest.ImpersonationConfig {
+	return rest.ImpersonationConfig{
+		UserName: opts.ImpersonateUser,
+		Groups:   opts.ImpersonateGroups,
+	}
+}
+
+// impersonateUIDHeader is the header impersonating the UID of the user, which
+// is not supported by the impersonation config of the vendored client.
+const impersonateUIDHeader = "Impersonate-Uid"
+
+// impersonateUIDRoundTripper sets the UID set with --as-uid on every request.
+type impersonateUIDRoundTripper struct {
+	uid string
+	rt  http.RoundTripper
+}
+
+func (r *impersonateUIDRoundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	req = utilnet.CloneRequest(req)
+	req.Header.Set(impersonateUIDHeader, r.uid)
+	return r.rt.RoundTrip(req)
+}
 
-	return kubeClient, nil
+// wrapImpersonateUID returns a function for rest.Config.WrapTransport setting
+// the impersonated UID on every request, after applying wrap, if not nil.
+func wrapImpersonateUID(uid string, wrap func(http.RoundTripper) http.RoundTripper) func(http.RoundTripper) http.RoundTripper {
+	return func(rt http.RoundTripper) http.RoundTripper {
+		if wrap != nil {
+			rt = wrap(rt)
+		}
+		return &impersonateUIDRoundTripper{uid: uid, rt: rt}
+	}
+}
+
+// joinHostPort combines host and port into an address to listen on. Unix
+// domain socket addresses are returned as is, ignoring the port. IPv6 hosts
+// may be given with or without brackets, e.g. ::1 or [::1].
+func joinHostPort(host string, port int) string {
+	if strings.HasPrefix(host, unixSocketPrefix) {
+		return host
+	}
+	host = strings.TrimSuffix(strings.TrimPrefix(host, "["), "]")
+	return net.JoinHostPort(host, strconv.Itoa(port))
 }
 
-func telemetryServer(registry prometheus.Gatherer, host string, port int) {
+// listen announces on the given address. Addresses of the form
+// unix:///path/to/socket create a Unix domain socket with the given file mode,
+// all other addresses are treated as TCP host and port.
+func listen(address string, socketMode os.FileMode) (net.Listener, error) {
+	if !strings.HasPrefix(address, unixSocketPrefix) {
+		return net.Listen("tcp", address)
+	}
+
+	path := strings.TrimPrefix(address, unixSocketPrefix)
+	listener, err := net.Listen("unix", path)
+	if err != nil {
+		return nil, err
+	}
+	if err := os.Chmod(path, socketMode); err != nil {
+		listener.Close()
+		return nil, err
+	}
+	return listener, nil
+}
+
+// createTLSConfig returns the TLS config requiring and verifying client
+// certificates against the certificate authorities in clientCAFile. If
+// clientCAFile is empty, nil is returned, resulting in the default config.
+func createTLSConfig(clientCAFile string) (*tls.Config, error) {
+	if clientCAFile == "" {
+		return nil, nil
+	}
+
+	pem, err := ioutil.ReadFile(clientCAFile)
+	if err != nil {
+		return nil, err
+	}
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(pem) {
+		return nil, fmt.Errorf("no certificates found in %s", clientCAFile)
+	}
+
+	return &tls.Config{
+		ClientAuth: tls.RequireAndVerifyClientCert,
+		ClientCAs:  pool,
+	}, nil
+}
+
+// newTelemetryRegistry returns the registry of the self metrics of
+// kube-state-metrics. Unless disableGolangTelemetry is set, it includes the
+// go_* and process_* metrics.
+func newTelemetryRegistry(disableGolangTelemetry bool) *prometheus.Registry {
+	registry := prometheus.NewRegistry()
+	registry.Register(kcollectors.ResourcesPerScrapeMetric)
+	registry.Register(kcollectors.ScrapeErrorTotalMetric)
+	registry.Register(kcollectors.PermissionDeniedTotalMetric)
+	registry.Register(kcollectors.CollectDurationSecondsMetric)
+	registry.Register(kcollectors.CollectorPanicTotalMetric)
+	registry.Register(kcollectors.ScrapeTimeoutTotalMetric)
+	registry.Register(kcollectors.InFlightScrapesMetric)
+	registry.Register(kcollectors.SeriesMetric)
+	registry.Register(kcollectors.TotalSeriesMetric)
+	registry.Register(kcollectors.WatchCacheObjectsMetric)
+	registry.Register(version.NewBuildInfoCollector())
+	if !disableGolangTelemetry {
+		registry.Register(prometheus.NewProcessCollector(os.Getpid(), ""))
+		registry.Register(prometheus.NewGoCollector())
+	}
+	return registry
+}
+
+func telemetryServer(registry prometheus.Gatherer, host string, port int, metricsPath string) *http.Server {
 	// Address to listen on for web interface and telemetry
-	listenAddress := net.JoinHostPort(host, strconv.Itoa(port))
+	listenAddress := joinHostPort(host, port)
 
-	glog.Infof("Starting kube-state-metrics self metrics server: %s", listenAddress)
+	log.Infof("Starting kube-state-metrics self metrics server: %s", listenAddress)
 
 	mux := http.NewServeMux()
 
@@ -182,19 +553,21 @@ func telemetryServer(registry prometheus.Gatherer, host string, port int) {
              </body>
              </html>`))
 	})
-	log.Fatal(http.ListenAndServe(listenAddress, mux))
+	return &http.Server{Addr: listenAddress, Handler: mux}
 }
 
 // TODO: How about accepting an interface Collector instead?
-func serveMetrics(collectors []*kcollectors.Collector, host string, port int) {
+func metricsServer(registry *kcollectors.Registry, opts *options.Options, host string, port int) *http.Server {
 	// Address to listen on for web interface and telemetry
-	listenAddress := net.JoinHostPort(host, strconv.Itoa(port))
+	listenAddress := joinHostPort(host, port)
+
+	log.Infof("Starting metrics server: %s", listenAddress)
 
-	glog.Infof("Starting metrics server: %s", listenAddress)
+	metricsPath := opts.MetricsPath
 
 	mux := http.NewServeMux()
 
-	// TODO: This doesn't belong into serveMetrics
+	// TODO: This doesn't belong into metricsServer
 	mux.Handle("/debug/pprof/", http.HandlerFunc(pprof.Index))
 	mux.Handle("/debug/pprof/cmdline", http.HandlerFunc(pprof.Cmdline))
 	mux.Handle("/debug/pprof/profile", http.HandlerFunc(pprof.Profile))
@@ -202,12 +575,27 @@ func serveMetrics(collectors []*kcollectors.Collector, host string, port int) {
 	mux.Handle("/debug/pprof/trace", http.HandlerFunc(pprof.Trace))
 
 	// Add metricsPath
-	mux.Handle(metricsPath, &metricHandler{collectors})
+	mux.Handle(metricsPath, newMetricHandler(registry, opts))
+	// Add the paths of --metrics-path-map
+	for _, path := range opts.MetricsPathMap.Paths() {
+		switch path {
+		case metricsPath:
+			continue
+		case healthzPath, readyzPath, collectorsPath:
+			log.Fatalf("Cannot expose metrics on reserved path %s", path)
+		}
+		log.Infof("Exposing the metrics of collectors mapped to %s on %s", path, path)
+		mux.Handle(path, newPathMetricHandler(registry, opts, path))
+	}
 	// Add healthzPath
 	mux.HandleFunc(healthzPath, func(w http.ResponseWriter, r *http.Request) {
 		w.WriteHeader(200)
 		w.Write([]byte("ok"))
 	})
+	// Add readyzPath
+	mux.Handle(readyzPath, newReadyzHandler(registry))
+	// Add collectorsPath
+	mux.Handle(collectorsPath, newCollectorsHandler(registry))
 	// Add index
 	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
 		w.Write([]byte(`<html>
@@ -217,22 +605,161 @@ func serveMetrics(collectors []*kcollectors.Collector, host string, port int) {
 			 <ul>
              <li><a href='` + metricsPath + `'>metrics</a></li>
              <li><a href='` + healthzPath + `'>healthz</a></li>
+             <li><a href='` + readyzPath + `'>readyz</a></li>
+             <li><a href='` + collectorsPath + `'>collectors</a></li>
 			 </ul>
              </body>
              </html>`))
 	})
-	log.Fatal(http.ListenAndServe(listenAddress, mux))
+	return &http.Server{Addr: listenAddress, Handler: mux}
+}
+
+// newReadyzHandler returns a handler responding with 503 until all collectors
+// have completed the initial list of their objects, so that no incomplete
+// metrics are scraped after a restart.
+func newReadyzHandler(registry *kcollectors.Registry) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !registry.HasSynced() {
+			http.Error(w, "collectors not synced", http.StatusServiceUnavailable)
+			return
+		}
+		w.WriteHeader(200)
+		w.Write([]byte("ok"))
+	})
+}
+
+// collectorInfo describes an enabled collector in the response of the
+// collectors handler.
+type collectorInfo struct {
+	Name    string   `json:"name"`
+	Metrics []string `json:"metrics"`
+}
+
+// newCollectorsHandler returns a handler responding with the enabled
+// collectors and the names of the metrics they currently expose as JSON, to
+// verify the effect of --collectors, the metric white- and blacklist and the
+// custom resource config.
+func newCollectorsHandler(registry *kcollectors.Registry) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		infos := []collectorInfo{}
+		for _, c := range registry.Collectors() {
+			infos = append(infos, collectorInfo{Name: c.Name(), Metrics: c.MetricNames()})
+		}
+		sort.SliceStable(infos, func(i, j int) bool { return infos[i].Name < infos[j].Name })
+
+		w.Header().Set("Content-Type", "application/json")
+		if err := json.NewEncoder(w).Encode(infos); err != nil {
+			log.Errorf("Failed to encode collectors: %v", err)
+		}
+	})
 }
 
 type metricHandler struct {
-	c []*kcollectors.Collector
+	registry *kcollectors.Registry
+	// path is the path the handler is served on. Only the collectors mapped
+	// to it by --metrics-path-map, or unmapped ones if it is the metrics
+	// path, are exposed.
+	path          string
+	metricsPath   string
+	pathMap       options.MetricsPathMap
+	outputFormat  string
+	scrapeTimeout time.Duration
+	// scrapeSlots limits the number of concurrent scrapes, if not nil.
+	scrapeSlots chan struct{}
+}
+
+func newMetricHandler(registry *kcollectors.Registry, opts *options.Options) *metricHandler {
+	return newPathMetricHandler(registry, opts, opts.MetricsPath)
+}
+
+// newPathMetricHandler returns a handler exposing the metrics of the
+// collectors served on the given path.
+func newPathMetricHandler(registry *kcollectors.Registry, opts *options.Options, path string) *metricHandler {
+	m := &metricHandler{
+		registry:      registry,
+		path:          path,
+		metricsPath:   opts.MetricsPath,
+		pathMap:       opts.MetricsPathMap,
+		outputFormat:  opts.OutputFormat,
+		scrapeTimeout: opts.ScrapeTimeout,
+	}
+	if opts.MaxConcurrentScrapes > 0 {
+		m.scrapeSlots = make(chan struct{}, opts.MaxConcurrentScrapes)
+	}
+	return m
+}
+
+// serves reports whether the handler exposes the metrics of the given
+// collector.
+func (m *metricHandler) serves(collector string) bool {
+	path, ok := m.pathMap[collector]
+	if !ok {
+		path = m.metricsPath
+	}
+	return path == m.path
 }
 
 func (m *metricHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if m.scrapeSlots != nil {
+		select {
+		case m.scrapeSlots <- struct{}{}:
+			defer func() { <-m.scrapeSlots }()
+		default:
+			http.Error(w, "too many concurrent scrapes", http.StatusTooManyRequests)
+			return
+		}
+	}
+
+	kcollectors.InFlightScrapesMetric.Inc()
+	defer kcollectors.InFlightScrapesMetric.Dec()
+
 	resHeader := w.Header()
 	var writer io.Writer = w
 
-	resHeader.Set("Content-Type", `text/plain; version=`+"0.0.4")
+	ctx := r.Context()
+	if m.scrapeTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, m.scrapeTimeout)
+		defer cancel()
+	}
+
+	ms, err := m.collect(ctx)
+	if err != nil {
+		if err == context.DeadlineExceeded {
+			kcollectors.ScrapeTimeoutTotalMetric.Inc()
+		}
+		http.Error(w, fmt.Sprintf("failed to collect metrics: %v", err), http.StatusServiceUnavailable)
+		return
+	}
+
+	// An explicit request for OpenMetrics or JSON overrides the configured
+	// output format.
+	format := m.outputFormat
+	switch accept := r.Header.Get("Accept"); {
+	case strings.Contains(accept, openMetricsContentType):
+		format = options.OutputFormatOpenMetrics
+	case strings.Contains(accept, "application/json"):
+		format = options.OutputFormatJSON
+	}
+
+	// Only JSON requires parsing the rendered metrics, the text and
+	// OpenMetrics formats are streamed as rendered.
+	var families []*dto.MetricFamily
+	if format == options.OutputFormatJSON {
+		families, err = gatherMetricFamilies(ms)
+		if err != nil {
+			http.Error(w, fmt.Sprintf("failed to gather metric families: %v", err), http.StatusInternalServerError)
+			return
+		}
+	}
+	switch format {
+	case options.OutputFormatJSON:
+		resHeader.Set("Content-Type", "application/json")
+	case options.OutputFormatOpenMetrics:
+		resHeader.Set("Content-Type", openMetricsContentType+"; version=0.0.1; charset=utf-8")
+	default:
+		resHeader.Set("Content-Type", `text/plain; version=`+"0.0.4")
+	}
 
 	// Gzip response if requested. Taken from
 	// github.com/prometheus/client_golang/prometheus/promhttp.decorateWriter.
@@ -243,17 +770,21 @@ func (m *metricHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 		if part == "gzip" || strings.HasPrefix(part, "gzip;") {
 			writer = gzip.NewWriter(writer)
 			resHeader.Set("Content-Encoding", "gzip")
+			break
 		}
 	}
 
-	for _, c := range m.c {
-		for _, m := range c.Collect() {
-			_, err := fmt.Fprint(writer, *m)
-			if err != nil {
-				// TODO: Handle panic
-				panic(err)
-			}
+	switch format {
+	case options.OutputFormatJSON:
+		if err := json.NewEncoder(writer).Encode(families); err != nil {
+			log.Errorf("Failed to write JSON response: %v", err)
+		}
+	case options.OutputFormatOpenMetrics:
+		if err := writeOpenMetrics(writer, ms); err != nil {
+			log.Errorf("Failed to write OpenMetrics response: %v", err)
 		}
+	default:
+		writeText(writer, ms)
 	}
 
 	// In case we gziped the response, we have to close the writer.
@@ -261,3 +792,118 @@ func (m *metricHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 		closer.Close()
 	}
 }
+
+// collect returns the metrics of all collectors. It gives up once the given
+// context is done, in which case the context error is returned.
+func (m *metricHandler) collect(ctx context.Context) ([]*metrics.Metric, error) {
+	result := make(chan []*metrics.Metric, 1)
+	go func() {
+		ms := []*metrics.Metric{}
+		// Collectors of the same resource in different clusters share a name
+		// and are counted together.
+		counts := map[string]int{}
+		for _, c := range m.registry.Collectors() {
+			if ctx.Err() != nil {
+				return
+			}
+			if !m.serves(c.Name()) {
+				continue
+			}
+			cms := c.Collect()
+			counts[c.Name()] += len(cms)
+			ms = append(ms, cms...)
+		}
+		kcollectors.UpdateSeriesMetrics(m.path, counts)
+		result <- ms
+	}()
+
+	select {
+	case ms := <-result:
+		return ms, nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+func writeText(writer io.Writer, ms []*metrics.Metric) {
+	for _, m := range ms {
+		_, err := fmt.Fprint(writer, *m)
+		if err != nil {
+			// TODO: Handle panic
+			panic(err)
+		}
+	}
+}
+
+// writeOpenMetrics writes the given pre-rendered metrics in the OpenMetrics
+// text format. The rendered samples are valid OpenMetrics samples, except for
+// their timestamps, which are in seconds instead of milliseconds. As the
+// samples of a family must not be interleaved with other families, but are
+// rendered per object, they are grouped by name. No TYPE lines are written.
+func writeOpenMetrics(writer io.Writer, ms []*metrics.Metric) error {
+	families := map[string][]*metrics.Metric{}
+	names := []string{}
+	for _, m := range ms {
+		name := m.Name()
+		if _, ok := families[name]; !ok {
+			names = append(names, name)
+		}
+		families[name] = append(families[name], m)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		for _, m := range families[name] {
+			if _, err := io.WriteString(writer, openMetricsSample(string(*m))); err != nil {
+				return err
+			}
+		}
+	}
+	_, err := io.WriteString(writer, "# EOF\n")
+	return err
+}
+
+// openMetricsSample converts the timestamp of the given rendered sample, if
+// any, from milliseconds to seconds.
+func openMetricsSample(s string) string {
+	// The value and timestamp follow the label set or, if there are no
+	// labels, the name. Label values may contain spaces, but no unescaped
+	// quotes, hence the label set ends at the last closing brace.
+	start := strings.LastIndexByte(s, '}') + 1
+	if start == 0 {
+		start = strings.IndexByte(s, ' ')
+	}
+	fields := strings.Fields(s[start:])
+	if len(fields) != 2 {
+		return s
+	}
+	ms, err := strconv.ParseInt(fields[1], 10, 64)
+	if err != nil {
+		return s
+	}
+	return s[:start] + " " + fields[0] + " " + strconv.FormatFloat(float64(ms)/1000, 'f', -1, 64) + "\n"
+}
+
+// gatherMetricFamilies parses the given pre-rendered metrics back into metric
+// families, sorted by name. As the rendered metrics don't carry any HELP or
+// TYPE information, all families are untyped.
+func gatherMetricFamilies(ms []*metrics.Metric) ([]*dto.MetricFamily, error) {
+	var buf bytes.Buffer
+	writeText(&buf, ms)
+
+	var parser expfmt.TextParser
+	familiesByName, err := parser.TextToMetricFamilies(&buf)
+	if err != nil {
+		return nil, err
+	}
+
+	families := make([]*dto.MetricFamily, 0, len(familiesByName))
+	for _, family := range familiesByName {
+		families = append(families, family)
+	}
+	sort.Slice(families, func(i, j int) bool {
+		return families[i].GetName() < families[j].GetName()
+	})
+
+	return families, nil
+}
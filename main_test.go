@@ -19,17 +19,33 @@ package main
 import (
 	// "fmt"
 	// "io/ioutil"
+	"compress/gzip"
 	"context"
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"net"
+	"net/http"
 	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
 	"strconv"
+	"strings"
 	"testing"
 	"time"
 
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/prometheus/common/expfmt"
 	"k8s.io/kube-state-metrics/pkg/options"
 
 	"k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/rest"
+	k8stesting "k8s.io/client-go/testing"
 	kcollectors "k8s.io/kube-state-metrics/pkg/collectors"
 )
 
@@ -58,7 +74,7 @@ func BenchmarkKubeStateMetrics(t *testing.B) {
 
 	collectors := builder.Build()
 
-	handler := metricHandler{collectors}
+	handler := newMetricHandler(kcollectors.NewRegistry(collectors), opts)
 
 	req := httptest.NewRequest("GET", "http://localhost:8080/metrics", nil)
 
@@ -79,6 +95,710 @@ func BenchmarkKubeStateMetrics(t *testing.B) {
 	// fmt.Println(string(body))
 }
 
+func TestMetricHandlerJSON(t *testing.T) {
+	kubeClient := fake.NewSimpleClientset()
+	if err := configMap(kubeClient, 0); err != nil {
+		t.Fatalf("error injecting resources: %v", err)
+	}
+
+	opts := options.NewOptions()
+
+	builder := kcollectors.NewBuilder(context.TODO(), opts)
+	builder.WithEnabledCollectors(options.CollectorSet{"configmaps": struct{}{}})
+	builder.WithKubeClient(kubeClient)
+	builder.WithNamespaces(options.DefaultNamespaces)
+
+	collectors := builder.Build()
+
+	// Wait for informers to sync
+	time.Sleep(time.Second)
+
+	tests := []struct {
+		Desc         string
+		OutputFormat string
+		Accept       string
+		WantJSON     bool
+	}{
+		{
+			Desc:         "text output format",
+			OutputFormat: options.OutputFormatText,
+			WantJSON:     false,
+		},
+		{
+			Desc:         "json output format",
+			OutputFormat: options.OutputFormatJSON,
+			WantJSON:     true,
+		},
+		{
+			Desc:         "text output format with json accept header",
+			OutputFormat: options.OutputFormatText,
+			Accept:       "application/json",
+			WantJSON:     true,
+		},
+	}
+
+	for _, test := range tests {
+		opts.OutputFormat = test.OutputFormat
+		handler := newMetricHandler(kcollectors.NewRegistry(collectors), opts)
+
+		req := httptest.NewRequest("GET", "http://localhost:8080/metrics", nil)
+		if test.Accept != "" {
+			req.Header.Set("Accept", test.Accept)
+		}
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, req)
+
+		resp := w.Result()
+		body, err := ioutil.ReadAll(resp.Body)
+		if err != nil {
+			t.Fatalf("%s: failed to read response body: %v", test.Desc, err)
+		}
+
+		isJSON := resp.Header.Get("Content-Type") == "application/json"
+		if isJSON != test.WantJSON {
+			t.Errorf("%s: expected JSON response %v, got Content-Type %q", test.Desc, test.WantJSON, resp.Header.Get("Content-Type"))
+			continue
+		}
+		if !isJSON {
+			continue
+		}
+
+		var families []*dto.MetricFamily
+		if err := json.Unmarshal(body, &families); err != nil {
+			t.Fatalf("%s: failed to unmarshal response: %v", test.Desc, err)
+		}
+		found := false
+		for _, family := range families {
+			if family.GetName() == "kube_configmap_info" && len(family.GetMetric()) == 1 {
+				found = true
+			}
+		}
+		if !found {
+			t.Errorf("%s: expected a kube_configmap_info family with one metric, got:\n%s", test.Desc, body)
+		}
+	}
+}
+
+func TestMetricHandlerOpenMetrics(t *testing.T) {
+	kubeClient := fake.NewSimpleClientset()
+	for i := 0; i < 2; i++ {
+		if err := configMap(kubeClient, i); err != nil {
+			t.Fatalf("error injecting resources: %v", err)
+		}
+	}
+
+	opts := options.NewOptions()
+
+	builder := kcollectors.NewBuilder(context.TODO(), opts)
+	builder.WithEnabledCollectors(options.CollectorSet{"configmaps": struct{}{}})
+	builder.WithKubeClient(kubeClient)
+	builder.WithNamespaces(options.DefaultNamespaces)
+
+	collectors := builder.Build()
+
+	// Wait for informers to sync
+	time.Sleep(time.Second)
+
+	handler := newMetricHandler(kcollectors.NewRegistry(collectors), opts)
+
+	req := httptest.NewRequest("GET", "http://localhost:8080/metrics", nil)
+	// The Accept header sent by Prometheus supporting OpenMetrics.
+	req.Header.Set("Accept", "application/openmetrics-text; version=0.0.1,text/plain;version=0.0.4;q=0.5,*/*;q=0.1")
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, req)
+
+	if got := w.Header().Get("Content-Type"); !strings.HasPrefix(got, "application/openmetrics-text;") {
+		t.Fatalf("expected OpenMetrics content type, got %q", got)
+	}
+
+	body := w.Body.String()
+	if !strings.HasSuffix(body, "\n# EOF\n") {
+		t.Fatalf("expected response to end with # EOF, got:\n%s", body)
+	}
+
+	// The metrics of a family have to be contiguous.
+	seen := map[string]bool{}
+	last := ""
+	lines := strings.Split(strings.TrimSuffix(body, "# EOF\n"), "\n")
+	for _, line := range lines[:len(lines)-1] {
+		name := line[:strings.IndexAny(line, "{ ")]
+		if name != last && seen[name] {
+			t.Fatalf("expected metrics of family %s to be contiguous, got:\n%s", name, body)
+		}
+		seen[name], last = true, name
+	}
+	if want := `kube_configmap_info{configmap="configmap1",namespace="default"} 1`; !strings.Contains(body, want+"\n") {
+		t.Fatalf("expected %s in response, got:\n%s", want, body)
+	}
+}
+
+func TestOpenMetricsSample(t *testing.T) {
+	tests := []struct {
+		Desc   string
+		Sample string
+		Wanted string
+	}{
+		{
+			Desc:   "without timestamp",
+			Sample: "kube_configmap_info{configmap=\"a b\",namespace=\"default\"} 1\n",
+			Wanted: "kube_configmap_info{configmap=\"a b\",namespace=\"default\"} 1\n",
+		},
+		{
+			Desc:   "with timestamp",
+			Sample: "kube_configmap_info{configmap=\"a} 1 2\",namespace=\"default\"} 1 1500000000123\n",
+			Wanted: "kube_configmap_info{configmap=\"a} 1 2\",namespace=\"default\"} 1 1500000000.123\n",
+		},
+		{
+			Desc:   "without labels",
+			Sample: "kube_test 2 1500000000000\n",
+			Wanted: "kube_test 2 1500000000\n",
+		},
+	}
+
+	for _, test := range tests {
+		if got := openMetricsSample(test.Sample); got != test.Wanted {
+			t.Errorf("%s: expected %q, got %q", test.Desc, test.Wanted, got)
+		}
+	}
+}
+
+func TestMetricHandlerTimestamps(t *testing.T) {
+	kubeClient := fake.NewSimpleClientset()
+	if err := configMap(kubeClient, 0); err != nil {
+		t.Fatalf("error injecting resources: %v", err)
+	}
+
+	opts := options.NewOptions()
+	opts.OutputFormat = options.OutputFormatJSON
+
+	builder := kcollectors.NewBuilder(context.TODO(), opts)
+	builder.WithEnabledCollectors(options.CollectorSet{"configmaps": struct{}{}})
+	builder.WithKubeClient(kubeClient)
+	builder.WithNamespaces(options.DefaultNamespaces)
+	builder.WithMetricTimestamps(true)
+
+	before := time.Now().UnixNano() / int64(time.Millisecond)
+	collectors := builder.Build()
+
+	// Wait for informers to sync
+	time.Sleep(time.Second)
+
+	handler := newMetricHandler(kcollectors.NewRegistry(collectors), opts)
+	req := httptest.NewRequest("GET", "http://localhost:8080/metrics", nil)
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, req)
+
+	var families []*dto.MetricFamily
+	if err := json.Unmarshal(w.Body.Bytes(), &families); err != nil {
+		t.Fatalf("failed to unmarshal response: %v", err)
+	}
+	found := false
+	for _, family := range families {
+		if family.GetName() != "kube_configmap_info" {
+			continue
+		}
+		for _, m := range family.GetMetric() {
+			found = true
+			if m.GetTimestampMs() < before {
+				t.Errorf("expected timestamp after %d, got %d", before, m.GetTimestampMs())
+			}
+		}
+	}
+	if !found {
+		t.Fatalf("expected a kube_configmap_info metric, got:\n%s", w.Body.String())
+	}
+}
+
+func TestMetricHandlerContainerRestarts(t *testing.T) {
+	kubeClient := fake.NewSimpleClientset()
+	pod := &v1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: "pod0", Namespace: "default"},
+		Status: v1.PodStatus{
+			ContainerStatuses: []v1.ContainerStatus{{Name: "container0", RestartCount: 5}},
+		},
+	}
+	if _, err := kubeClient.CoreV1().Pods(pod.Namespace).Create(pod); err != nil {
+		t.Fatalf("error injecting resources: %v", err)
+	}
+
+	opts := options.NewOptions()
+
+	builder := kcollectors.NewBuilder(context.TODO(), opts)
+	builder.WithEnabledCollectors(options.CollectorSet{"pods": struct{}{}})
+	builder.WithKubeClient(kubeClient)
+	builder.WithNamespaces(options.DefaultNamespaces)
+
+	collectors := builder.Build()
+
+	// Wait for informers to sync
+	time.Sleep(time.Second)
+
+	handler := newMetricHandler(kcollectors.NewRegistry(collectors), opts)
+	req := httptest.NewRequest("GET", "http://localhost:8080/metrics", nil)
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, req)
+
+	var parser expfmt.TextParser
+	families, err := parser.TextToMetricFamilies(w.Body)
+	if err != nil {
+		t.Fatalf("failed to parse response: %v", err)
+	}
+	family, ok := families["kube_pod_container_status_restarts_total"]
+	if !ok {
+		t.Fatalf("expected a kube_pod_container_status_restarts_total family, got: %v", families)
+	}
+	// The exposition doesn't carry TYPE lines, but the restart count must
+	// never be declared a gauge, as rate() relies on counter semantics.
+	if family.GetType() == dto.MetricType_GAUGE {
+		t.Errorf("expected kube_pod_container_status_restarts_total not to be a gauge")
+	}
+	if got := family.GetMetric(); len(got) != 1 || got[0].GetUntyped().GetValue() != 5 {
+		t.Errorf("expected the cumulative restart count 5, got: %v", got)
+	}
+}
+
+func TestMetricHandlerSeriesMetrics(t *testing.T) {
+	kubeClient := fake.NewSimpleClientset()
+	for i := 0; i < 2; i++ {
+		cm := &v1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: "configmap" + strconv.Itoa(i), Namespace: "default"}}
+		if _, err := kubeClient.CoreV1().ConfigMaps(cm.Namespace).Create(cm); err != nil {
+			t.Fatalf("error injecting resources: %v", err)
+		}
+	}
+	svc := &v1.Service{ObjectMeta: metav1.ObjectMeta{Name: "service0", Namespace: "default"}}
+	if _, err := kubeClient.CoreV1().Services(svc.Namespace).Create(svc); err != nil {
+		t.Fatalf("error injecting resources: %v", err)
+	}
+
+	opts := options.NewOptions()
+
+	builder := kcollectors.NewBuilder(context.TODO(), opts)
+	builder.WithEnabledCollectors(options.CollectorSet{"configmaps": struct{}{}, "services": struct{}{}})
+	builder.WithKubeClient(kubeClient)
+	builder.WithNamespaces(options.DefaultNamespaces)
+
+	collectors := builder.Build()
+
+	// Wait for informers to sync
+	time.Sleep(time.Second)
+
+	wanted := map[string]int{}
+	total := 0
+	for _, c := range collectors {
+		wanted[c.Name()] = len(c.Collect())
+		total += wanted[c.Name()]
+	}
+	if wanted["configmaps"] <= wanted["services"] {
+		t.Fatalf("expected the two configmaps to yield more series than the single service, got %v", wanted)
+	}
+
+	handler := newMetricHandler(kcollectors.NewRegistry(collectors), opts)
+	req := httptest.NewRequest("GET", "http://localhost:8080/metrics", nil)
+	handler.ServeHTTP(httptest.NewRecorder(), req)
+
+	for name, n := range wanted {
+		m := &dto.Metric{}
+		if err := kcollectors.SeriesMetric.WithLabelValues(name).Write(m); err != nil {
+			t.Fatal(err)
+		}
+		if got := m.GetGauge().GetValue(); got != float64(n) {
+			t.Errorf("expected %d series of collector %s, got %v", n, name, got)
+		}
+	}
+	m := &dto.Metric{}
+	if err := kcollectors.TotalSeriesMetric.Write(m); err != nil {
+		t.Fatal(err)
+	}
+	if got := m.GetGauge().GetValue(); got != float64(total) {
+		t.Errorf("expected %d series in total, got %v", total, got)
+	}
+}
+
+func TestMetricsPathMap(t *testing.T) {
+	kubeClient := fake.NewSimpleClientset()
+	cm := &v1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: "configmap0", Namespace: "default"}}
+	if _, err := kubeClient.CoreV1().ConfigMaps(cm.Namespace).Create(cm); err != nil {
+		t.Fatalf("error injecting resources: %v", err)
+	}
+	svc := &v1.Service{ObjectMeta: metav1.ObjectMeta{Name: "service0", Namespace: "default"}}
+	if _, err := kubeClient.CoreV1().Services(svc.Namespace).Create(svc); err != nil {
+		t.Fatalf("error injecting resources: %v", err)
+	}
+
+	opts := options.NewOptions()
+	opts.MetricsPathMap = options.MetricsPathMap{"services": "/infra"}
+
+	builder := kcollectors.NewBuilder(context.TODO(), opts)
+	builder.WithEnabledCollectors(options.CollectorSet{"configmaps": struct{}{}, "services": struct{}{}})
+	builder.WithKubeClient(kubeClient)
+	builder.WithNamespaces(options.DefaultNamespaces)
+
+	collectors := builder.Build()
+
+	// Wait for informers to sync
+	time.Sleep(time.Second)
+
+	server := metricsServer(kcollectors.NewRegistry(collectors), opts, "127.0.0.1", 0)
+	scrape := func(path string) map[string]*dto.MetricFamily {
+		w := httptest.NewRecorder()
+		server.Handler.ServeHTTP(w, httptest.NewRequest("GET", "http://localhost:8080"+path, nil))
+		if w.Code != http.StatusOK {
+			t.Fatalf("expected status 200 scraping %s, got %d", path, w.Code)
+		}
+		var parser expfmt.TextParser
+		families, err := parser.TextToMetricFamilies(w.Body)
+		if err != nil {
+			t.Fatalf("failed to parse response of %s: %v", path, err)
+		}
+		return families
+	}
+
+	workloads := scrape("/metrics")
+	infra := scrape("/infra")
+	for name := range workloads {
+		if _, ok := infra[name]; ok {
+			t.Errorf("expected metric family %s to only be exposed on one path", name)
+		}
+		if !strings.HasPrefix(name, "kube_configmap_") {
+			t.Errorf("expected only configmap metrics on /metrics, got %s", name)
+		}
+	}
+	for name := range infra {
+		if !strings.HasPrefix(name, "kube_service_") {
+			t.Errorf("expected only service metrics on /infra, got %s", name)
+		}
+	}
+	if len(workloads) == 0 || len(infra) == 0 {
+		t.Errorf("expected metrics on both paths, got %d and %d families", len(workloads), len(infra))
+	}
+}
+
+func TestReadyzHandler(t *testing.T) {
+	kubeClient := fake.NewSimpleClientset()
+	// Block the initial list, keeping the reflector from syncing.
+	unblock := make(chan struct{})
+	kubeClient.PrependReactor("list", "configmaps", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		<-unblock
+		return false, nil, nil
+	})
+
+	opts := options.NewOptions()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	builder := kcollectors.NewBuilder(ctx, opts)
+	builder.WithEnabledCollectors(options.CollectorSet{"configmaps": struct{}{}})
+	builder.WithKubeClient(kubeClient)
+	builder.WithNamespaces(options.DefaultNamespaces)
+
+	handler := newReadyzHandler(kcollectors.NewRegistry(builder.Build()))
+	readyz := func() int {
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, httptest.NewRequest("GET", "http://localhost:8080/readyz", nil))
+		return w.Code
+	}
+
+	if code := readyz(); code != http.StatusServiceUnavailable {
+		t.Fatalf("expected status %d before sync, got %d", http.StatusServiceUnavailable, code)
+	}
+
+	close(unblock)
+	deadline := time.Now().Add(5 * time.Second)
+	for readyz() != http.StatusOK {
+		if time.Now().After(deadline) {
+			t.Fatal("expected status 200 after sync")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestCollectorsHandler(t *testing.T) {
+	kubeClient := fake.NewSimpleClientset()
+	if err := configMap(kubeClient, 0); err != nil {
+		t.Fatalf("error injecting resources: %v", err)
+	}
+
+	opts := options.NewOptions()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	builder := kcollectors.NewBuilder(ctx, opts)
+	builder.WithEnabledCollectors(options.CollectorSet{"configmaps": struct{}{}, "secrets": struct{}{}})
+	builder.WithKubeClient(kubeClient)
+	builder.WithNamespaces(options.DefaultNamespaces)
+
+	registry := kcollectors.NewRegistry(builder.Build())
+	deadline := time.Now().Add(5 * time.Second)
+	for !registry.HasSynced() {
+		if time.Now().After(deadline) {
+			t.Fatal("expected collectors to sync")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	w := httptest.NewRecorder()
+	newCollectorsHandler(registry).ServeHTTP(w, httptest.NewRequest("GET", "http://localhost:8080/debug/collectors", nil))
+
+	if ct := w.Header().Get("Content-Type"); ct != "application/json" {
+		t.Errorf("expected content type application/json, got %q", ct)
+	}
+	var got []collectorInfo
+	if err := json.Unmarshal(w.Body.Bytes(), &got); err != nil {
+		t.Fatalf("failed to decode response %q: %v", w.Body.String(), err)
+	}
+	want := []collectorInfo{
+		{Name: "configmaps", Metrics: []string{"kube_configmap_data_keys", "kube_configmap_info", "kube_configmap_metadata_resource_version"}},
+		{Name: "secrets", Metrics: []string{}},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("expected collectors %+v, got %+v", want, got)
+	}
+}
+
+func TestTelemetryRegistryGolangTelemetry(t *testing.T) {
+	for _, disabled := range []bool{false, true} {
+		mfs, err := newTelemetryRegistry(disabled).Gather()
+		if err != nil {
+			t.Fatal(err)
+		}
+		var golang, buildInfo bool
+		for _, mf := range mfs {
+			if strings.HasPrefix(mf.GetName(), "go_") || strings.HasPrefix(mf.GetName(), "process_") {
+				golang = true
+			}
+			if mf.GetName() == "kube_state_metrics_build_info" {
+				buildInfo = true
+			}
+		}
+		if golang == disabled {
+			t.Errorf("disabled golang telemetry %v: expected go_* and process_* metrics %v, got %v", disabled, !disabled, golang)
+		}
+		if !buildInfo {
+			t.Errorf("disabled golang telemetry %v: expected kube_state_metrics_build_info", disabled)
+		}
+	}
+}
+
+// TestMetricsServerNoGolangMetrics guards against the go_* and process_* self
+// metrics of the telemetry registry leaking onto the main metrics endpoint.
+func TestMetricsServerNoGolangMetrics(t *testing.T) {
+	kubeClient := fake.NewSimpleClientset()
+	if err := configMap(kubeClient, 0); err != nil {
+		t.Fatalf("error injecting resources: %v", err)
+	}
+
+	opts := options.NewOptions()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	builder := kcollectors.NewBuilder(ctx, opts)
+	builder.WithEnabledCollectors(options.CollectorSet{"configmaps": struct{}{}})
+	builder.WithKubeClient(kubeClient)
+	builder.WithNamespaces(options.DefaultNamespaces)
+
+	// Create the telemetry registry like main does, including the golang
+	// telemetry.
+	newTelemetryRegistry(false)
+	registry := kcollectors.NewRegistry(builder.Build())
+	if err := registry.WaitForSync(ctx, 5*time.Second); err != nil {
+		t.Fatal(err)
+	}
+
+	w := httptest.NewRecorder()
+	metricsServer(registry, opts, "localhost", 8080).Handler.ServeHTTP(w, httptest.NewRequest("GET", "http://localhost"+opts.MetricsPath, nil))
+	body := w.Body.String()
+	if !strings.Contains(body, "kube_configmap_info") {
+		t.Fatalf("expected kube_configmap_info on %s, got %q", opts.MetricsPath, body)
+	}
+
+	for _, line := range strings.Split(body, "\n") {
+		name := strings.TrimPrefix(strings.TrimPrefix(line, "# HELP "), "# TYPE ")
+		if strings.HasPrefix(name, "go_") || strings.HasPrefix(name, "process_") {
+			t.Errorf("expected no go_* and process_* metrics on %s, got %q", opts.MetricsPath, line)
+		}
+	}
+}
+
+func TestGzipNegotiation(t *testing.T) {
+	kubeClient := fake.NewSimpleClientset()
+	if err := configMap(kubeClient, 0); err != nil {
+		t.Fatalf("error injecting resources: %v", err)
+	}
+
+	opts := options.NewOptions()
+
+	builder := kcollectors.NewBuilder(context.TODO(), opts)
+	builder.WithEnabledCollectors(options.CollectorSet{"configmaps": struct{}{}})
+	builder.WithKubeClient(kubeClient)
+	builder.WithNamespaces(options.DefaultNamespaces)
+
+	collectors := builder.Build()
+
+	// Wait for informers to sync
+	time.Sleep(time.Second)
+
+	telemetryRegistry := prometheus.NewRegistry()
+	telemetryRegistry.MustRegister(prometheus.NewGoCollector())
+
+	servers := map[string]*http.Server{
+		"metrics":   metricsServer(kcollectors.NewRegistry(collectors), opts, "localhost", 8080),
+		"telemetry": telemetryServer(telemetryRegistry, "localhost", 8081, opts.TelemetryPath),
+	}
+	wantMetrics := map[string]string{
+		"metrics":   "kube_configmap_info",
+		"telemetry": "go_goroutines",
+	}
+	tests := []struct {
+		AcceptEncoding string
+		WantGzip       bool
+	}{
+		{AcceptEncoding: "", WantGzip: false},
+		{AcceptEncoding: "deflate", WantGzip: false},
+		{AcceptEncoding: "gzip", WantGzip: true},
+		{AcceptEncoding: "deflate, gzip;q=1.0, gzip", WantGzip: true},
+	}
+
+	for name, server := range servers {
+		for _, test := range tests {
+			req := httptest.NewRequest("GET", "http://localhost/metrics", nil)
+			if test.AcceptEncoding != "" {
+				req.Header.Set("Accept-Encoding", test.AcceptEncoding)
+			}
+			w := httptest.NewRecorder()
+			server.Handler.ServeHTTP(w, req)
+
+			isGzip := w.Header().Get("Content-Encoding") == "gzip"
+			if isGzip != test.WantGzip {
+				t.Errorf("%s server, Accept-Encoding %q: expected gzip %v, got Content-Encoding %q", name, test.AcceptEncoding, test.WantGzip, w.Header().Get("Content-Encoding"))
+				continue
+			}
+
+			var body io.Reader = w.Body
+			if isGzip {
+				gz, err := gzip.NewReader(w.Body)
+				if err != nil {
+					t.Fatalf("%s server, Accept-Encoding %q: failed to read gzipped response: %v", name, test.AcceptEncoding, err)
+				}
+				body = gz
+			}
+			b, err := ioutil.ReadAll(body)
+			if err != nil {
+				t.Fatalf("%s server, Accept-Encoding %q: failed to read response: %v", name, test.AcceptEncoding, err)
+			}
+			if !strings.Contains(string(b), wantMetrics[name]) {
+				t.Errorf("%s server, Accept-Encoding %q: expected %s in response, got:\n%s", name, test.AcceptEncoding, wantMetrics[name], b)
+			}
+		}
+	}
+}
+
+func TestCustomMetricsPath(t *testing.T) {
+	kubeClient := fake.NewSimpleClientset()
+	if err := configMap(kubeClient, 0); err != nil {
+		t.Fatalf("error injecting resources: %v", err)
+	}
+
+	opts := options.NewOptions()
+	opts.MetricsPath = "/kube-state-metrics/metrics"
+	opts.TelemetryPath = "/kube-state-metrics/telemetry"
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	builder := kcollectors.NewBuilder(ctx, opts)
+	builder.WithEnabledCollectors(options.CollectorSet{"configmaps": struct{}{}})
+	builder.WithKubeClient(kubeClient)
+	builder.WithNamespaces(options.DefaultNamespaces)
+
+	registry := kcollectors.NewRegistry(builder.Build())
+	deadline := time.Now().Add(5 * time.Second)
+	for !registry.HasSynced() {
+		if time.Now().After(deadline) {
+			t.Fatal("expected collectors to sync")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	telemetryRegistry := prometheus.NewRegistry()
+	telemetryRegistry.MustRegister(prometheus.NewGoCollector())
+
+	tests := []struct {
+		Server     *http.Server
+		Path       string
+		WantMetric string
+	}{
+		{Server: metricsServer(registry, opts, "localhost", 8080), Path: opts.MetricsPath, WantMetric: "kube_configmap_info"},
+		{Server: telemetryServer(telemetryRegistry, "localhost", 8081, opts.TelemetryPath), Path: opts.TelemetryPath, WantMetric: "go_goroutines"},
+	}
+
+	for _, test := range tests {
+		w := httptest.NewRecorder()
+		test.Server.Handler.ServeHTTP(w, httptest.NewRequest("GET", "http://localhost"+test.Path, nil))
+		if !strings.Contains(w.Body.String(), test.WantMetric) {
+			t.Errorf("expected %s on %s, got %q", test.WantMetric, test.Path, w.Body.String())
+		}
+
+		w = httptest.NewRecorder()
+		test.Server.Handler.ServeHTTP(w, httptest.NewRequest("GET", "http://localhost/", nil))
+		if !strings.Contains(w.Body.String(), "href='"+test.Path+"'") {
+			t.Errorf("expected index to link %s, got %q", test.Path, w.Body.String())
+		}
+
+		w = httptest.NewRecorder()
+		test.Server.Handler.ServeHTTP(w, httptest.NewRequest("GET", "http://localhost/metrics", nil))
+		if strings.Contains(w.Body.String(), test.WantMetric) {
+			t.Errorf("expected no metrics on the default path, got %q", w.Body.String())
+		}
+	}
+}
+
+func TestMetricHandlerScrapeTimeout(t *testing.T) {
+	kubeClient := fake.NewSimpleClientset()
+
+	opts := options.NewOptions()
+
+	builder := kcollectors.NewBuilder(context.TODO(), opts)
+	builder.WithEnabledCollectors(options.DefaultCollectors)
+	builder.WithKubeClient(kubeClient)
+	builder.WithNamespaces(options.DefaultNamespaces)
+
+	collectors := builder.Build()
+
+	// The deadline is already exceeded once collection starts.
+	opts.ScrapeTimeout = time.Nanosecond
+	handler := newMetricHandler(kcollectors.NewRegistry(collectors), opts)
+
+	req := httptest.NewRequest("GET", "http://localhost:8080/metrics", nil)
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, req)
+
+	if w.Code != http.StatusServiceUnavailable {
+		t.Fatalf("expected status %d on scrape timeout, got %d", http.StatusServiceUnavailable, w.Code)
+	}
+}
+
+func TestMetricHandlerMaxConcurrentScrapes(t *testing.T) {
+	opts := options.NewOptions()
+	opts.MaxConcurrentScrapes = 1
+
+	handler := newMetricHandler(kcollectors.NewRegistry(nil), opts)
+
+	req := httptest.NewRequest("GET", "http://localhost:8080/metrics", nil)
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, req)
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected status %d with a free scrape slot, got %d", http.StatusOK, w.Code)
+	}
+
+	// Occupy the only scrape slot.
+	handler.scrapeSlots <- struct{}{}
+
+	w = httptest.NewRecorder()
+	handler.ServeHTTP(w, req)
+	if w.Code != http.StatusTooManyRequests {
+		t.Fatalf("expected status %d without a free scrape slot, got %d", http.StatusTooManyRequests, w.Code)
+	}
+}
+
 func injectFixtures(client *fake.Clientset, multiplier int) error {
 	creators := []func(*fake.Clientset, int) error{
 		configMap,
@@ -133,3 +853,157 @@ func pod(client *fake.Clientset, index int) error {
 	_, err := client.CoreV1().Pods(metav1.NamespaceDefault).Create(&pod)
 	return err
 }
+
+func TestImpersonationConfig(t *testing.T) {
+	opts := options.NewOptions()
+	opts.ImpersonateUser = "system:serviceaccount:kube-system:kube-state-metrics"
+	opts.ImpersonateGroups = []string{"system:serviceaccounts", "system:authenticated"}
+
+	got := impersonationConfig(opts)
+	want := rest.ImpersonationConfig{
+		UserName: "system:serviceaccount:kube-system:kube-state-metrics",
+		Groups:   []string{"system:serviceaccounts", "system:authenticated"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("expected impersonation config %+v, got %+v", want, got)
+	}
+}
+
+func TestWrapImpersonateUID(t *testing.T) {
+	var got http.Header
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = r.Header
+	}))
+	defer server.Close()
+
+	wrapped := false
+	wrap := func(rt http.RoundTripper) http.RoundTripper {
+		wrapped = true
+		return rt
+	}
+	client := &http.Client{Transport: wrapImpersonateUID("1234", wrap)(http.DefaultTransport)}
+
+	req, err := http.NewRequest("GET", server.URL, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+
+	if !wrapped {
+		t.Errorf("expected the previous transport wrapper to be applied")
+	}
+	if uid := got.Get("Impersonate-Uid"); uid != "1234" {
+		t.Errorf("expected impersonated uid %q, got %q", "1234", uid)
+	}
+	if _, ok := req.Header["Impersonate-Uid"]; ok {
+		t.Errorf("expected the original request to be unchanged")
+	}
+}
+
+func TestListenUnixSocket(t *testing.T) {
+	dir, err := ioutil.TempDir("", "kube-state-metrics")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "metrics.sock")
+	address := joinHostPort(unixSocketPrefix+path, 8080)
+	if address != unixSocketPrefix+path {
+		t.Fatalf("expected port to be ignored for Unix domain sockets, got address %s", address)
+	}
+
+	listener, err := listen(address, 0600)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	fi, err := os.Stat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if fi.Mode()&os.ModeSocket == 0 {
+		t.Fatalf("expected %s to be a socket, got mode %v", path, fi.Mode())
+	}
+	if fi.Mode().Perm() != 0600 {
+		t.Fatalf("expected socket permissions 0600, got %#o", fi.Mode().Perm())
+	}
+
+	listener.Close()
+
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Fatalf("expected socket file to be removed on close, got %v", err)
+	}
+}
+
+func TestJoinHostPort(t *testing.T) {
+	tests := []struct {
+		Host          string
+		Port          int
+		WantedAddress string
+	}{
+		{Host: "0.0.0.0", Port: 8080, WantedAddress: "0.0.0.0:8080"},
+		{Host: "::", Port: 8080, WantedAddress: "[::]:8080"},
+		{Host: "::1", Port: 8080, WantedAddress: "[::1]:8080"},
+		{Host: "[::1]", Port: 8080, WantedAddress: "[::1]:8080"},
+		{Host: "unix:///tmp/ksm.sock", Port: 8080, WantedAddress: "unix:///tmp/ksm.sock"},
+	}
+
+	for _, test := range tests {
+		if got := joinHostPort(test.Host, test.Port); got != test.WantedAddress {
+			t.Errorf("joinHostPort(%q, %d): expected %q, got %q", test.Host, test.Port, test.WantedAddress, got)
+		}
+	}
+}
+
+func TestListenIPv6Loopback(t *testing.T) {
+	server := telemetryServer(prometheus.NewRegistry(), "::1", 0, "/metrics")
+	listener, err := listen(server.Addr, 0)
+	if err != nil {
+		t.Skipf("IPv6 loopback not available: %v", err)
+	}
+	go server.Serve(listener)
+	defer server.Close()
+
+	addr := listener.Addr().String()
+	if !strings.HasPrefix(addr, "[::1]:") {
+		t.Fatalf("expected listen address with bracketed IPv6 host, got %q", addr)
+	}
+
+	resp, err := http.Get("http://" + addr + "/metrics")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected status 200 when scraping IPv6 address, got %d", resp.StatusCode)
+	}
+}
+
+func TestListenEphemeralPort(t *testing.T) {
+	server := telemetryServer(prometheus.NewRegistry(), "127.0.0.1", 0, "/metrics")
+	listener, err := listen(server.Addr, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	go server.Serve(listener)
+	defer server.Close()
+
+	port := listener.Addr().(*net.TCPAddr).Port
+	if port == 0 {
+		t.Fatal("expected listener to be bound to a resolved port, got port 0")
+	}
+
+	resp, err := http.Get("http://" + listener.Addr().String() + "/metrics")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected status 200 when scraping resolved address, got %d", resp.StatusCode)
+	}
+}
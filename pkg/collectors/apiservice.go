@@ -0,0 +1,115 @@
+/*
+Copyright 2018 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package collectors
+
+import (
+	"k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/kube-state-metrics/pkg/customresource"
+	"k8s.io/kube-state-metrics/pkg/log"
+	"k8s.io/kube-state-metrics/pkg/metrics"
+)
+
+var (
+	descAPIServiceLabelsName          = "kube_apiservice_labels"
+	descAPIServiceLabelsHelp          = "Kubernetes labels converted to Prometheus labels."
+	descAPIServiceLabelsDefaultLabels = []string{"apiservice"}
+
+	descAPIServiceCreated = newMetricFamilyDef(
+		"kube_apiservice_created",
+		"Unix creation timestamp",
+		descAPIServiceLabelsDefaultLabels,
+		nil,
+	)
+
+	descAPIServiceStatusCondition = newMetricFamilyDef(
+		"kube_apiservice_status_condition",
+		"The condition of the API service.",
+		append(descAPIServiceLabelsDefaultLabels, "condition", "status"),
+		nil,
+	)
+
+	// apiServiceResource is the apiregistration.k8s.io/v1 APIService
+	// resource.
+	apiServiceResource = customresource.Resource{
+		Group:    "apiregistration.k8s.io",
+		Version:  "v1",
+		Resource: "apiservices",
+	}
+)
+
+// apiService holds the fields of an apiregistration.k8s.io/v1 APIService
+// exposed as metrics.
+type apiService struct {
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+	Status            apiServiceStatus `json:"status,omitempty"`
+}
+
+type apiServiceStatus struct {
+	Conditions []apiServiceCondition `json:"conditions,omitempty"`
+}
+
+type apiServiceCondition struct {
+	Type   string             `json:"type"`
+	Status v1.ConditionStatus `json:"status"`
+}
+
+func apiServiceLabelsDesc(labelKeys []string) *metricFamilyDef {
+	return newMetricFamilyDef(
+		descAPIServiceLabelsName,
+		descAPIServiceLabelsHelp,
+		append(descAPIServiceLabelsDefaultLabels, labelKeys...),
+		nil,
+	)
+}
+
+func generateAPIServiceMetrics(allowedLabels []string, obj interface{}) []*metrics.Metric {
+	ms := []*metrics.Metric{}
+
+	a := apiService{}
+	if err := runtime.DefaultUnstructuredConverter.FromUnstructured(obj.(*unstructured.Unstructured).Object, &a); err != nil {
+		log.Errorf("Failed to convert API service: %v", err)
+		return ms
+	}
+
+	addGauge := func(desc *metricFamilyDef, v float64, lv ...string) {
+		lv = append([]string{a.Name}, lv...)
+
+		m, err := metrics.NewMetric(desc.Name, desc.LabelKeys, lv, v)
+		if err != nil {
+			panic(err)
+		}
+
+		ms = append(ms, m)
+	}
+
+	if labelKeys, labelValues, ok := kubeLabelsToPrometheusLabels(a.Labels, allowedLabels); ok {
+		addGauge(apiServiceLabelsDesc(labelKeys), 1, labelValues...)
+	}
+
+	if !a.CreationTimestamp.IsZero() {
+		addGauge(descAPIServiceCreated, float64(a.CreationTimestamp.Unix()))
+	}
+
+	for _, c := range a.Status.Conditions {
+		ms = append(ms, addConditionMetrics(descAPIServiceStatusCondition, c.Status, a.Name, c.Type)...)
+	}
+
+	return ms
+}
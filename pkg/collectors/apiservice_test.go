@@ -0,0 +1,113 @@
+/*
+Copyright 2018 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package collectors
+
+import (
+	"testing"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/kube-state-metrics/pkg/metrics"
+)
+
+func TestAPIServiceCollector(t *testing.T) {
+	// Fixed metadata on type and help text. We prepend this to every expected
+	// output so we only have to modify a single place when doing adjustments.
+	const metadata = `
+		# HELP kube_apiservice_labels Kubernetes labels converted to Prometheus labels.
+		# TYPE kube_apiservice_labels gauge
+		# HELP kube_apiservice_created Unix creation timestamp
+		# TYPE kube_apiservice_created gauge
+		# HELP kube_apiservice_status_condition The condition of the API service.
+		# TYPE kube_apiservice_status_condition gauge
+	`
+	cases := []generateMetricsTestCase{
+		{
+			Obj: &unstructured.Unstructured{Object: map[string]interface{}{
+				"apiVersion": "apiregistration.k8s.io/v1",
+				"kind":       "APIService",
+				"metadata": map[string]interface{}{
+					"name":              "v1.apps",
+					"creationTimestamp": "2017-07-14T02:40:00Z",
+					"labels": map[string]interface{}{
+						"kube-aggregator.kubernetes.io/automanaged": "onstart",
+					},
+				},
+				"spec": map[string]interface{}{
+					"group":   "apps",
+					"version": "v1",
+				},
+				"status": map[string]interface{}{
+					"conditions": []interface{}{
+						map[string]interface{}{
+							"type":   "Available",
+							"status": "True",
+							"reason": "Local",
+						},
+					},
+				},
+			}},
+			Want: `
+				kube_apiservice_created{apiservice="v1.apps"} 1.5e+09
+				kube_apiservice_labels{apiservice="v1.apps",label_kube_aggregator_kubernetes_io_automanaged="onstart"} 1
+				kube_apiservice_status_condition{apiservice="v1.apps",condition="Available",status="false"} 0
+				kube_apiservice_status_condition{apiservice="v1.apps",condition="Available",status="true"} 1
+				kube_apiservice_status_condition{apiservice="v1.apps",condition="Available",status="unknown"} 0
+`,
+		},
+		{
+			Obj: &unstructured.Unstructured{Object: map[string]interface{}{
+				"apiVersion": "apiregistration.k8s.io/v1",
+				"kind":       "APIService",
+				"metadata": map[string]interface{}{
+					"name": "v1beta1.metrics.k8s.io",
+				},
+				"spec": map[string]interface{}{
+					"group":   "metrics.k8s.io",
+					"version": "v1beta1",
+					"service": map[string]interface{}{
+						"name":      "metrics-server",
+						"namespace": "kube-system",
+					},
+				},
+				"status": map[string]interface{}{
+					"conditions": []interface{}{
+						map[string]interface{}{
+							"type":    "Available",
+							"status":  "False",
+							"reason":  "FailedDiscoveryCheck",
+							"message": "no response from https://10.0.0.1:443",
+						},
+					},
+				},
+			}},
+			Want: `
+				kube_apiservice_labels{apiservice="v1beta1.metrics.k8s.io"} 1
+				kube_apiservice_status_condition{apiservice="v1beta1.metrics.k8s.io",condition="Available",status="false"} 1
+				kube_apiservice_status_condition{apiservice="v1beta1.metrics.k8s.io",condition="Available",status="true"} 0
+				kube_apiservice_status_condition{apiservice="v1beta1.metrics.k8s.io",condition="Available",status="unknown"} 0
+`,
+		},
+	}
+	for i, c := range cases {
+		c.Func = func(obj interface{}) []*metrics.Metric {
+			return generateAPIServiceMetrics(nil, obj)
+		}
+		if err := c.run(); err != nil {
+			t.Errorf("unexpected collecting result in %vth run:\n%s", i, err)
+		}
+	}
+}
@@ -18,20 +18,41 @@ limitations under the License.
 package collectors
 
 import (
+	"sort"
 	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
 
+	admissionregistration "k8s.io/api/admissionregistration/v1beta1"
 	apps "k8s.io/api/apps/v1beta1"
 	autoscaling "k8s.io/api/autoscaling/v2beta1"
 	batchv1 "k8s.io/api/batch/v1"
 	batchv1beta1 "k8s.io/api/batch/v1beta1"
 	extensions "k8s.io/api/extensions/v1beta1"
 
-	"github.com/golang/glog"
 	"golang.org/x/net/context"
 	"k8s.io/api/core/v1"
+	networking "k8s.io/api/networking/v1"
 	"k8s.io/api/policy/v1beta1"
+	rbac "k8s.io/api/rbac/v1"
+	scheduling "k8s.io/api/scheduling/v1beta1"
+	storage "k8s.io/api/storage/v1beta1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/apimachinery/pkg/watch"
 	clientset "k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
 	"k8s.io/client-go/tools/cache"
+	"k8s.io/kube-state-metrics/pkg/customresource"
+	"k8s.io/kube-state-metrics/pkg/log"
 	"k8s.io/kube-state-metrics/pkg/metrics"
 	metricsstore "k8s.io/kube-state-metrics/pkg/metrics_store"
 	"k8s.io/kube-state-metrics/pkg/options"
@@ -42,9 +63,36 @@ import (
 type Builder struct {
 	kubeClient        clientset.Interface
 	namespaces        options.NamespaceList
+	namespaceDenylist options.NamespaceList
 	opts              *options.Options
 	ctx               context.Context
 	enabledCollectors options.CollectorSet
+	metricWhitelist   *options.MetricMatcher
+	metricBlacklist   *options.MetricMatcher
+	metricPrefix      string
+	metricTimestamps  bool
+	disableCreated    bool
+	labelSelector     labels.Selector
+	fieldSelector     fields.Selector
+	resourceName      string
+	resourceScope     string
+	resyncPeriod      time.Duration
+	shard             int
+	totalShards       int
+	customResources   *customresource.Config
+	restConfig        *rest.Config
+	metricLabels      map[string]string
+	clusters          []cluster
+	// cluster is the name of the cluster the collectors are built for, added
+	// as cluster label to every metric. Empty if no clusters were added.
+	cluster string
+}
+
+// cluster is a cluster added to a Builder with AddCluster.
+type cluster struct {
+	name       string
+	kubeClient clientset.Interface
+	restConfig *rest.Config
 }
 
 // NewBuilder returns a new builder.
@@ -53,8 +101,12 @@ func NewBuilder(
 	opts *options.Options,
 ) *Builder {
 	return &Builder{
-		opts: opts,
-		ctx:  ctx,
+		opts:          opts,
+		ctx:           ctx,
+		labelSelector: labels.Everything(),
+		fieldSelector: fields.Everything(),
+		totalShards:   1,
+		resourceScope: options.ResourceScopeAll,
 	}
 }
 
@@ -68,212 +120,1054 @@ func (b *Builder) WithNamespaces(n options.NamespaceList) {
 	b.namespaces = n
 }
 
+// WithNamespacesDenylist sets the namespaceDenylist property of a Builder.
+// Objects in these namespaces are watched, but no metrics are generated for
+// them.
+func (b *Builder) WithNamespacesDenylist(n options.NamespaceList) {
+	b.namespaceDenylist = n
+}
+
+// WithMetricWhitelist sets the metricWhitelist property of a Builder.
+func (b *Builder) WithMetricWhitelist(m *options.MetricMatcher) {
+	b.metricWhitelist = m
+}
+
+// WithMetricBlacklist sets the metricBlacklist property of a Builder.
+func (b *Builder) WithMetricBlacklist(m *options.MetricMatcher) {
+	b.metricBlacklist = m
+}
+
+// WithMetricPrefix sets the metricPrefix property of a Builder.
+func (b *Builder) WithMetricPrefix(p string) {
+	b.metricPrefix = p
+}
+
+// WithMetricLabels sets the constant labels added to every metric.
+func (b *Builder) WithMetricLabels(l map[string]string) {
+	b.metricLabels = l
+}
+
+// WithMetricTimestamps sets the metricTimestamps property of a Builder. If
+// set, every metric carries the time its object was last observed.
+func (b *Builder) WithMetricTimestamps(t bool) {
+	b.metricTimestamps = t
+}
+
+// WithCreatedMetricsDisabled sets the disableCreated property of a Builder.
+// If set, no kube_<resource>_created metrics are generated.
+func (b *Builder) WithCreatedMetricsDisabled(d bool) {
+	b.disableCreated = d
+}
+
+// WithLabelSelector sets the labelSelector property of a Builder.
+func (b *Builder) WithLabelSelector(s labels.Selector) {
+	b.labelSelector = s
+}
+
+// WithFieldSelector sets the fieldSelector property of a Builder. It only
+// applies to the pod and node collectors.
+func (b *Builder) WithFieldSelector(s fields.Selector) {
+	b.fieldSelector = s
+}
+
+// WithResourceName restricts all collectors to the object with the given name.
+func (b *Builder) WithResourceName(name string) {
+	b.resourceName = name
+}
+
+// WithResourceScope sets the resourceScope property of a Builder. Only the
+// collectors of resources in the given scope, one of the options.ResourceScope*
+// constants, are built.
+func (b *Builder) WithResourceScope(s string) {
+	b.resourceScope = s
+}
+
+// WithResyncPeriod sets the resyncPeriod property of a Builder. Every
+// reflector relists all of its objects after the given period, 0 disables
+// periodic relists.
+func (b *Builder) WithResyncPeriod(p time.Duration) {
+	b.resyncPeriod = p
+}
+
+// WithSharding sets the shard and totalShards properties of a Builder.
+func (b *Builder) WithSharding(shard, totalShards int) {
+	b.shard = shard
+	b.totalShards = totalShards
+}
+
 // WithKubeClient sets the kubeClient property of a Builder.
 func (b *Builder) WithKubeClient(c clientset.Interface) {
 	b.kubeClient = c
 }
 
-// Build initializes and registers all enabled collectors.
+// AddCluster adds a cluster to watch with the given client and REST config.
+// Once clusters are added, the collectors are built once per cluster instead
+// of for the client set by WithKubeClient, adding a cluster label with the
+// given name to every metric.
+func (b *Builder) AddCluster(name string, kubeClient clientset.Interface, restConfig *rest.Config) {
+	b.clusters = append(b.clusters, cluster{name: name, kubeClient: kubeClient, restConfig: restConfig})
+}
+
+// WithCustomResources sets the customResources property of a Builder.
+func (b *Builder) WithCustomResources(c *customresource.Config) {
+	b.customResources = c
+}
+
+// WithRESTConfig sets the restConfig property of a Builder. It is used by
+// collectors of resources without a typed client, e.g. custom resources.
+func (b *Builder) WithRESTConfig(c *rest.Config) {
+	b.restConfig = c
+}
+
+// Build initializes and registers all enabled collectors, once per added
+// cluster.
 func (b *Builder) Build() []*Collector {
+	registerReflectorErrorLogger()
+	collectors := []*Collector{}
+	for _, cb := range b.clusterBuilders() {
+		collectors = append(collectors, cb.build()...)
+	}
+	return collectors
+}
+
+// clusterBuilders returns a copy of the Builder per added cluster, using the
+// client and REST config of the cluster, or the Builder itself if no clusters
+// were added.
+func (b *Builder) clusterBuilders() []*Builder {
+	if len(b.clusters) == 0 {
+		return []*Builder{b}
+	}
+	builders := []*Builder{}
+	for _, c := range b.clusters {
+		cb := *b
+		cb.clusters = nil
+		cb.kubeClient = c.kubeClient
+		cb.restConfig = c.restConfig
+		cb.cluster = c.name
+		builders = append(builders, &cb)
+	}
+	return builders
+}
 
+func (b *Builder) build() []*Collector {
 	collectors := []*Collector{}
 	activeCollectorNames := []string{}
 
 	for c := range b.enabledCollectors {
+		if !b.inResourceScope(collectorResources[c]) {
+			continue
+		}
 		constructor, ok := availableCollectors[c]
 		if ok {
 			collector := constructor(b)
+			collector.name = c
 			activeCollectorNames = append(activeCollectorNames, c)
 			collectors = append(collectors, collector)
 		}
 		// TODO: What if not ok?
 	}
 
-	glog.Infof("Active collectors: %s", strings.Join(activeCollectorNames, ","))
+	if b.customResources != nil {
+		for _, collector := range b.buildCustomResourceCollectors(b.ctx, b.customResources) {
+			activeCollectorNames = append(activeCollectorNames, collector.name)
+			collectors = append(collectors, collector)
+		}
+	}
+
+	if _, ok := b.enabledCollectors["namespaces"]; ok && b.inResourceScope(collectorResources["namespaces"]) {
+		collector := b.buildNamespaceObjectCountCollector(collectors)
+		activeCollectorNames = append(activeCollectorNames, collector.name)
+		collectors = append(collectors, collector)
+	}
+
+	if b.cluster != "" {
+		log.Infof("Active collectors of cluster %s: %s", b.cluster, strings.Join(activeCollectorNames, ","))
+	} else {
+		log.Infof("Active collectors: %s", strings.Join(activeCollectorNames, ","))
+	}
+
+	return collectors
+}
+
+// BuildCustomResourceCollectors initializes one collector per resource of the
+// given custom resource config, once per added cluster. The collectors stop
+// watching their resources once ctx is done.
+func (b *Builder) BuildCustomResourceCollectors(ctx context.Context, c *customresource.Config) []*Collector {
+	registerReflectorErrorLogger()
+	collectors := []*Collector{}
+	for _, cb := range b.clusterBuilders() {
+		collectors = append(collectors, cb.buildCustomResourceCollectors(ctx, c)...)
+	}
+	return collectors
+}
 
+func (b *Builder) buildCustomResourceCollectors(ctx context.Context, c *customresource.Config) []*Collector {
+	collectors := []*Collector{}
+	for _, r := range c.Resources {
+		if !b.inResourceScope(r) {
+			continue
+		}
+		collector := b.buildCustomResourceCollector(ctx, r)
+		collector.name = customResourceCollectorName(r)
+		collectors = append(collectors, collector)
+	}
 	return collectors
 }
 
+// buildNamespaceObjectCountCollector returns a synthetic collector exposing
+// the number of objects per namespace held by the given collectors. The
+// counts are computed on collection from the stores of the collectors.
+func (b *Builder) buildNamespaceObjectCountCollector(collectors []*Collector) *Collector {
+	generateFunc := func(interface{}) []*metrics.Metric {
+		return generateNamespaceObjectCountMetrics(collectors)
+	}
+	generateFunc = metrics.FilteredGenerateFunc(
+		metrics.PrefixedGenerateFunc(b.labeledGenerateFunc(generateFunc), b.metricPrefix),
+		b.metricWhitelist,
+		b.metricBlacklist,
+	)
+
+	collector := newCollector(generatedStore(func() []*metrics.Metric { return generateFunc(nil) }), nil)
+	collector.name = "namespaceobjectcounts"
+	return collector
+}
+
 var availableCollectors = map[string]func(f *Builder) *Collector{
+	"apiservices":              func(b *Builder) *Collector { return b.buildAPIServiceCollector() },
+	"clusterrolebindings":      func(b *Builder) *Collector { return b.buildClusterRoleBindingCollector() },
+	"clusterroles":             func(b *Builder) *Collector { return b.buildClusterRoleCollector() },
 	"configmaps":               func(b *Builder) *Collector { return b.buildConfigMapCollector() },
 	"cronjobs":                 func(b *Builder) *Collector { return b.buildCronJobCollector() },
 	"daemonsets":               func(b *Builder) *Collector { return b.buildDaemonSetCollector() },
 	"deployments":              func(b *Builder) *Collector { return b.buildDeploymentCollector() },
 	"endpoints":                func(b *Builder) *Collector { return b.buildEndpointsCollector() },
+	"endpointslices":           func(b *Builder) *Collector { return b.buildEndpointSliceCollector() },
 	"horizontalpodautoscalers": func(b *Builder) *Collector { return b.buildHPACollector() },
 	"jobs":                   func(b *Builder) *Collector { return b.buildJobCollector() },
+	"leases":                 func(b *Builder) *Collector { return b.buildLeaseCollector() },
 	"limitranges":            func(b *Builder) *Collector { return b.buildLimitRangeCollector() },
+	"mutatingwebhookconfigurations": func(b *Builder) *Collector { return b.buildMutatingWebhookConfigurationCollector() },
 	"namespaces":             func(b *Builder) *Collector { return b.buildNamespaceCollector() },
+	"networkpolicies":        func(b *Builder) *Collector { return b.buildNetworkPolicyCollector() },
 	"nodes":                  func(b *Builder) *Collector { return b.buildNodeCollector() },
 	"persistentvolumeclaims": func(b *Builder) *Collector { return b.buildPersistentVolumeClaimCollector() },
 	"persistentvolumes":      func(b *Builder) *Collector { return b.buildPersistentVolumeCollector() },
 	"poddisruptionbudgets":   func(b *Builder) *Collector { return b.buildPodDisruptionBudgetCollector() },
 	"pods":                   func(b *Builder) *Collector { return b.buildPodCollector() },
+	"priorityclasses":        func(b *Builder) *Collector { return b.buildPriorityClassCollector() },
 	"replicasets":            func(b *Builder) *Collector { return b.buildReplicaSetCollector() },
 	"replicationcontrollers": func(b *Builder) *Collector { return b.buildReplicationControllerCollector() },
 	"resourcequotas":         func(b *Builder) *Collector { return b.buildResourceQuotaCollector() },
+	"runtimeclasses":         func(b *Builder) *Collector { return b.buildRuntimeClassCollector() },
 	"secrets":                func(b *Builder) *Collector { return b.buildSecretCollector() },
 	"services":               func(b *Builder) *Collector { return b.buildServiceCollector() },
 	"statefulsets":           func(b *Builder) *Collector { return b.buildStatefulSetCollector() },
+	"validatingwebhookconfigurations": func(b *Builder) *Collector { return b.buildValidatingWebhookConfigurationCollector() },
+	"verticalpodautoscalers":          func(b *Builder) *Collector { return b.buildVerticalPodAutoscalerCollector() },
+	"volumeattachments":               func(b *Builder) *Collector { return b.buildVolumeAttachmentCollector() },
 }
 
 func (b *Builder) buildPodCollector() *Collector {
 	genFunc := func(obj interface{}) []*metrics.Metric {
-		return generatePodMetrics(b.opts.DisablePodNonGenericResourceMetrics, obj)
+		return generatePodMetrics(b.opts.DisablePodNonGenericResourceMetrics, b.opts.UseInfoMetrics, b.opts.AnnotationsAllowlist["pods"], b.allowedLabels("pods"), obj)
 	}
-	store := metricsstore.NewMetricsStore(genFunc)
-	reflectorPerNamespace(b.ctx, b.kubeClient, &v1.Pod{}, store, b.namespaces, createPodListWatch)
+	store := b.newMetricsStore("pods", genFunc)
+	hasSynced := reflectorPerNamespace(b.ctx, b.kubeClient, &v1.Pod{}, "pods", store, b.resyncPeriod, b.namespaces, b.labelSelector, b.fieldSelectorFor("pods"), createPodListWatch)
 
-	return newCollector(store)
+	return newCollector(store, hasSynced)
+}
+
+func (b *Builder) buildCustomResourceCollector(ctx context.Context, r customresource.Resource) *Collector {
+	store := b.newMetricsStore(customResourceCollectorName(r), customresource.GenerateFunc(r))
+	hasSynced := b.unstructuredReflectorPerNamespace(ctx, r, store)
+
+	return newCollector(store, hasSynced)
+}
+
+func (b *Builder) buildLeaseCollector() *Collector {
+	store := b.newMetricsStore("leases", generateLeaseMetrics)
+	if !b.servesResource(leaseResource) {
+		return newCollector(store, nil)
+	}
+	hasSynced := b.unstructuredReflectorPerNamespace(b.ctx, leaseResource, store)
+
+	return newCollector(store, hasSynced)
+}
+
+func (b *Builder) buildAPIServiceCollector() *Collector {
+	genFunc := func(obj interface{}) []*metrics.Metric {
+		return generateAPIServiceMetrics(b.allowedLabels("apiservices"), obj)
+	}
+	store := b.newMetricsStore("apiservices", genFunc)
+	hasSynced := b.unstructuredReflectorPerNamespace(b.ctx, apiServiceResource, store)
+
+	return newCollector(store, hasSynced)
+}
+
+func (b *Builder) buildRuntimeClassCollector() *Collector {
+	store := b.newMetricsStore("runtimeclasses", generateRuntimeClassMetrics)
+	if !b.servesResource(runtimeClassResource) {
+		return newCollector(store, nil)
+	}
+	hasSynced := b.unstructuredReflectorPerNamespace(b.ctx, runtimeClassResource, store)
+
+	return newCollector(store, hasSynced)
+}
+
+func (b *Builder) buildVolumeAttachmentCollector() *Collector {
+	store := b.newMetricsStore("volumeattachments", generateVolumeAttachmentMetrics)
+	hasSynced := reflectorPerNamespace(b.ctx, b.kubeClient, &storage.VolumeAttachment{}, "volumeattachments", store, b.resyncPeriod, b.namespaces, b.labelSelector, b.fieldSelectorFor("volumeattachments"), createVolumeAttachmentListWatch)
+
+	return newCollector(store, hasSynced)
+}
+
+func (b *Builder) buildEndpointSliceCollector() *Collector {
+	store := b.newMetricsStore("endpointslices", generateEndpointSliceMetrics)
+	if !b.servesResource(endpointSliceResource) {
+		return newCollector(store, nil)
+	}
+	hasSynced := b.unstructuredReflectorPerNamespace(b.ctx, endpointSliceResource, store)
+
+	return newCollector(store, hasSynced)
+}
+
+func (b *Builder) buildVerticalPodAutoscalerCollector() *Collector {
+	store := b.newMetricsStore("verticalpodautoscalers", generateVerticalPodAutoscalerMetrics)
+	if !b.servesResource(verticalPodAutoscalerResource) {
+		return newCollector(store, nil)
+	}
+	hasSynced := b.unstructuredReflectorPerNamespace(b.ctx, verticalPodAutoscalerResource, store)
+
+	return newCollector(store, hasSynced)
+}
+
+// servesResource reports whether the API server serves the given resource,
+// e.g. whether its CRD is installed or its API version is available in the
+// Kubernetes version of the cluster. If not, or if discovery fails, a warning
+// is logged and false is returned, so that the collector of the resource can
+// be skipped instead of never completing its initial list.
+func (b *Builder) servesResource(r customresource.Resource) bool {
+	gv := r.GroupVersionResource().GroupVersion().String()
+	list, err := b.kubeClient.Discovery().ServerResourcesForGroupVersion(gv)
+	if err != nil {
+		log.Warningf("Failed to discover %s, not watching %s: %v", gv, customResourceCollectorName(r), err)
+		return false
+	}
+	for _, res := range list.APIResources {
+		if res.Name == r.Resource {
+			return true
+		}
+	}
+	log.Warningf("%s is not served by %s, not watching it", r.Resource, gv)
+	return false
+}
+
+// unstructuredReflectorPerNamespace is like reflectorPerNamespace for
+// resources the vendored client-go has no typed client for, i.e. custom
+// resources and built-in resources newer than the vendored client-go, which
+// are hence listed and watched as *unstructured.Unstructured. Without a
+// restConfig the store stays empty and nothing is waited for to sync.
+func (b *Builder) unstructuredReflectorPerNamespace(ctx context.Context, r customresource.Resource, store cache.Store) func() bool {
+	if b.restConfig == nil {
+		log.Warningf("No REST config given, not watching %s", customResourceCollectorName(r))
+		return nil
+	}
+	client, err := customresource.NewClient(b.restConfig, r)
+	if err != nil {
+		log.Fatalf("Failed to create client for %s: %v", customResourceCollectorName(r), err)
+	}
+	listWatchFunc := func(_ clientset.Interface, ns string) cache.ListWatch {
+		return client.ListWatch(ns)
+	}
+	return reflectorPerNamespace(ctx, b.kubeClient, &unstructured.Unstructured{}, customResourceCollectorName(r), store, b.resyncPeriod, b.namespaces, b.labelSelector, b.fieldSelectorFor(customResourceCollectorName(r)), listWatchFunc)
+}
+
+// customResourceCollectorName returns the name of the collector of a custom
+// resource, e.g. "widgets.example.com".
+func customResourceCollectorName(r customresource.Resource) string {
+	if r.Group == "" {
+		return r.Resource
+	}
+	return r.Resource + "." + r.Group
 }
 
 func (b *Builder) buildCronJobCollector() *Collector {
-	store := metricsstore.NewMetricsStore(generateCronJobMetrics)
-	reflectorPerNamespace(b.ctx, b.kubeClient, &batchv1beta1.CronJob{}, store, b.namespaces, createCronJobListWatch)
+	genFunc := func(obj interface{}) []*metrics.Metric {
+		return generateCronJobMetrics(b.allowedLabels("cronjobs"), obj)
+	}
+	store := b.newMetricsStore("cronjobs", genFunc)
+	hasSynced := reflectorPerNamespace(b.ctx, b.kubeClient, &batchv1beta1.CronJob{}, "cronjobs", store, b.resyncPeriod, b.namespaces, b.labelSelector, b.fieldSelectorFor("cronjobs"), createCronJobListWatch)
+
+	return newCollector(store, hasSynced)
+}
+
+func (b *Builder) buildClusterRoleCollector() *Collector {
+	store := b.newMetricsStore("clusterroles", generateClusterRoleMetrics)
+	hasSynced := reflectorPerNamespace(b.ctx, b.kubeClient, &rbac.ClusterRole{}, "clusterroles", store, b.resyncPeriod, b.namespaces, b.labelSelector, b.fieldSelectorFor("clusterroles"), createClusterRoleListWatch)
+
+	return newCollector(store, hasSynced)
+}
+
+func (b *Builder) buildClusterRoleBindingCollector() *Collector {
+	store := b.newMetricsStore("clusterrolebindings", generateClusterRoleBindingMetrics)
+	hasSynced := reflectorPerNamespace(b.ctx, b.kubeClient, &rbac.ClusterRoleBinding{}, "clusterrolebindings", store, b.resyncPeriod, b.namespaces, b.labelSelector, b.fieldSelectorFor("clusterrolebindings"), createClusterRoleBindingListWatch)
 
-	return newCollector(store)
+	return newCollector(store, hasSynced)
 }
 
 func (b *Builder) buildConfigMapCollector() *Collector {
-	store := metricsstore.NewMetricsStore(generateConfigMapMetrics)
-	reflectorPerNamespace(b.ctx, b.kubeClient, &v1.ConfigMap{}, store, b.namespaces, createConfigMapListWatch)
+	store := b.newMetricsStore("configmaps", generateConfigMapMetrics)
+	hasSynced := reflectorPerNamespace(b.ctx, b.kubeClient, &v1.ConfigMap{}, "configmaps", store, b.resyncPeriod, b.namespaces, b.labelSelector, b.fieldSelectorFor("configmaps"), createConfigMapListWatch)
 
-	return newCollector(store)
+	return newCollector(store, hasSynced)
 }
 
 func (b *Builder) buildDaemonSetCollector() *Collector {
-	store := metricsstore.NewMetricsStore(generateDaemonSetMetrics)
-	reflectorPerNamespace(b.ctx, b.kubeClient, &extensions.DaemonSet{}, store, b.namespaces, createDaemonSetListWatch)
+	genFunc := func(obj interface{}) []*metrics.Metric {
+		return generateDaemonSetMetrics(b.allowedLabels("daemonsets"), obj)
+	}
+	store := b.newMetricsStore("daemonsets", genFunc)
+	hasSynced := reflectorPerNamespace(b.ctx, b.kubeClient, &extensions.DaemonSet{}, "daemonsets", store, b.resyncPeriod, b.namespaces, b.labelSelector, b.fieldSelectorFor("daemonsets"), createDaemonSetListWatch)
 
-	return newCollector(store)
+	return newCollector(store, hasSynced)
 }
 
 func (b *Builder) buildDeploymentCollector() *Collector {
-	store := metricsstore.NewMetricsStore(generateDeploymentMetrics)
-	reflectorPerNamespace(b.ctx, b.kubeClient, &extensions.Deployment{}, store, b.namespaces, createDeploymentListWatch)
+	genFunc := func(obj interface{}) []*metrics.Metric {
+		return generateDeploymentMetrics(b.opts.AnnotationsAllowlist["deployments"], b.allowedLabels("deployments"), obj)
+	}
+	store := b.newMetricsStore("deployments", genFunc)
+	hasSynced := reflectorPerNamespace(b.ctx, b.kubeClient, &extensions.Deployment{}, "deployments", store, b.resyncPeriod, b.namespaces, b.labelSelector, b.fieldSelectorFor("deployments"), createDeploymentListWatch)
 
-	return newCollector(store)
+	return newCollector(store, hasSynced)
 }
 
 func (b *Builder) buildEndpointsCollector() *Collector {
-	store := metricsstore.NewMetricsStore(generateEndpointsMetrics)
-	reflectorPerNamespace(b.ctx, b.kubeClient, &v1.Endpoints{}, store, b.namespaces, createEndpointsListWatch)
+	genFunc := func(obj interface{}) []*metrics.Metric {
+		return generateEndpointsMetrics(b.allowedLabels("endpoints"), obj)
+	}
+	store := b.newMetricsStore("endpoints", genFunc)
+	hasSynced := reflectorPerNamespace(b.ctx, b.kubeClient, &v1.Endpoints{}, "endpoints", store, b.resyncPeriod, b.namespaces, b.labelSelector, b.fieldSelectorFor("endpoints"), createEndpointsListWatch)
 
-	return newCollector(store)
+	return newCollector(store, hasSynced)
 }
 
 func (b *Builder) buildHPACollector() *Collector {
-	store := metricsstore.NewMetricsStore(generateHPAMetrics)
-	reflectorPerNamespace(b.ctx, b.kubeClient, &autoscaling.HorizontalPodAutoscaler{}, store, b.namespaces, createHPAListWatch)
+	genFunc := func(obj interface{}) []*metrics.Metric {
+		return generateHPAMetrics(b.allowedLabels("horizontalpodautoscalers"), obj)
+	}
+	store := b.newMetricsStore("horizontalpodautoscalers", genFunc)
+	hasSynced := reflectorPerNamespace(b.ctx, b.kubeClient, &autoscaling.HorizontalPodAutoscaler{}, "horizontalpodautoscalers", store, b.resyncPeriod, b.namespaces, b.labelSelector, b.fieldSelectorFor("horizontalpodautoscalers"), createHPAListWatch)
 
-	return newCollector(store)
+	return newCollector(store, hasSynced)
 }
 
 func (b *Builder) buildJobCollector() *Collector {
-	store := metricsstore.NewMetricsStore(generateJobMetrics)
-	reflectorPerNamespace(b.ctx, b.kubeClient, &batchv1.Job{}, store, b.namespaces, createJobListWatch)
+	genFunc := func(obj interface{}) []*metrics.Metric {
+		return generateJobMetrics(b.allowedLabels("jobs"), obj)
+	}
+	store := b.newMetricsStore("jobs", genFunc)
+	hasSynced := reflectorPerNamespace(b.ctx, b.kubeClient, &batchv1.Job{}, "jobs", store, b.resyncPeriod, b.namespaces, b.labelSelector, b.fieldSelectorFor("jobs"), createJobListWatch)
 
-	return newCollector(store)
+	return newCollector(store, hasSynced)
 }
 
 func (b *Builder) buildLimitRangeCollector() *Collector {
-	store := metricsstore.NewMetricsStore(generateLimitRangeMetrics)
-	reflectorPerNamespace(b.ctx, b.kubeClient, &v1.LimitRange{}, store, b.namespaces, createLimitRangeListWatch)
+	store := b.newMetricsStore("limitranges", generateLimitRangeMetrics)
+	hasSynced := reflectorPerNamespace(b.ctx, b.kubeClient, &v1.LimitRange{}, "limitranges", store, b.resyncPeriod, b.namespaces, b.labelSelector, b.fieldSelectorFor("limitranges"), createLimitRangeListWatch)
+
+	return newCollector(store, hasSynced)
+}
 
-	return newCollector(store)
+func (b *Builder) buildMutatingWebhookConfigurationCollector() *Collector {
+	store := b.newMetricsStore("mutatingwebhookconfigurations", generateMutatingWebhookConfigurationMetrics)
+	hasSynced := reflectorPerNamespace(b.ctx, b.kubeClient, &admissionregistration.MutatingWebhookConfiguration{}, "mutatingwebhookconfigurations", store, b.resyncPeriod, b.namespaces, b.labelSelector, b.fieldSelectorFor("mutatingwebhookconfigurations"), createMutatingWebhookConfigurationListWatch)
+
+	return newCollector(store, hasSynced)
 }
 
 func (b *Builder) buildNamespaceCollector() *Collector {
-	store := metricsstore.NewMetricsStore(generateNamespaceMetrics)
-	reflectorPerNamespace(b.ctx, b.kubeClient, &v1.Namespace{}, store, b.namespaces, createNamespaceListWatch)
+	genFunc := func(obj interface{}) []*metrics.Metric {
+		return generateNamespaceMetrics(b.opts.EnableNamespaceAnnotations, b.allowedLabels("namespaces"), obj)
+	}
+	store := b.newMetricsStore("namespaces", genFunc)
+	hasSynced := reflectorPerNamespace(b.ctx, b.kubeClient, &v1.Namespace{}, "namespaces", store, b.resyncPeriod, b.namespaces, b.labelSelector, b.fieldSelectorFor("namespaces"), createNamespaceListWatch)
 
-	return newCollector(store)
+	return newCollector(store, hasSynced)
+}
+
+func (b *Builder) buildNetworkPolicyCollector() *Collector {
+	genFunc := func(obj interface{}) []*metrics.Metric {
+		return generateNetworkPolicyMetrics(b.allowedLabels("networkpolicies"), obj)
+	}
+	store := b.newMetricsStore("networkpolicies", genFunc)
+	hasSynced := reflectorPerNamespace(b.ctx, b.kubeClient, &networking.NetworkPolicy{}, "networkpolicies", store, b.resyncPeriod, b.namespaces, b.labelSelector, b.fieldSelectorFor("networkpolicies"), createNetworkPolicyListWatch)
+
+	return newCollector(store, hasSynced)
 }
 
 func (b *Builder) buildNodeCollector() *Collector {
 	genFunc := func(obj interface{}) []*metrics.Metric {
-		return generateNodeMetrics(b.opts.DisableNodeNonGenericResourceMetrics, obj)
+		return generateNodeMetrics(b.opts.DisableNodeNonGenericResourceMetrics, b.allowedLabels("nodes"), obj)
+	}
+	store := b.newMetricsStore("nodes", genFunc)
+	hasSynced := reflectorPerNamespace(b.ctx, b.kubeClient, &v1.Node{}, "nodes", store, b.resyncPeriod, b.namespaces, b.labelSelector, b.fieldSelectorFor("nodes"), createNodeListWatch)
+
+	// Node leases are listed and watched as unstructured objects, which
+	// requires a REST config. The heartbeat lag is skipped on clusters not
+	// serving leases, e.g. before Kubernetes 1.14, and if leases may not be
+	// listed and watched.
+	if b.restConfig == nil || !b.servesResource(leaseResource) {
+		return newCollector(store, hasSynced)
+	}
+	if !b.permitted(leaseResource, nodeLeaseNamespace) {
+		log.Warningf("Not permitted to list and watch leases in namespace %s, not exposing node heartbeat lags", nodeLeaseNamespace)
+		return newCollector(store, hasSynced)
+	}
+	client, err := customresource.NewClient(b.restConfig, leaseResource)
+	if err != nil {
+		log.Fatalf("Failed to create client for node leases: %v", err)
 	}
-	store := metricsstore.NewMetricsStore(genFunc)
-	reflectorPerNamespace(b.ctx, b.kubeClient, &v1.Node{}, store, b.namespaces, createNodeListWatch)
+	leases := cache.NewStore(cache.MetaNamespaceKeyFunc)
+	// Whether the leases have synced is ignored, as missing leases only
+	// result in missing heartbeat lags.
+	reflectorPerNamespace(b.ctx, b.kubeClient, &unstructured.Unstructured{}, "leases", leases, b.resyncPeriod, []string{nodeLeaseNamespace}, labels.Everything(), fields.Everything(), func(_ clientset.Interface, ns string) cache.ListWatch {
+		return client.ListWatch(ns)
+	})
 
-	return newCollector(store)
+	heartbeatLag := metrics.FilteredGenerateFunc(
+		metrics.PrefixedGenerateFunc(b.labeledGenerateFunc(func(obj interface{}) []*metrics.Metric {
+			return generateNodeHeartbeatLagMetrics(obj.([]string), leases, time.Now())
+		}), b.metricPrefix),
+		b.metricWhitelist,
+		b.metricBlacklist,
+	)
+
+	return newCollector(
+		nodeStore{
+			MetricsStore: store,
+			heartbeatLag: func(nodes []string) []*metrics.Metric { return heartbeatLag(nodes) },
+		},
+		hasSynced,
+	)
 }
 
 func (b *Builder) buildPersistentVolumeCollector() *Collector {
-	store := metricsstore.NewMetricsStore(generatePersistentVolumeMetrics)
-	reflectorPerNamespace(b.ctx, b.kubeClient, &v1.PersistentVolume{}, store, b.namespaces, createPersistentVolumeListWatch)
+	genFunc := func(obj interface{}) []*metrics.Metric {
+		return generatePersistentVolumeMetrics(b.allowedLabels("persistentvolumes"), obj)
+	}
+	store := b.newMetricsStore("persistentvolumes", genFunc)
+	hasSynced := reflectorPerNamespace(b.ctx, b.kubeClient, &v1.PersistentVolume{}, "persistentvolumes", store, b.resyncPeriod, b.namespaces, b.labelSelector, b.fieldSelectorFor("persistentvolumes"), createPersistentVolumeListWatch)
 
-	return newCollector(store)
+	return newCollector(store, hasSynced)
 }
 
 func (b *Builder) buildPersistentVolumeClaimCollector() *Collector {
-	store := metricsstore.NewMetricsStore(generatePersistentVolumeClaimMetrics)
-	reflectorPerNamespace(b.ctx, b.kubeClient, &v1.PersistentVolumeClaim{}, store, b.namespaces, createPersistentVolumeClaimListWatch)
+	genFunc := func(obj interface{}) []*metrics.Metric {
+		return generatePersistentVolumeClaimMetrics(b.allowedLabels("persistentvolumeclaims"), obj)
+	}
+	store := b.newMetricsStore("persistentvolumeclaims", genFunc)
+	hasSynced := reflectorPerNamespace(b.ctx, b.kubeClient, &v1.PersistentVolumeClaim{}, "persistentvolumeclaims", store, b.resyncPeriod, b.namespaces, b.labelSelector, b.fieldSelectorFor("persistentvolumeclaims"), createPersistentVolumeClaimListWatch)
 
-	return newCollector(store)
+	return newCollector(store, hasSynced)
 }
 
 func (b *Builder) buildPodDisruptionBudgetCollector() *Collector {
-	store := metricsstore.NewMetricsStore(generatePodDisruptionBudgetMetrics)
-	reflectorPerNamespace(b.ctx, b.kubeClient, &v1beta1.PodDisruptionBudget{}, store, b.namespaces, createPodDisruptionBudgetListWatch)
+	store := b.newMetricsStore("poddisruptionbudgets", generatePodDisruptionBudgetMetrics)
+	hasSynced := reflectorPerNamespace(b.ctx, b.kubeClient, &v1beta1.PodDisruptionBudget{}, "poddisruptionbudgets", store, b.resyncPeriod, b.namespaces, b.labelSelector, b.fieldSelectorFor("poddisruptionbudgets"), createPodDisruptionBudgetListWatch)
+
+	return newCollector(store, hasSynced)
+}
+
+func (b *Builder) buildPriorityClassCollector() *Collector {
+	store := b.newMetricsStore("priorityclasses", generatePriorityClassMetrics)
+	hasSynced := reflectorPerNamespace(b.ctx, b.kubeClient, &scheduling.PriorityClass{}, "priorityclasses", store, b.resyncPeriod, b.namespaces, b.labelSelector, b.fieldSelectorFor("priorityclasses"), createPriorityClassListWatch)
 
-	return newCollector(store)
+	return newCollector(store, hasSynced)
 }
 
 func (b *Builder) buildReplicaSetCollector() *Collector {
-	store := metricsstore.NewMetricsStore(generateReplicaSetMetrics)
-	reflectorPerNamespace(b.ctx, b.kubeClient, &extensions.ReplicaSet{}, store, b.namespaces, createReplicaSetListWatch)
+	store := b.newMetricsStore("replicasets", generateReplicaSetMetrics)
+	hasSynced := reflectorPerNamespace(b.ctx, b.kubeClient, &extensions.ReplicaSet{}, "replicasets", store, b.resyncPeriod, b.namespaces, b.labelSelector, b.fieldSelectorFor("replicasets"), createReplicaSetListWatch)
 
-	return newCollector(store)
+	return newCollector(store, hasSynced)
 }
 
 func (b *Builder) buildReplicationControllerCollector() *Collector {
-	store := metricsstore.NewMetricsStore(generateReplicationControllerMetrics)
-	reflectorPerNamespace(b.ctx, b.kubeClient, &v1.ReplicationController{}, store, b.namespaces, createReplicationControllerListWatch)
+	store := b.newMetricsStore("replicationcontrollers", generateReplicationControllerMetrics)
+	hasSynced := reflectorPerNamespace(b.ctx, b.kubeClient, &v1.ReplicationController{}, "replicationcontrollers", store, b.resyncPeriod, b.namespaces, b.labelSelector, b.fieldSelectorFor("replicationcontrollers"), createReplicationControllerListWatch)
 
-	return newCollector(store)
+	return newCollector(store, hasSynced)
 }
 
 func (b *Builder) buildResourceQuotaCollector() *Collector {
-	store := metricsstore.NewMetricsStore(generateResourceQuotaMetrics)
-	reflectorPerNamespace(b.ctx, b.kubeClient, &v1.ResourceQuota{}, store, b.namespaces, createResourceQuotaListWatch)
+	store := b.newMetricsStore("resourcequotas", generateResourceQuotaMetrics)
+	hasSynced := reflectorPerNamespace(b.ctx, b.kubeClient, &v1.ResourceQuota{}, "resourcequotas", store, b.resyncPeriod, b.namespaces, b.labelSelector, b.fieldSelectorFor("resourcequotas"), createResourceQuotaListWatch)
 
-	return newCollector(store)
+	return newCollector(store, hasSynced)
 }
 
 func (b *Builder) buildSecretCollector() *Collector {
-	store := metricsstore.NewMetricsStore(generateSecretMetrics)
-	reflectorPerNamespace(b.ctx, b.kubeClient, &v1.Secret{}, store, b.namespaces, createSecretListWatch)
+	genFunc := func(obj interface{}) []*metrics.Metric {
+		return generateSecretMetrics(b.allowedLabels("secrets"), obj)
+	}
+	store := b.newMetricsStore("secrets", genFunc)
+	hasSynced := reflectorPerNamespace(b.ctx, b.kubeClient, &v1.Secret{}, "secrets", store, b.resyncPeriod, b.namespaces, b.labelSelector, b.fieldSelectorFor("secrets"), createSecretListWatch)
 
-	return newCollector(store)
+	return newCollector(store, hasSynced)
 }
 
 func (b *Builder) buildServiceCollector() *Collector {
-	store := metricsstore.NewMetricsStore(generateServiceMetrics)
-	reflectorPerNamespace(b.ctx, b.kubeClient, &v1.Service{}, store, b.namespaces, createServiceListWatch)
+	genFunc := func(obj interface{}) []*metrics.Metric {
+		return generateServiceMetrics(b.allowedLabels("services"), obj)
+	}
+	store := b.newMetricsStore("services", genFunc)
+	hasSynced := reflectorPerNamespace(b.ctx, b.kubeClient, &v1.Service{}, "services", store, b.resyncPeriod, b.namespaces, b.labelSelector, b.fieldSelectorFor("services"), createServiceListWatch)
 
-	return newCollector(store)
+	return newCollector(store, hasSynced)
 }
 
 func (b *Builder) buildStatefulSetCollector() *Collector {
-	store := metricsstore.NewMetricsStore(generateStatefulSetMetrics)
-	reflectorPerNamespace(b.ctx, b.kubeClient, &apps.StatefulSet{}, store, b.namespaces, createStatefulSetListWatch)
+	genFunc := func(obj interface{}) []*metrics.Metric {
+		return generateStatefulSetMetrics(b.allowedLabels("statefulsets"), obj)
+	}
+	store := b.newMetricsStore("statefulsets", genFunc)
+	hasSynced := reflectorPerNamespace(b.ctx, b.kubeClient, &apps.StatefulSet{}, "statefulsets", store, b.resyncPeriod, b.namespaces, b.labelSelector, b.fieldSelectorFor("statefulsets"), createStatefulSetListWatch)
+
+	return newCollector(store, hasSynced)
+}
+
+func (b *Builder) buildValidatingWebhookConfigurationCollector() *Collector {
+	store := b.newMetricsStore("validatingwebhookconfigurations", generateValidatingWebhookConfigurationMetrics)
+	hasSynced := reflectorPerNamespace(b.ctx, b.kubeClient, &admissionregistration.ValidatingWebhookConfiguration{}, "validatingwebhookconfigurations", store, b.resyncPeriod, b.namespaces, b.labelSelector, b.fieldSelectorFor("validatingwebhookconfigurations"), createValidatingWebhookConfigurationListWatch)
+
+	return newCollector(store, hasSynced)
+}
+
+// allowedLabels returns the label keys of the objects of the given collector
+// exposed by its labels metric. It returns nil, allowing all labels, if no
+// labels allowlist is set, and an empty list, suppressing the labels metric,
+// for collectors missing from a set labels allowlist.
+func (b *Builder) allowedLabels(collector string) []string {
+	if len(b.opts.LabelsAllowlist) == 0 {
+		return nil
+	}
+	if keys, ok := b.opts.LabelsAllowlist[collector]; ok && keys != nil {
+		return keys
+	}
+	return []string{}
+}
+
+// newMetricsStore returns a new MetricsStore using the given function to
+// generate metrics, labeled with the configured constant labels, prefixed with
+// the configured metric prefix, filtered by the configured metric white- or
+// blacklist and restricted to the objects of the configured shard. If enabled,
+// the metrics are timestamped with the time their object was observed. Panics
+// generating the metrics of an object are recovered from and counted for the
+// given collector.
+func (b *Builder) newMetricsStore(collector string, generateFunc func(interface{}) []*metrics.Metric) *metricsstore.MetricsStore {
+	if b.disableCreated {
+		generateFunc = withoutCreatedMetrics(generateFunc)
+	}
+	generateFunc = b.labeledGenerateFunc(generateFunc)
+	if b.metricTimestamps {
+		generateFunc = metrics.TimestampedGenerateFunc(generateFunc, time.Now)
+	}
+	return metricsstore.NewMetricsStore(
+		withPanicRecovery(
+			collector,
+			withNamespaceDenylist(
+				shardedGenerateFunc(
+					metrics.FilteredGenerateFunc(
+						metrics.PrefixedGenerateFunc(generateFunc, b.metricPrefix),
+						b.metricWhitelist,
+						b.metricBlacklist,
+					),
+					b.shard,
+					b.totalShards,
+				),
+				b.namespaceDenylist,
+			),
+		),
+	)
+}
+
+// labeledGenerateFunc wraps generateFunc to add the cluster label, if the
+// Builder was copied for a cluster, and the configured constant labels to
+// every metric, sorted by label name.
+func (b *Builder) labeledGenerateFunc(generateFunc func(interface{}) []*metrics.Metric) func(interface{}) []*metrics.Metric {
+	names := []string{}
+	for name := range b.metricLabels {
+		names = append(names, name)
+	}
+	sort.Sort(sort.Reverse(sort.StringSlice(names)))
+	for _, name := range names {
+		generateFunc = metrics.LabeledGenerateFunc(generateFunc, name, b.metricLabels[name])
+	}
+	if b.cluster != "" {
+		generateFunc = metrics.LabeledGenerateFunc(generateFunc, "cluster", b.cluster)
+	}
+	return generateFunc
+}
 
-	return newCollector(store)
+// inResourceScope reports whether the collector of the given resource is
+// built in the scope set with WithResourceScope.
+func (b *Builder) inResourceScope(r customresource.Resource) bool {
+	switch b.resourceScope {
+	case options.ResourceScopeCluster:
+		return !r.Namespaced
+	case options.ResourceScopeNamespaced:
+		return r.Namespaced
+	}
+	return true
+}
+
+// fieldSelectorFor returns the field selector restricting the objects watched
+// by the given collector. The field selector set with WithFieldSelector only
+// applies to pods and nodes, the name set with WithResourceName applies to all
+// collectors.
+func (b *Builder) fieldSelectorFor(collector string) fields.Selector {
+	selectors := []fields.Selector{}
+	if (collector == "pods" || collector == "nodes") && !b.fieldSelector.Empty() {
+		selectors = append(selectors, b.fieldSelector)
+	}
+	if b.resourceName != "" {
+		selectors = append(selectors, fields.OneTermEqualSelector("metadata.name", b.resourceName))
+	}
+	return fields.AndSelectors(selectors...)
 }
 
+// reflectorPerNamespace starts one reflector per namespace feeding the given
+// store. Failing list and watch requests are counted as scrape errors of the
+// given resource. A non-zero resyncPeriod makes the reflectors relist all
+// objects after every period. The returned function reports whether all
+// reflectors have completed their initial list.
 func reflectorPerNamespace(
 	ctx context.Context,
 	kubeClient clientset.Interface,
 	expectedType interface{},
+	resource string,
 	store cache.Store,
+	resyncPeriod time.Duration,
 	namespaces []string,
+	labelSelector labels.Selector,
+	fieldSelector fields.Selector,
 	listWatchFunc func(kubeClient clientset.Interface, ns string) cache.ListWatch,
-) {
+) func() bool {
+	synced := []*syncedStore{}
 	for _, ns := range namespaces {
 		lw := listWatchFunc(kubeClient, ns)
-		reflector := cache.NewReflector(&lw, expectedType, store, 0)
-		go reflector.Run(ctx.Done())
+		lw = withErrorMetric(withFieldSelector(withLabelSelector(lw, labelSelector), fieldSelector), resource)
+		s := &syncedStore{Store: store, namespace: ns}
+		synced = append(synced, s)
+		reflector := cache.NewReflector(&lw, expectedType, s, 0)
+		go runReflector(ctx, reflector, resyncPeriod)
+	}
+
+	return func() bool {
+		for _, s := range synced {
+			if !s.hasSynced() {
+				return false
+			}
+		}
+		return true
+	}
+}
+
+// runReflector runs the given reflector until ctx is done. With a non-zero
+// resyncPeriod, the reflector is stopped after every period and restarted,
+// which relists all objects. Resyncs of the reflector itself only hand the
+// stored objects to the store again, which is of no use for metrics stores.
+func runReflector(ctx context.Context, reflector *cache.Reflector, resyncPeriod time.Duration) {
+	if resyncPeriod == 0 {
+		reflector.Run(ctx.Done())
+		return
+	}
+	for {
+		stop := make(chan struct{})
+		go func() {
+			select {
+			case <-time.After(resyncPeriod):
+			case <-ctx.Done():
+			}
+			close(stop)
+		}()
+		reflector.Run(stop)
+
+		select {
+		case <-ctx.Done():
+			return
+		default:
+		}
+	}
+}
+
+// namespaceReplacer is implemented by stores shared by the reflectors of
+// several namespaces, see metricsstore.MetricsStore.ReplaceNamespace.
+type namespaceReplacer interface {
+	ReplaceNamespace(namespace string, list []interface{}, resourceVersion string) error
+}
+
+// syncedStore wraps the store of a single reflector to record whether the
+// reflector has completed its initial list, i.e. replaced the contents of the
+// store. If the store is shared with the reflectors of other namespaces, only
+// the objects of the reflector's namespace are replaced.
+type syncedStore struct {
+	cache.Store
+	namespace string
+	synced    int32
+}
+
+func (s *syncedStore) Replace(list []interface{}, resourceVersion string) error {
+	var err error
+	if r, ok := s.Store.(namespaceReplacer); ok {
+		err = r.ReplaceNamespace(s.namespace, list, resourceVersion)
+	} else {
+		err = s.Store.Replace(list, resourceVersion)
+	}
+	if err != nil {
+		return err
+	}
+	atomic.StoreInt32(&s.synced, 1)
+	return nil
+}
+
+func (s *syncedStore) hasSynced() bool {
+	return atomic.LoadInt32(&s.synced) == 1
+}
+
+// withLabelSelector restricts the given ListWatch to objects matching the
+// given label selector.
+func withLabelSelector(lw cache.ListWatch, selector labels.Selector) cache.ListWatch {
+	if selector == nil || selector.Empty() {
+		return lw
+	}
+
+	listFunc, watchFunc := lw.ListFunc, lw.WatchFunc
+	lw.ListFunc = func(opts metav1.ListOptions) (runtime.Object, error) {
+		opts.LabelSelector = selector.String()
+		return listFunc(opts)
+	}
+	lw.WatchFunc = func(opts metav1.ListOptions) (watch.Interface, error) {
+		opts.LabelSelector = selector.String()
+		return watchFunc(opts)
+	}
+	return lw
+}
+
+// forbiddenResources holds the resources a forbidden list or watch request was
+// logged for, to only log once per resource.
+var forbiddenResources sync.Map
+
+// withErrorMetric counts failing list and watch requests of the given
+// ListWatch as scrape errors of the given resource, with the reason forbidden
+// if the service account lacks the permission to list or watch the resource,
+// or error otherwise. The reflector retries failed requests, hence forbidden
+// requests are only logged once per resource.
+func withErrorMetric(lw cache.ListWatch, resource string) cache.ListWatch {
+	countError := func(err error) {
+		if !apierrors.IsForbidden(err) {
+			ScrapeErrorTotalMetric.WithLabelValues(resource, "error").Inc()
+			return
+		}
+		ScrapeErrorTotalMetric.WithLabelValues(resource, "forbidden").Inc()
+		if _, logged := forbiddenResources.LoadOrStore(resource, true); !logged {
+			log.Errorf("Not permitted to list and watch %s, check the RBAC rules of the service account: %v", resource, err)
+		}
+	}
+
+	listFunc, watchFunc := lw.ListFunc, lw.WatchFunc
+	lw.ListFunc = func(opts metav1.ListOptions) (runtime.Object, error) {
+		obj, err := listFunc(opts)
+		if err != nil {
+			countError(err)
+		}
+		return obj, err
+	}
+	lw.WatchFunc = func(opts metav1.ListOptions) (watch.Interface, error) {
+		w, err := watchFunc(opts)
+		if err != nil {
+			countError(err)
+		}
+		return w, err
+	}
+	return lw
+}
+
+const (
+	minReflectorErrorBackoff = time.Second
+	maxReflectorErrorBackoff = 5 * time.Minute
+)
+
+// registerReflectorErrorLogger replaces the glog based logging of the errors
+// the reflectors of the collectors report via utilruntime.HandleError with a
+// reflectorErrorLogger, keeping the other error handlers. Failed list and
+// watch requests are counted by withErrorMetric already.
+var registerReflectorErrorLogger = func() func() {
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			l := newReflectorErrorLogger(time.Now, log.Errorf)
+			utilruntime.ErrorHandlers = append([]func(error){l.handle}, utilruntime.ErrorHandlers[1:]...)
+		})
+	}
+}()
+
+// reflectorErrorLogger logs errors, suppressing repetitions of the same error
+// with exponential backoff and jitter. The reflectors retry failed list and
+// watch requests every second, hence an unreachable API server, e.g. during a
+// control plane restart, would otherwise log the same error every second per
+// collector and namespace.
+type reflectorErrorLogger struct {
+	mtx    sync.Mutex
+	now    func() time.Time
+	logf   func(format string, args ...interface{})
+	errors map[string]*repeatedError
+}
+
+// repeatedError is an error logged by a reflectorErrorLogger.
+type repeatedError struct {
+	// next is the time the error is logged again at the earliest.
+	next       time.Time
+	backoff    time.Duration
+	suppressed int
+}
+
+func newReflectorErrorLogger(now func() time.Time, logf func(format string, args ...interface{})) *reflectorErrorLogger {
+	return &reflectorErrorLogger{
+		now:    now,
+		logf:   logf,
+		errors: map[string]*repeatedError{},
+	}
+}
+
+func (l *reflectorErrorLogger) handle(err error) {
+	l.mtx.Lock()
+	defer l.mtx.Unlock()
+
+	now := l.now()
+	l.forget(now)
+
+	msg := err.Error()
+	e, ok := l.errors[msg]
+	if !ok {
+		l.errors[msg] = &repeatedError{
+			next:    now.Add(wait.Jitter(minReflectorErrorBackoff, 0.1)),
+			backoff: minReflectorErrorBackoff,
+		}
+		l.logf("%s", msg)
+		return
+	}
+	if now.Before(e.next) {
+		e.suppressed++
+		return
+	}
+
+	l.logf("%s (repeated %d times since last logged)", msg, e.suppressed+1)
+	e.suppressed = 0
+	e.backoff *= 2
+	if e.backoff > maxReflectorErrorBackoff {
+		e.backoff = maxReflectorErrorBackoff
+	}
+	e.next = now.Add(wait.Jitter(e.backoff, 0.1))
+}
+
+// forget removes the errors which have not been logged again for longer than
+// the maximum backoff, logging the number of their suppressed repetitions, so
+// that their next occurrence is logged right away.
+func (l *reflectorErrorLogger) forget(now time.Time) {
+	for msg, e := range l.errors {
+		if now.Sub(e.next) <= maxReflectorErrorBackoff {
+			continue
+		}
+		if e.suppressed > 0 {
+			l.logf("%s (repeated %d more times)", msg, e.suppressed)
+		}
+		delete(l.errors, msg)
+	}
+}
+
+// withoutCreatedMetrics wraps a function generating metrics for a Kubernetes
+// object to drop the creation timestamp metrics, i.e. the ones named
+// *_created.
+func withoutCreatedMetrics(f func(interface{}) []*metrics.Metric) func(interface{}) []*metrics.Metric {
+	return func(obj interface{}) []*metrics.Metric {
+		ms := []*metrics.Metric{}
+		for _, m := range f(obj) {
+			if !strings.HasSuffix(m.Name(), "_created") {
+				ms = append(ms, m)
+			}
+		}
+		return ms
+	}
+}
+
+// withPanicRecovery wraps a function generating metrics for a Kubernetes
+// object to recover from panics, e.g. on unexpected object shapes, which would
+// otherwise crash the reflector goroutine and the process. The panic is logged
+// and counted for the given collector, and no metrics are generated for the
+// object.
+func withPanicRecovery(collector string, f func(interface{}) []*metrics.Metric) func(interface{}) []*metrics.Metric {
+	return func(obj interface{}) (ms []*metrics.Metric) {
+		defer func() {
+			if r := recover(); r != nil {
+				name := ""
+				if o, err := meta.Accessor(obj); err == nil {
+					name = o.GetNamespace() + "/" + o.GetName()
+				}
+				log.Errorf("Recovered from panic generating the %s metrics of %s: %v", collector, name, r)
+				CollectorPanicTotalMetric.WithLabelValues(collector).Inc()
+				ms = nil
+			}
+		}()
+		return f(obj)
+	}
+}
+
+// withNamespaceDenylist wraps a function generating metrics for a Kubernetes
+// object to not generate any metrics for objects in the given namespaces, nor
+// for the namespaces themselves. Field selectors on the namespace are not
+// supported for cluster-scoped resources, hence objects are filtered after
+// being watched.
+func withNamespaceDenylist(f func(interface{}) []*metrics.Metric, denylist []string) func(interface{}) []*metrics.Metric {
+	if len(denylist) == 0 {
+		return f
+	}
+
+	denied := map[string]bool{}
+	for _, ns := range denylist {
+		denied[ns] = true
+	}
+
+	return func(obj interface{}) []*metrics.Metric {
+		o, err := meta.Accessor(obj)
+		if err != nil || denied[o.GetNamespace()] {
+			return nil
+		}
+		// Namespaces are cluster-scoped, hence denied by their name.
+		if _, ok := obj.(*v1.Namespace); ok && denied[o.GetName()] {
+			return nil
+		}
+		return f(obj)
+	}
+}
+
+// withFieldSelector restricts the given ListWatch to objects matching the
+// given field selector. Field selectors are only validated by the API server,
+// hence invalid ones surface as scrape errors, see withErrorMetric.
+func withFieldSelector(lw cache.ListWatch, selector fields.Selector) cache.ListWatch {
+	if selector == nil || selector.Empty() {
+		return lw
+	}
+
+	listFunc, watchFunc := lw.ListFunc, lw.WatchFunc
+	lw.ListFunc = func(opts metav1.ListOptions) (runtime.Object, error) {
+		opts.FieldSelector = selector.String()
+		return listFunc(opts)
+	}
+	lw.WatchFunc = func(opts metav1.ListOptions) (watch.Interface, error) {
+		opts.FieldSelector = selector.String()
+		return watchFunc(opts)
 	}
+	return lw
 }
@@ -0,0 +1,815 @@
+/*
+Copyright 2018 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package collectors
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"sort"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+	"golang.org/x/net/context"
+	authorization "k8s.io/api/authorization/v1"
+	"k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/rest"
+	k8stesting "k8s.io/client-go/testing"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/kube-state-metrics/pkg/metrics"
+	metricsstore "k8s.io/kube-state-metrics/pkg/metrics_store"
+	"k8s.io/kube-state-metrics/pkg/options"
+)
+
+func TestWithLabelSelector(t *testing.T) {
+	tests := []struct {
+		Selector string
+		Want     string
+	}{
+		{Selector: "", Want: ""},
+		{Selector: "app in (foo,bar),tier!=db", Want: "app in (bar,foo),tier!=db"},
+	}
+
+	for _, test := range tests {
+		selector, err := labels.Parse(test.Selector)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		var listSelector, watchSelector string
+		lw := withLabelSelector(cache.ListWatch{
+			ListFunc: func(opts metav1.ListOptions) (runtime.Object, error) {
+				listSelector = opts.LabelSelector
+				return nil, nil
+			},
+			WatchFunc: func(opts metav1.ListOptions) (watch.Interface, error) {
+				watchSelector = opts.LabelSelector
+				return nil, nil
+			},
+		}, selector)
+
+		lw.List(metav1.ListOptions{})
+		lw.Watch(metav1.ListOptions{})
+
+		if listSelector != test.Want || watchSelector != test.Want {
+			t.Errorf("expected label selector %q for list and watch, got %q and %q", test.Want, listSelector, watchSelector)
+		}
+	}
+}
+
+func TestWithFieldSelector(t *testing.T) {
+	selector, err := fields.ParseSelector("spec.nodeName!=master")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var listSelector string
+	lw := withFieldSelector(cache.ListWatch{
+		ListFunc: func(opts metav1.ListOptions) (runtime.Object, error) {
+			listSelector = opts.FieldSelector
+			return nil, errors.New("field label not supported")
+		},
+	}, selector)
+
+	if _, err := lw.List(metav1.ListOptions{}); err == nil {
+		t.Fatal("expected list error to be passed through")
+	}
+
+	if listSelector != "spec.nodeName!=master" {
+		t.Errorf("expected field selector %q, got %q", "spec.nodeName!=master", listSelector)
+	}
+}
+
+func TestFieldSelectorFor(t *testing.T) {
+	nodeSelector, err := fields.ParseSelector("spec.nodeName!=master")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		Desc           string
+		FieldSelector  fields.Selector
+		ResourceName   string
+		Collector      string
+		WantedSelector string
+	}{
+		{
+			Desc:           "no selectors",
+			FieldSelector:  fields.Everything(),
+			Collector:      "pods",
+			WantedSelector: "",
+		},
+		{
+			Desc:           "field selector on pods",
+			FieldSelector:  nodeSelector,
+			Collector:      "pods",
+			WantedSelector: "spec.nodeName!=master",
+		},
+		{
+			Desc:           "field selector on other collectors",
+			FieldSelector:  nodeSelector,
+			Collector:      "deployments",
+			WantedSelector: "",
+		},
+		{
+			Desc:           "resource name",
+			FieldSelector:  fields.Everything(),
+			ResourceName:   "kube-dns",
+			Collector:      "deployments",
+			WantedSelector: "metadata.name=kube-dns",
+		},
+		{
+			Desc:           "resource name and field selector on pods",
+			FieldSelector:  nodeSelector,
+			ResourceName:   "kube-dns-0",
+			Collector:      "pods",
+			WantedSelector: "spec.nodeName!=master,metadata.name=kube-dns-0",
+		},
+	}
+
+	for _, test := range tests {
+		b := NewBuilder(context.TODO(), options.NewOptions())
+		b.WithFieldSelector(test.FieldSelector)
+		b.WithResourceName(test.ResourceName)
+		if got := b.fieldSelectorFor(test.Collector).String(); got != test.WantedSelector {
+			t.Errorf("Test error for Desc: %s. Wanted field selector %q, got %q", test.Desc, test.WantedSelector, got)
+		}
+	}
+}
+
+func TestBuildWithResourceName(t *testing.T) {
+	kubeClient := fake.NewSimpleClientset()
+	var listSelector string
+	kubeClient.PrependReactor("list", "deployments", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		listSelector = action.(k8stesting.ListAction).GetListRestrictions().Fields.String()
+		return false, nil, nil
+	})
+
+	b := NewBuilder(context.TODO(), options.NewOptions())
+	b.WithEnabledCollectors(options.CollectorSet{"deployments": struct{}{}})
+	b.WithKubeClient(kubeClient)
+	b.WithNamespaces(options.NamespaceList{"kube-system"})
+	b.WithResourceName("kube-dns")
+	collectors := b.Build()
+
+	if err := waitFor(collectors[0].HasSynced); err != nil {
+		t.Fatal(err)
+	}
+	if listSelector != "metadata.name=kube-dns" {
+		t.Errorf("expected field selector %q, got %q", "metadata.name=kube-dns", listSelector)
+	}
+}
+
+func TestBuildWithResyncPeriod(t *testing.T) {
+	kubeClient := fake.NewSimpleClientset()
+	var lists int32
+	kubeClient.PrependReactor("list", "deployments", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		atomic.AddInt32(&lists, 1)
+		return false, nil, nil
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	b := NewBuilder(ctx, options.NewOptions())
+	b.WithEnabledCollectors(options.CollectorSet{"deployments": struct{}{}})
+	b.WithKubeClient(kubeClient)
+	b.WithNamespaces(options.NamespaceList{"kube-system"})
+	b.WithResyncPeriod(10 * time.Millisecond)
+	b.Build()
+
+	// The reflector waits a second before relisting after the watch ended.
+	deadline := time.Now().Add(5 * time.Second)
+	for atomic.LoadInt32(&lists) < 2 {
+		if time.Now().After(deadline) {
+			t.Fatalf("expected deployments to be relisted after the resync period, got %d lists", atomic.LoadInt32(&lists))
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestBuildServedResources(t *testing.T) {
+	tests := []struct {
+		Desc           string
+		Collector      string
+		Resources      []*metav1.APIResourceList
+		WantedRequests bool
+	}{
+		{
+			Desc:           "CRD not installed",
+			Collector:      "verticalpodautoscalers",
+			WantedRequests: false,
+		},
+		{
+			Desc:      "other resources of the group version served",
+			Collector: "verticalpodautoscalers",
+			Resources: []*metav1.APIResourceList{
+				{GroupVersion: "autoscaling.k8s.io/v1", APIResources: []metav1.APIResource{{Name: "verticalpodautoscalercheckpoints"}}},
+			},
+			WantedRequests: false,
+		},
+		{
+			Desc:      "CRD installed",
+			Collector: "verticalpodautoscalers",
+			Resources: []*metav1.APIResourceList{
+				{GroupVersion: "autoscaling.k8s.io/v1", APIResources: []metav1.APIResource{{Name: "verticalpodautoscalers"}}},
+			},
+			WantedRequests: true,
+		},
+		{
+			Desc:           "runtime classes not served",
+			Collector:      "runtimeclasses",
+			WantedRequests: false,
+		},
+		{
+			Desc:      "runtime classes served",
+			Collector: "runtimeclasses",
+			Resources: []*metav1.APIResourceList{
+				{GroupVersion: "node.k8s.io/v1beta1", APIResources: []metav1.APIResource{{Name: "runtimeclasses"}}},
+			},
+			WantedRequests: true,
+		},
+	}
+
+	for _, test := range tests {
+		var requests int32
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			atomic.AddInt32(&requests, 1)
+			http.NotFound(w, r)
+		}))
+
+		kubeClient := fake.NewSimpleClientset()
+		kubeClient.Resources = test.Resources
+
+		ctx, cancel := context.WithCancel(context.Background())
+		b := NewBuilder(ctx, options.NewOptions())
+		b.WithEnabledCollectors(options.CollectorSet{test.Collector: struct{}{}})
+		b.WithKubeClient(kubeClient)
+		b.WithRESTConfig(&rest.Config{Host: server.URL})
+		b.WithNamespaces(options.DefaultNamespaces)
+		collectors := b.Build()
+
+		if len(collectors) != 1 {
+			t.Fatalf("%s: expected the collector to be built, got %d collectors", test.Desc, len(collectors))
+		}
+		if test.WantedRequests {
+			if err := waitFor(func() bool { return atomic.LoadInt32(&requests) > 0 }); err != nil {
+				t.Errorf("%s: expected the resource to be listed", test.Desc)
+			}
+		} else {
+			if !collectors[0].HasSynced() {
+				t.Errorf("%s: expected collector of an unserved resource to be synced", test.Desc)
+			}
+			if n := atomic.LoadInt32(&requests); n != 0 {
+				t.Errorf("%s: expected no requests for an unserved resource, got %d", test.Desc, n)
+			}
+		}
+
+		cancel()
+		server.Close()
+	}
+}
+
+func TestBuildNodeCollectorLeases(t *testing.T) {
+	leases := []*metav1.APIResourceList{
+		{GroupVersion: "coordination.k8s.io/v1", APIResources: []metav1.APIResource{{Name: "leases"}}},
+	}
+	tests := []struct {
+		Desc           string
+		Resources      []*metav1.APIResourceList
+		Allowed        bool
+		WantedRequests bool
+	}{
+		{
+			Desc:           "leases not served",
+			Allowed:        true,
+			WantedRequests: false,
+		},
+		{
+			Desc:           "leases forbidden",
+			Resources:      leases,
+			Allowed:        false,
+			WantedRequests: false,
+		},
+		{
+			Desc:           "leases served and permitted",
+			Resources:      leases,
+			Allowed:        true,
+			WantedRequests: true,
+		},
+	}
+
+	for _, test := range tests {
+		var requests int32
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			atomic.AddInt32(&requests, 1)
+			http.NotFound(w, r)
+		}))
+
+		kubeClient := fake.NewSimpleClientset(&v1.Node{ObjectMeta: metav1.ObjectMeta{Name: "node1"}})
+		kubeClient.Resources = test.Resources
+		allowed := test.Allowed
+		kubeClient.PrependReactor("create", "selfsubjectaccessreviews", func(action k8stesting.Action) (bool, runtime.Object, error) {
+			review := action.(k8stesting.CreateAction).GetObject().(*authorization.SelfSubjectAccessReview)
+			review.Status.Allowed = allowed
+			return true, review, nil
+		})
+
+		ctx, cancel := context.WithCancel(context.Background())
+		b := NewBuilder(ctx, options.NewOptions())
+		b.WithEnabledCollectors(options.CollectorSet{"nodes": struct{}{}})
+		b.WithKubeClient(kubeClient)
+		b.WithRESTConfig(&rest.Config{Host: server.URL})
+		b.WithNamespaces(options.DefaultNamespaces)
+		collectors := b.Build()
+
+		// Nodes are synced whether or not the leases can be listed.
+		if err := waitFor(collectors[0].HasSynced); err != nil {
+			t.Errorf("%s: expected the nodes collector to be synced", test.Desc)
+		}
+		if test.WantedRequests {
+			if err := waitFor(func() bool { return atomic.LoadInt32(&requests) > 0 }); err != nil {
+				t.Errorf("%s: expected the leases to be listed", test.Desc)
+			}
+		} else if n := atomic.LoadInt32(&requests); n != 0 {
+			t.Errorf("%s: expected no requests for leases, got %d", test.Desc, n)
+		}
+
+		cancel()
+		server.Close()
+	}
+}
+
+func TestBuildWithResyncPeriodMultipleNamespaces(t *testing.T) {
+	kubeClient := fake.NewSimpleClientset(
+		&v1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: "cm1", Namespace: "ns1"}},
+		&v1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: "cm2", Namespace: "ns2"}},
+	)
+	var ns1Lists, ns2Lists int32
+	kubeClient.PrependReactor("list", "configmaps", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		if action.GetNamespace() == "ns1" {
+			atomic.AddInt32(&ns1Lists, 1)
+		} else {
+			atomic.AddInt32(&ns2Lists, 1)
+		}
+		return false, nil, nil
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	b := NewBuilder(ctx, options.NewOptions())
+	b.WithEnabledCollectors(options.CollectorSet{"configmaps": struct{}{}})
+	b.WithKubeClient(kubeClient)
+	b.WithNamespaces(options.NamespaceList{"ns1", "ns2"})
+	b.WithResyncPeriod(10 * time.Millisecond)
+	collectors := b.Build()
+
+	if err := waitFor(collectors[0].HasSynced); err != nil {
+		t.Fatal(err)
+	}
+
+	// The relist of one namespace must not drop the objects of the other
+	// namespace from the shared store.
+	deadline := time.Now().Add(5 * time.Second)
+	for atomic.LoadInt32(&ns1Lists) < 3 || atomic.LoadInt32(&ns2Lists) < 3 {
+		if time.Now().After(deadline) {
+			t.Fatalf("expected both namespaces to be relisted, got %d and %d lists", atomic.LoadInt32(&ns1Lists), atomic.LoadInt32(&ns2Lists))
+		}
+		if n, _ := collectors[0].objects(); n != 2 {
+			t.Fatalf("expected both config maps to be stored, got %d", n)
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestBuildWithResourceScope(t *testing.T) {
+	enabled := options.CollectorSet{}
+	for _, c := range []string{"deployments", "namespaces", "nodes", "persistentvolumes", "pods"} {
+		enabled[c] = struct{}{}
+	}
+
+	tests := []struct {
+		Scope  string
+		Wanted []string
+	}{
+		{Scope: options.ResourceScopeAll, Wanted: []string{"deployments", "namespaceobjectcounts", "namespaces", "nodes", "persistentvolumes", "pods"}},
+		{Scope: options.ResourceScopeCluster, Wanted: []string{"namespaceobjectcounts", "namespaces", "nodes", "persistentvolumes"}},
+		{Scope: options.ResourceScopeNamespaced, Wanted: []string{"deployments", "pods"}},
+	}
+
+	for _, test := range tests {
+		b := NewBuilder(context.TODO(), options.NewOptions())
+		b.WithEnabledCollectors(enabled)
+		b.WithKubeClient(fake.NewSimpleClientset())
+		b.WithNamespaces(options.DefaultNamespaces)
+		b.WithResourceScope(test.Scope)
+
+		names := []string{}
+		for _, c := range b.Build() {
+			names = append(names, c.Name())
+		}
+		sort.Strings(names)
+		if !reflect.DeepEqual(names, test.Wanted) {
+			t.Errorf("%s: expected collectors %v, got %v", test.Scope, test.Wanted, names)
+		}
+	}
+}
+
+func TestWithErrorMetric(t *testing.T) {
+	forbidden := apierrors.NewForbidden(schema.GroupResource{Resource: "secrets"}, "", errors.New("missing RBAC rule"))
+	tests := []struct {
+		Resource     string
+		Err          error
+		WantedReason string
+	}{
+		{Resource: "test_forbidden", Err: forbidden, WantedReason: "forbidden"},
+		{Resource: "test_error", Err: errors.New("field label not supported"), WantedReason: "error"},
+	}
+
+	for _, test := range tests {
+		lw := withErrorMetric(cache.ListWatch{
+			ListFunc: func(opts metav1.ListOptions) (runtime.Object, error) {
+				return nil, test.Err
+			},
+			WatchFunc: func(opts metav1.ListOptions) (watch.Interface, error) {
+				return nil, test.Err
+			},
+		}, test.Resource)
+
+		if _, err := lw.List(metav1.ListOptions{}); err != test.Err {
+			t.Fatalf("expected list error to be passed through, got %v", err)
+		}
+		if _, err := lw.Watch(metav1.ListOptions{}); err != test.Err {
+			t.Fatalf("expected watch error to be passed through, got %v", err)
+		}
+
+		m := &dto.Metric{}
+		if err := ScrapeErrorTotalMetric.WithLabelValues(test.Resource, test.WantedReason).(prometheus.Metric).Write(m); err != nil {
+			t.Fatal(err)
+		}
+		if got := m.GetCounter().GetValue(); got != 2 {
+			t.Errorf("%s: expected 2 scrape errors with reason %s, got %v", test.Resource, test.WantedReason, got)
+		}
+	}
+
+	if _, logged := forbiddenResources.Load("test_forbidden"); !logged {
+		t.Error("expected forbidden resource to be logged")
+	}
+	if _, logged := forbiddenResources.Load("test_error"); logged {
+		t.Error("expected resource with other errors not to be logged as forbidden")
+	}
+}
+
+func TestWithNamespacesDenylist(t *testing.T) {
+	b := NewBuilder(context.TODO(), options.NewOptions())
+	b.WithNamespacesDenylist(options.NamespaceList{"kube-system"})
+	store := b.newMetricsStore("configmaps", generateConfigMapMetrics)
+
+	for _, ns := range []string{"default", "kube-system"} {
+		store.Add(&v1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Namespace: ns, Name: "configmap-" + ns}})
+	}
+
+	for _, m := range store.GetAll() {
+		if strings.Contains(string(*m), `namespace="kube-system"`) {
+			t.Errorf("expected no metrics of denied namespace, got %s", *m)
+		}
+	}
+	if len(store.GetAll()) == 0 {
+		t.Error("expected metrics of allowed namespace, got none")
+	}
+}
+
+func TestWithNamespacesDenylistNamespaces(t *testing.T) {
+	b := NewBuilder(context.TODO(), options.NewOptions())
+	b.WithNamespacesDenylist(options.NamespaceList{"kube-system"})
+	store := b.newMetricsStore("namespaces", func(obj interface{}) []*metrics.Metric {
+		return generateNamespaceMetrics(false, nil, obj)
+	})
+
+	for _, ns := range []string{"default", "kube-system"} {
+		store.Add(&v1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: ns}})
+	}
+
+	if keys, want := store.ListKeys(), []string{"default"}; !reflect.DeepEqual(keys, want) {
+		t.Errorf("expected only the allowed namespace to be stored, got %v", keys)
+	}
+}
+
+func TestWithMetricLabels(t *testing.T) {
+	b := NewBuilder(context.TODO(), options.NewOptions())
+	b.WithMetricLabels(map[string]string{"region": "us-east-1", "env": "prod"})
+	b.WithMetricWhitelist(mustMatcher(t, options.MetricSet{"kube_pod_info": struct{}{}, "kube_node_info": struct{}{}}))
+
+	pods := b.newMetricsStore("pods", func(obj interface{}) []*metrics.Metric {
+		return generatePodMetrics(false, false, nil, nil, obj)
+	})
+	pods.Add(&v1.Pod{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "pod1", UID: "uid1"}})
+	nodes := b.newMetricsStore("nodes", func(obj interface{}) []*metrics.Metric {
+		return generateNodeMetrics(false, nil, obj)
+	})
+	nodes.Add(&v1.Node{ObjectMeta: metav1.ObjectMeta{Name: "node1", UID: "uid2"}})
+
+	for _, store := range []*metricsstore.MetricsStore{pods, nodes} {
+		ms := store.GetAll()
+		if len(ms) != 1 {
+			t.Fatalf("expected the whitelisted metric only, got %v", ms)
+		}
+		if !strings.Contains(string(*ms[0]), `{env="prod",region="us-east-1",`) {
+			t.Errorf("expected constant labels on metric, got %s", *ms[0])
+		}
+	}
+}
+
+func mustMatcher(t *testing.T, ms options.MetricSet) *options.MetricMatcher {
+	m, err := ms.Matcher(false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return m
+}
+
+func TestBuildMultipleClusters(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	b := NewBuilder(ctx, options.NewOptions())
+	b.WithEnabledCollectors(options.CollectorSet{"configmaps": struct{}{}})
+	b.WithNamespaces(options.NamespaceList{metav1.NamespaceAll})
+	for _, name := range []string{"a", "b"} {
+		client := fake.NewSimpleClientset(&v1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "configmap-" + name}})
+		b.AddCluster(name, client, nil)
+	}
+
+	collectors := b.Build()
+	var ms []string
+	for _, c := range collectors {
+		if err := waitFor(c.HasSynced); err != nil {
+			t.Fatal(err)
+		}
+		for _, m := range c.store.GetAll() {
+			ms = append(ms, string(*m))
+		}
+	}
+
+	for _, want := range []string{
+		`kube_configmap_info{cluster="a",configmap="configmap-a",namespace="default"} 1`,
+		`kube_configmap_info{cluster="b",configmap="configmap-b",namespace="default"} 1`,
+	} {
+		found := false
+		for _, m := range ms {
+			if strings.HasPrefix(m, want) {
+				found = true
+			}
+		}
+		if !found {
+			t.Errorf("expected metric %q, got %v", want, ms)
+		}
+	}
+	for _, m := range ms {
+		if !strings.Contains(m, `cluster="`) {
+			t.Errorf("expected cluster label on every metric, got %q", m)
+		}
+	}
+}
+
+func waitFor(cond func() bool) error {
+	for i := 0; i < 100; i++ {
+		if cond() {
+			return nil
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	return errors.New("timed out waiting for condition")
+}
+
+func TestLabelsAllowlist(t *testing.T) {
+	opts := options.NewOptions()
+	opts.LabelsAllowlist = options.LabelsAllowlist{
+		"pods":       {"app", "tier"},
+		"services":   {"app", "missing"},
+		"namespaces": {},
+	}
+	b := NewBuilder(context.TODO(), opts)
+
+	labels := map[string]string{"app": "foo", "tier": "db", "pod-template-hash": "1234"}
+	tests := []struct {
+		Desc string
+		Case generateMetricsTestCase
+	}{
+		{
+			Desc: "allowed pod labels",
+			Case: generateMetricsTestCase{
+				Obj: &v1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "pod1", Namespace: "ns1", Labels: labels}},
+				Func: func(obj interface{}) []*metrics.Metric {
+					return generatePodMetrics(false, false, nil, b.allowedLabels("pods"), obj)
+				},
+				MetricNames: []string{"kube_pod_labels"},
+				Want: `
+					kube_pod_labels{label_app="foo",label_tier="db",namespace="ns1",pod="pod1"} 1
+`,
+			},
+		},
+		{
+			Desc: "allowed service labels",
+			Case: generateMetricsTestCase{
+				Obj: &v1.Service{ObjectMeta: metav1.ObjectMeta{Name: "svc1", Namespace: "ns1", Labels: labels}},
+				Func: func(obj interface{}) []*metrics.Metric {
+					return generateServiceMetrics(b.allowedLabels("services"), obj)
+				},
+				MetricNames: []string{"kube_service_labels"},
+				Want: `
+					kube_service_labels{label_app="foo",namespace="ns1",service="svc1"} 1
+`,
+			},
+		},
+		{
+			Desc: "collector listed without labels",
+			Case: generateMetricsTestCase{
+				Obj: &v1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "ns1", Labels: labels}},
+				Func: func(obj interface{}) []*metrics.Metric {
+					return generateNamespaceMetrics(false, b.allowedLabels("namespaces"), obj)
+				},
+				MetricNames: []string{"kube_namespace_labels"},
+				Want:        "",
+			},
+		},
+		{
+			Desc: "collector not listed",
+			Case: generateMetricsTestCase{
+				Obj: &v1.Node{ObjectMeta: metav1.ObjectMeta{Name: "node1", Labels: labels}},
+				Func: func(obj interface{}) []*metrics.Metric {
+					return generateNodeMetrics(false, b.allowedLabels("nodes"), obj)
+				},
+				MetricNames: []string{"kube_node_labels"},
+				Want:        "",
+			},
+		},
+	}
+
+	for _, test := range tests {
+		if err := test.Case.run(); err != nil {
+			t.Errorf("unexpected collecting result for %s:\n%s", test.Desc, err)
+		}
+	}
+
+	// Without an allowlist, all labels are exposed.
+	b = NewBuilder(context.TODO(), options.NewOptions())
+	c := generateMetricsTestCase{
+		Obj: &v1.Node{ObjectMeta: metav1.ObjectMeta{Name: "node1", Labels: labels}},
+		Func: func(obj interface{}) []*metrics.Metric {
+			return generateNodeMetrics(false, b.allowedLabels("nodes"), obj)
+		},
+		MetricNames: []string{"kube_node_labels"},
+		Want: `
+			kube_node_labels{label_app="foo",label_pod_template_hash="1234",label_tier="db",node="node1"} 1
+`,
+	}
+	if err := c.run(); err != nil {
+		t.Errorf("unexpected collecting result without allowlist:\n%s", err)
+	}
+}
+
+func TestReflectorErrorLogger(t *testing.T) {
+	now := time.Unix(0, 0)
+	logged := []string{}
+	l := newReflectorErrorLogger(
+		func() time.Time { return now },
+		func(format string, args ...interface{}) { logged = append(logged, fmt.Sprintf(format, args...)) },
+	)
+
+	// The reflectors retry every second while the API server is unreachable.
+	unreachable := errors.New("Failed to list *v1.Pod: connection refused")
+	for i := 0; i < 60; i++ {
+		l.handle(unreachable)
+		now = now.Add(time.Second)
+	}
+
+	if len(logged) == 0 || logged[0] != unreachable.Error() {
+		t.Fatalf("expected the first error to be logged right away, got %q", logged)
+	}
+	if len(logged) > 8 {
+		t.Errorf("expected repeated errors to be logged with backoff, got %d log lines: %q", len(logged), logged)
+	}
+	repetitions := 1 + l.errors[unreachable.Error()].suppressed
+	for _, line := range logged[1:] {
+		var n int
+		if _, err := fmt.Sscanf(strings.TrimPrefix(line, unreachable.Error()), " (repeated %d times since last logged)", &n); err != nil {
+			t.Fatalf("unexpected log line %q: %v", line, err)
+		}
+		repetitions += n
+	}
+	if repetitions != 60 {
+		t.Errorf("expected 60 errors to be accounted for, got %d", repetitions)
+	}
+
+	logged = nil
+	other := errors.New("Failed to watch *v1.Node: connection refused")
+	l.handle(other)
+	if len(logged) != 1 || logged[0] != other.Error() {
+		t.Errorf("expected a different error to be logged right away, got %q", logged)
+	}
+
+	// Once the API server is back, the suppressed repetitions are summarized
+	// and the error is forgotten.
+	logged = nil
+	suppressed := l.errors[unreachable.Error()].suppressed
+	now = now.Add(2 * maxReflectorErrorBackoff)
+	l.handle(unreachable)
+	wanted := []string{unreachable.Error()}
+	if suppressed > 0 {
+		wanted = []string{fmt.Sprintf("%s (repeated %d more times)", unreachable.Error(), suppressed), unreachable.Error()}
+	}
+	if !reflect.DeepEqual(logged, wanted) {
+		t.Errorf("expected %q to be logged, got %q", wanted, logged)
+	}
+}
+
+func TestBuildWithCreatedMetricsDisabled(t *testing.T) {
+	created := metav1.Unix(1500000000, 0)
+	objects := []runtime.Object{
+		&v1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "configmap1", CreationTimestamp: created}},
+		&v1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "default", CreationTimestamp: created}},
+		&v1.Node{ObjectMeta: metav1.ObjectMeta{Name: "node1", CreationTimestamp: created}},
+		&v1.Pod{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "pod1", CreationTimestamp: created}},
+		&v1.Service{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "service1", CreationTimestamp: created}},
+	}
+
+	for _, disabled := range []bool{false, true} {
+		ctx, cancel := context.WithCancel(context.Background())
+		b := NewBuilder(ctx, options.NewOptions())
+		b.WithEnabledCollectors(options.CollectorSet{
+			"configmaps": struct{}{},
+			"namespaces": struct{}{},
+			"nodes":      struct{}{},
+			"pods":       struct{}{},
+			"services":   struct{}{},
+		})
+		b.WithKubeClient(fake.NewSimpleClientset(objects...))
+		b.WithNamespaces(options.DefaultNamespaces)
+		b.WithCreatedMetricsDisabled(disabled)
+
+		createdFamilies := map[string]bool{}
+		for _, c := range b.Build() {
+			if err := waitFor(c.HasSynced); err != nil {
+				t.Fatal(err)
+			}
+			for _, m := range c.Collect() {
+				if strings.HasSuffix(m.Name(), "_created") {
+					createdFamilies[m.Name()] = true
+				}
+			}
+		}
+		cancel()
+
+		if disabled && len(createdFamilies) != 0 {
+			t.Errorf("expected no *_created metrics with created metrics disabled, got %v", createdFamilies)
+		}
+		if !disabled && len(createdFamilies) != 5 {
+			t.Errorf("expected the *_created metrics of all 5 collectors, got %v", createdFamilies)
+		}
+	}
+}
+
+func TestAvailableCollectors(t *testing.T) {
+	for c := range availableCollectors {
+		_, isDefault := options.DefaultCollectors[c]
+		_, isOptIn := options.OptInCollectors[c]
+		if isDefault == isOptIn {
+			t.Errorf("expected collector %s to be either a default or an opt-in collector", c)
+		}
+	}
+	for _, set := range []options.CollectorSet{options.DefaultCollectors, options.OptInCollectors} {
+		for c := range set {
+			if _, ok := availableCollectors[c]; !ok {
+				t.Errorf("expected collector %s to be available", c)
+			}
+		}
+	}
+}
@@ -0,0 +1,90 @@
+/*
+Copyright 2018 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package collectors
+
+import (
+	"k8s.io/kube-state-metrics/pkg/metrics"
+
+	rbac "k8s.io/api/rbac/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/watch"
+	clientset "k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/cache"
+)
+
+var (
+	descClusterRoleLabelsDefaultLabels = []string{"clusterrole"}
+
+	descClusterRoleInfo = newMetricFamilyDef(
+		"kube_clusterrole_info",
+		"Information about cluster role.",
+		descClusterRoleLabelsDefaultLabels,
+		nil,
+	)
+	descClusterRoleCreated = newMetricFamilyDef(
+		"kube_clusterrole_created",
+		"Unix creation timestamp",
+		descClusterRoleLabelsDefaultLabels,
+		nil,
+	)
+	descClusterRoleRules = newMetricFamilyDef(
+		"kube_clusterrole_rules",
+		"Number of policy rules of the cluster role.",
+		descClusterRoleLabelsDefaultLabels,
+		nil,
+	)
+)
+
+func createClusterRoleListWatch(kubeClient clientset.Interface, ns string) cache.ListWatch {
+	return cache.ListWatch{
+		ListFunc: func(opts metav1.ListOptions) (runtime.Object, error) {
+			return kubeClient.RbacV1().ClusterRoles().List(opts)
+		},
+		WatchFunc: func(opts metav1.ListOptions) (watch.Interface, error) {
+			return kubeClient.RbacV1().ClusterRoles().Watch(opts)
+		},
+	}
+}
+
+func generateClusterRoleMetrics(obj interface{}) []*metrics.Metric {
+	ms := []*metrics.Metric{}
+
+	// TODO: Refactor
+	rPointer := obj.(*rbac.ClusterRole)
+	r := *rPointer
+
+	addGauge := func(desc *metricFamilyDef, v float64, lv ...string) {
+		lv = append([]string{r.Name}, lv...)
+
+		m, err := metrics.NewMetric(desc.Name, desc.LabelKeys, lv, v)
+		if err != nil {
+			panic(err)
+		}
+
+		ms = append(ms, m)
+	}
+
+	addGauge(descClusterRoleInfo, 1)
+	addGauge(descClusterRoleRules, float64(len(r.Rules)))
+
+	if !r.CreationTimestamp.IsZero() {
+		addGauge(descClusterRoleCreated, float64(r.CreationTimestamp.Unix()))
+	}
+
+	return ms
+}
@@ -0,0 +1,82 @@
+/*
+Copyright 2018 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package collectors
+
+import (
+	"testing"
+	"time"
+
+	rbac "k8s.io/api/rbac/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestClusterRoleCollector(t *testing.T) {
+	// Fixed metadata on type and help text. We prepend this to every expected
+	// output so we only have to modify a single place when doing adjustments.
+	const metadata = `
+		# HELP kube_clusterrole_info Information about cluster role.
+		# TYPE kube_clusterrole_info gauge
+		# HELP kube_clusterrole_created Unix creation timestamp
+		# TYPE kube_clusterrole_created gauge
+		# HELP kube_clusterrole_rules Number of policy rules of the cluster role.
+		# TYPE kube_clusterrole_rules gauge
+	`
+	cases := []generateMetricsTestCase{
+		{
+			Obj: &rbac.ClusterRole{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:              "view",
+					CreationTimestamp: metav1.Time{Time: time.Unix(1500000000, 0)},
+				},
+				Rules: []rbac.PolicyRule{
+					{
+						APIGroups: []string{""},
+						Resources: []string{"pods", "services"},
+						Verbs:     []string{"get", "list", "watch"},
+					},
+					{
+						APIGroups: []string{"apps"},
+						Resources: []string{"deployments"},
+						Verbs:     []string{"get", "list", "watch"},
+					},
+				},
+			},
+			Want: `
+				kube_clusterrole_created{clusterrole="view"} 1.5e+09
+				kube_clusterrole_info{clusterrole="view"} 1
+				kube_clusterrole_rules{clusterrole="view"} 2
+`,
+		},
+		{
+			Obj: &rbac.ClusterRole{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: "aggregated",
+				},
+			},
+			Want: `
+				kube_clusterrole_info{clusterrole="aggregated"} 1
+				kube_clusterrole_rules{clusterrole="aggregated"} 0
+`,
+		},
+	}
+	for i, c := range cases {
+		c.Func = generateClusterRoleMetrics
+		if err := c.run(); err != nil {
+			t.Errorf("unexpected collecting result in %vth run:\n%s", i, err)
+		}
+	}
+}
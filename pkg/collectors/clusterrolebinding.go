@@ -0,0 +1,99 @@
+/*
+Copyright 2018 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package collectors
+
+import (
+	"k8s.io/kube-state-metrics/pkg/metrics"
+
+	rbac "k8s.io/api/rbac/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/watch"
+	clientset "k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/cache"
+)
+
+var (
+	descClusterRoleBindingLabelsDefaultLabels = []string{"clusterrolebinding"}
+
+	descClusterRoleBindingInfo = newMetricFamilyDef(
+		"kube_clusterrolebinding_info",
+		"Information about cluster role binding.",
+		append(descClusterRoleBindingLabelsDefaultLabels, "role_ref"),
+		nil,
+	)
+	descClusterRoleBindingCreated = newMetricFamilyDef(
+		"kube_clusterrolebinding_created",
+		"Unix creation timestamp",
+		descClusterRoleBindingLabelsDefaultLabels,
+		nil,
+	)
+	descClusterRoleBindingSubjects = newMetricFamilyDef(
+		"kube_clusterrolebinding_subjects",
+		"Number of subjects of the cluster role binding by kind.",
+		append(descClusterRoleBindingLabelsDefaultLabels, "subject_kind"),
+		nil,
+	)
+)
+
+func createClusterRoleBindingListWatch(kubeClient clientset.Interface, ns string) cache.ListWatch {
+	return cache.ListWatch{
+		ListFunc: func(opts metav1.ListOptions) (runtime.Object, error) {
+			return kubeClient.RbacV1().ClusterRoleBindings().List(opts)
+		},
+		WatchFunc: func(opts metav1.ListOptions) (watch.Interface, error) {
+			return kubeClient.RbacV1().ClusterRoleBindings().Watch(opts)
+		},
+	}
+}
+
+func generateClusterRoleBindingMetrics(obj interface{}) []*metrics.Metric {
+	ms := []*metrics.Metric{}
+
+	// TODO: Refactor
+	bPointer := obj.(*rbac.ClusterRoleBinding)
+	b := *bPointer
+
+	addGauge := func(desc *metricFamilyDef, v float64, lv ...string) {
+		lv = append([]string{b.Name}, lv...)
+
+		m, err := metrics.NewMetric(desc.Name, desc.LabelKeys, lv, v)
+		if err != nil {
+			panic(err)
+		}
+
+		ms = append(ms, m)
+	}
+
+	addGauge(descClusterRoleBindingInfo, 1, b.RoleRef.Name)
+
+	if !b.CreationTimestamp.IsZero() {
+		addGauge(descClusterRoleBindingCreated, float64(b.CreationTimestamp.Unix()))
+	}
+
+	for _, kind := range []string{rbac.GroupKind, rbac.ServiceAccountKind, rbac.UserKind} {
+		n := 0
+		for _, s := range b.Subjects {
+			if s.Kind == kind {
+				n++
+			}
+		}
+		addGauge(descClusterRoleBindingSubjects, float64(n), kind)
+	}
+
+	return ms
+}
@@ -0,0 +1,92 @@
+/*
+Copyright 2018 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package collectors
+
+import (
+	"testing"
+	"time"
+
+	rbac "k8s.io/api/rbac/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestClusterRoleBindingCollector(t *testing.T) {
+	// Fixed metadata on type and help text. We prepend this to every expected
+	// output so we only have to modify a single place when doing adjustments.
+	const metadata = `
+		# HELP kube_clusterrolebinding_info Information about cluster role binding.
+		# TYPE kube_clusterrolebinding_info gauge
+		# HELP kube_clusterrolebinding_created Unix creation timestamp
+		# TYPE kube_clusterrolebinding_created gauge
+		# HELP kube_clusterrolebinding_subjects Number of subjects of the cluster role binding by kind.
+		# TYPE kube_clusterrolebinding_subjects gauge
+	`
+	cases := []generateMetricsTestCase{
+		{
+			Obj: &rbac.ClusterRoleBinding{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:              "admins",
+					CreationTimestamp: metav1.Time{Time: time.Unix(1500000000, 0)},
+				},
+				RoleRef: rbac.RoleRef{
+					APIGroup: rbac.GroupName,
+					Kind:     "ClusterRole",
+					Name:     "cluster-admin",
+				},
+				Subjects: []rbac.Subject{
+					{Kind: rbac.UserKind, APIGroup: rbac.GroupName, Name: "alice"},
+					{Kind: rbac.UserKind, APIGroup: rbac.GroupName, Name: "bob"},
+					{Kind: rbac.GroupKind, APIGroup: rbac.GroupName, Name: "system:masters"},
+					{Kind: rbac.ServiceAccountKind, Name: "deployer", Namespace: "ci"},
+					{Kind: rbac.ServiceAccountKind, Name: "operator", Namespace: "kube-system"},
+					{Kind: rbac.ServiceAccountKind, Name: "default", Namespace: "default"},
+				},
+			},
+			Want: `
+				kube_clusterrolebinding_created{clusterrolebinding="admins"} 1.5e+09
+				kube_clusterrolebinding_info{clusterrolebinding="admins",role_ref="cluster-admin"} 1
+				kube_clusterrolebinding_subjects{clusterrolebinding="admins",subject_kind="Group"} 1
+				kube_clusterrolebinding_subjects{clusterrolebinding="admins",subject_kind="ServiceAccount"} 3
+				kube_clusterrolebinding_subjects{clusterrolebinding="admins",subject_kind="User"} 2
+`,
+		},
+		{
+			Obj: &rbac.ClusterRoleBinding{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: "empty",
+				},
+				RoleRef: rbac.RoleRef{
+					APIGroup: rbac.GroupName,
+					Kind:     "ClusterRole",
+					Name:     "view",
+				},
+			},
+			Want: `
+				kube_clusterrolebinding_info{clusterrolebinding="empty",role_ref="view"} 1
+				kube_clusterrolebinding_subjects{clusterrolebinding="empty",subject_kind="Group"} 0
+				kube_clusterrolebinding_subjects{clusterrolebinding="empty",subject_kind="ServiceAccount"} 0
+				kube_clusterrolebinding_subjects{clusterrolebinding="empty",subject_kind="User"} 0
+`,
+		},
+	}
+	for i, c := range cases {
+		c.Func = generateClusterRoleBindingMetrics
+		if err := c.run(); err != nil {
+			t.Errorf("unexpected collecting result in %vth run:\n%s", i, err)
+		}
+	}
+}
@@ -17,24 +17,33 @@ limitations under the License.
 package collectors
 
 import (
+	"sort"
+	"sync"
 	"time"
 
 	"regexp"
 
 	"github.com/prometheus/client_golang/prometheus"
 	"k8s.io/api/core/v1"
+	"k8s.io/kube-state-metrics/pkg/log"
 	"k8s.io/kube-state-metrics/pkg/metrics"
 )
 
 var (
-	resyncPeriod = 5 * time.Minute
-
 	ScrapeErrorTotalMetric = prometheus.NewCounterVec(
 		prometheus.CounterOpts{
 			Name: "ksm_scrape_error_total",
 			Help: "Total scrape errors encountered when scraping a resource",
 		},
-		[]string{"resource"},
+		[]string{"resource", "reason"},
+	)
+
+	PermissionDeniedTotalMetric = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "ksm_permission_denied_total",
+			Help: "Total list and watch permissions on the resources of enabled collectors found denied on startup",
+		},
+		[]string{"resource", "verb"},
 	)
 
 	ResourcesPerScrapeMetric = prometheus.NewSummaryVec(
@@ -45,6 +54,59 @@ var (
 		[]string{"resource"},
 	)
 
+	CollectDurationSecondsMetric = prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Name: "ksm_collect_duration_seconds",
+			Help: "Duration of collecting the metrics of a collector",
+		},
+		[]string{"collector"},
+	)
+
+	CollectorPanicTotalMetric = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "ksm_collector_panic_total",
+			Help: "Total panics recovered from while generating or collecting the metrics of a collector",
+		},
+		[]string{"collector"},
+	)
+
+	ScrapeTimeoutTotalMetric = prometheus.NewCounter(
+		prometheus.CounterOpts{
+			Name: "ksm_scrape_timeout_total",
+			Help: "Total scrapes of the metrics endpoint which exceeded the scrape timeout",
+		},
+	)
+
+	InFlightScrapesMetric = prometheus.NewGauge(
+		prometheus.GaugeOpts{
+			Name: "ksm_in_flight_scrapes",
+			Help: "Number of scrapes of the metrics endpoint currently being served",
+		},
+	)
+
+	SeriesMetric = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "kube_state_metrics_series",
+			Help: "Number of series exposed by a collector in the last scrape",
+		},
+		[]string{"collector"},
+	)
+
+	TotalSeriesMetric = prometheus.NewGauge(
+		prometheus.GaugeOpts{
+			Name: "kube_state_metrics_total_series",
+			Help: "Number of series exposed by all collectors in the last scrape",
+		},
+	)
+
+	WatchCacheObjectsMetric = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "kube_state_metrics_watch_cache_objects",
+			Help: "Number of objects held in the stores of the collectors of a resource",
+		},
+		[]string{"resource"},
+	)
+
 	invalidLabelCharRE = regexp.MustCompile(`[^a-zA-Z0-9_]`)
 )
 
@@ -52,21 +114,120 @@ type store interface {
 	GetAll() []*metrics.Metric
 }
 
+// keyLister is implemented by stores holding watched objects, e.g.
+// metricsstore.MetricsStore.
+type keyLister interface {
+	ListKeys() []string
+}
+
+// generatedStore is a store generating its metrics on every collection, e.g.
+// from the state of other stores, instead of from watched objects.
+type generatedStore func() []*metrics.Metric
+
+func (s generatedStore) GetAll() []*metrics.Metric {
+	return s()
+}
+
 // Collector represents a kube-state-metrics metric collector. It is stripped
 // down version of the Prometheus client_golang collector.
 type Collector struct {
+	name  string
 	store store
+	// hasSynced reports whether the reflectors feeding the store have
+	// completed their initial list. Nil if there is nothing to wait for.
+	hasSynced func() bool
+}
+
+func newCollector(s store, hasSynced func() bool) *Collector {
+	return &Collector{store: s, hasSynced: hasSynced}
+}
+
+// HasSynced returns true once the store of the collector holds the metrics of
+// all objects initially listed from the API server.
+func (c *Collector) HasSynced() bool {
+	return c.hasSynced == nil || c.hasSynced()
 }
 
-func newCollector(s store) *Collector {
-	return &Collector{s}
+// Name returns the name of the collector, e.g. pods.
+func (c *Collector) Name() string {
+	return c.name
 }
 
-// Collect returns all metrics of the underlying store of the collector.
-func (c *Collector) Collect() []*metrics.Metric {
+// objects returns the number of objects held by the store of the collector.
+// ok is false if the store does not hold watched objects.
+func (c *Collector) objects() (n int, ok bool) {
+	s, ok := c.store.(keyLister)
+	if !ok {
+		return 0, false
+	}
+	return len(s.ListKeys()), true
+}
+
+// MetricNames returns the sorted names of the metrics currently held by the
+// store of the collector. As metrics are only generated for existing objects,
+// metrics of collectors without objects are missing.
+func (c *Collector) MetricNames() []string {
+	seen := map[string]bool{}
+	names := []string{}
+	for _, m := range c.store.GetAll() {
+		name := m.Name()
+		if !seen[name] {
+			seen[name] = true
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Collect returns all metrics of the underlying store of the collector. A
+// panic while collecting is logged and counted, and no metrics are returned,
+// so that the other collectors are still scraped. Panics generating the
+// metrics of watched objects are recovered from when the objects are stored,
+// see withPanicRecovery.
+func (c *Collector) Collect() (ms []*metrics.Metric) {
+	start := time.Now()
+	defer func() {
+		CollectDurationSecondsMetric.WithLabelValues(c.name).Observe(time.Since(start).Seconds())
+	}()
+	defer func() {
+		if r := recover(); r != nil {
+			log.Errorf("Recovered from panic collecting the metrics of %s: %v", c.name, r)
+			CollectorPanicTotalMetric.WithLabelValues(c.name).Inc()
+			ms = nil
+		}
+	}()
+
 	return c.store.GetAll()
 }
 
+var (
+	seriesMtx sync.Mutex
+	// seriesCounts holds the number of series per collector name of the
+	// last scrape of each metrics path.
+	seriesCounts = map[string]map[string]int{}
+)
+
+// UpdateSeriesMetrics sets the series metrics to the given number of series per
+// collector name of a completed scrape of the given metrics path. Collectors
+// missing from counts, e.g. removed custom resource collectors, are dropped
+// from the metrics.
+func UpdateSeriesMetrics(path string, counts map[string]int) {
+	seriesMtx.Lock()
+	defer seriesMtx.Unlock()
+
+	seriesCounts[path] = counts
+	total := 0
+	SeriesMetric.Reset()
+	for _, counts := range seriesCounts {
+		for name, n := range counts {
+			SeriesMetric.WithLabelValues(name).Add(float64(n))
+			total += n
+		}
+	}
+	TotalSeriesMetric.Set(float64(total))
+}
+
 func newMetricFamilyDef(name, help string, labelKeys []string, constLabels prometheus.Labels) *metricFamilyDef {
 	return &metricFamilyDef{name, help, labelKeys, constLabels}
 }
@@ -110,16 +271,27 @@ func addConditionMetrics(desc *metricFamilyDef, cs v1.ConditionStatus, lv ...str
 	return ms
 }
 
-func kubeLabelsToPrometheusLabels(labels map[string]string) ([]string, []string) {
-	labelKeys := make([]string, len(labels))
-	labelValues := make([]string, len(labels))
-	i := 0
-	for k, v := range labels {
-		labelKeys[i] = "label_" + sanitizeLabelName(k)
-		labelValues[i] = v
-		i++
+// kubeLabelsToPrometheusLabels converts the given labels to Prometheus labels.
+// If allowed is nil, all labels are converted. Otherwise only the allowed
+// keys are converted, and ok is false if there are no allowed keys at all,
+// meaning that the labels metric is not to be generated.
+func kubeLabelsToPrometheusLabels(labels map[string]string, allowed []string) (labelKeys []string, labelValues []string, ok bool) {
+	if allowed == nil {
+		labelKeys = make([]string, len(labels))
+		labelValues = make([]string, len(labels))
+		i := 0
+		for k, v := range labels {
+			labelKeys[i] = "label_" + sanitizeLabelName(k)
+			labelValues[i] = v
+			i++
+		}
+		return labelKeys, labelValues, true
+	}
+	if len(allowed) == 0 {
+		return nil, nil, false
 	}
-	return labelKeys, labelValues
+	labelKeys, labelValues = allowedToPrometheusLabels("label_", labels, allowed)
+	return labelKeys, labelValues, true
 }
 
 func kubeAnnotationsToPrometheusAnnotations(annotations map[string]string) ([]string, []string) {
@@ -134,6 +306,36 @@ func kubeAnnotationsToPrometheusAnnotations(annotations map[string]string) ([]st
 	return annotationKeys, annotationValues
 }
 
+// allowedAnnotationsToPrometheusAnnotations converts the allowed keys of the
+// given annotations to Prometheus labels, in the order of allowed. Allowed
+// keys the object is not annotated with are skipped.
+func allowedAnnotationsToPrometheusAnnotations(annotations map[string]string, allowed []string) ([]string, []string) {
+	return allowedToPrometheusLabels("annotation_", annotations, allowed)
+}
+
+// allowedToPrometheusLabels converts the allowed keys of m to Prometheus
+// labels with the given prefix, in the order of allowed.
+func allowedToPrometheusLabels(prefix string, m map[string]string, allowed []string) ([]string, []string) {
+	keys := []string{}
+	values := []string{}
+	seen := map[string]struct{}{}
+	for _, k := range allowed {
+		v, ok := m[k]
+		if !ok {
+			continue
+		}
+		// Different keys like a.b and a_b are sanitized to the same label.
+		key := prefix + sanitizeLabelName(k)
+		if _, ok := seen[key]; ok {
+			continue
+		}
+		seen[key] = struct{}{}
+		keys = append(keys, key)
+		values = append(values, v)
+	}
+	return keys, values
+}
+
 func sanitizeLabelName(s string) string {
 	return invalidLabelCharRE.ReplaceAllString(s, "_")
 }
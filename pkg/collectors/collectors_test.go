@@ -0,0 +1,110 @@
+/*
+Copyright 2018 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package collectors
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+	"golang.org/x/net/context"
+	"k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"k8s.io/kube-state-metrics/pkg/metrics"
+	"k8s.io/kube-state-metrics/pkg/options"
+)
+
+type fakeStore struct {
+	metrics []*metrics.Metric
+}
+
+func (s *fakeStore) GetAll() []*metrics.Metric {
+	return s.metrics
+}
+
+func TestCollectorCollectPanic(t *testing.T) {
+	panicking := newCollector(generatedStore(func() []*metrics.Metric { panic("unexpected object") }), nil)
+	panicking.name = "test_collect_panic"
+	m, err := metrics.NewMetric("kube_test_info", []string{}, []string{}, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	healthy := newCollector(&fakeStore{metrics: []*metrics.Metric{m}}, nil)
+	healthy.name = "test_collect_healthy"
+
+	ms := []*metrics.Metric{}
+	for _, c := range []*Collector{panicking, healthy} {
+		ms = append(ms, c.Collect()...)
+	}
+	if len(ms) != 1 || ms[0] != m {
+		t.Fatalf("expected the metrics of the healthy collector only, got %v", ms)
+	}
+
+	metric := &dto.Metric{}
+	if err := CollectorPanicTotalMetric.WithLabelValues(panicking.name).(prometheus.Metric).Write(metric); err != nil {
+		t.Fatal(err)
+	}
+	if got := metric.GetCounter().GetValue(); got != 1 {
+		t.Fatalf("expected 1 recovered panic, got %v", got)
+	}
+}
+
+func TestMetricsStoreGeneratePanic(t *testing.T) {
+	const collector = "test_generate_panic"
+	b := NewBuilder(context.TODO(), options.NewOptions())
+	store := b.newMetricsStore(collector, func(obj interface{}) []*metrics.Metric {
+		if obj.(*v1.ConfigMap).Name == "unexpected" {
+			panic("unexpected object")
+		}
+		return generateConfigMapMetrics(obj)
+	})
+
+	for _, name := range []string{"unexpected", "healthy"} {
+		if err := store.Add(&v1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: name}}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if keys, want := store.ListKeys(), []string{"default/healthy"}; !reflect.DeepEqual(keys, want) {
+		t.Fatalf("expected only the healthy object to be stored, got %v", keys)
+	}
+
+	metric := &dto.Metric{}
+	if err := CollectorPanicTotalMetric.WithLabelValues(collector).(prometheus.Metric).Write(metric); err != nil {
+		t.Fatal(err)
+	}
+	if got := metric.GetCounter().GetValue(); got != 1 {
+		t.Fatalf("expected 1 recovered panic, got %v", got)
+	}
+}
+
+func TestCollectorCollectDuration(t *testing.T) {
+	c := newCollector(&fakeStore{}, nil)
+	c.name = "test_collect_duration"
+
+	c.Collect()
+	c.Collect()
+
+	m := &dto.Metric{}
+	if err := CollectDurationSecondsMetric.WithLabelValues(c.name).(prometheus.Metric).Write(m); err != nil {
+		t.Fatal(err)
+	}
+	if got := m.GetHistogram().GetSampleCount(); got != 2 {
+		t.Fatalf("expected 2 observed collect durations, got %d", got)
+	}
+}
@@ -50,6 +50,13 @@ var (
 		append(descConfigMapLabelsDefaultLabels, "resource_version"),
 		nil,
 	)
+
+	descConfigMapDataKeys = newMetricFamilyDef(
+		"kube_configmap_data_keys",
+		"Number of keys in the data and binary data of the configmap.",
+		descConfigMapLabelsDefaultLabels,
+		nil,
+	)
 )
 
 func createConfigMapListWatch(kubeClient clientset.Interface, ns string) cache.ListWatch {
@@ -91,5 +98,8 @@ func generateConfigMapMetrics(obj interface{}) []*metrics.Metric {
 
 	addGauge(descConfigMapMetadataResourceVersion, 1, string(m.ObjectMeta.ResourceVersion))
 
+	// Only count the keys, the data may hold sensitive values.
+	addGauge(descConfigMapDataKeys, float64(len(m.Data)+len(m.BinaryData)))
+
 	return ms
 }
@@ -17,6 +17,7 @@ limitations under the License.
 package collectors
 
 import (
+	"strings"
 	"testing"
 
 	"k8s.io/api/core/v1"
@@ -37,6 +38,8 @@ func TestConfigMapCollector(t *testing.T) {
 		# TYPE kube_configmap_created gauge
 		# HELP kube_configmap_metadata_resource_version Resource version representing a specific version of the configmap.
 		# TYPE kube_configmap_metadata_resource_version gauge
+		# HELP kube_configmap_data_keys Number of keys in the data and binary data of the configmap.
+		# TYPE kube_configmap_data_keys gauge
 	`
 	cases := []generateMetricsTestCase{
 		{
@@ -69,6 +72,27 @@ func TestConfigMapCollector(t *testing.T) {
 				`,
 			MetricNames: []string{"kube_configmap_info", "kube_configmap_created", "kube_configmap_metadata_resource_version"},
 		},
+		{
+			Obj: &v1.ConfigMap{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:            "configmap3",
+					Namespace:       "ns3",
+					ResourceVersion: "1",
+				},
+				Data: map[string]string{
+					"app.properties": "password=hunter2",
+					"log-level":      "debug",
+				},
+				BinaryData: map[string][]byte{
+					"keystore": []byte("binary-secret"),
+				},
+			},
+			Want: `
+				kube_configmap_data_keys{configmap="configmap3",namespace="ns3"} 3
+				kube_configmap_info{configmap="configmap3",namespace="ns3"} 1
+				kube_configmap_metadata_resource_version{configmap="configmap3",namespace="ns3",resource_version="1"} 1
+				`,
+		},
 	}
 	for i, c := range cases {
 		c.Func = generateConfigMapMetrics
@@ -77,3 +101,26 @@ func TestConfigMapCollector(t *testing.T) {
 		}
 	}
 }
+
+func TestConfigMapCollectorOmitsData(t *testing.T) {
+	cm := &v1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "configmap1",
+			Namespace: "ns1",
+		},
+		Data: map[string]string{
+			"app.properties": "password=hunter2",
+		},
+		BinaryData: map[string][]byte{
+			"keystore": []byte("binary-secret"),
+		},
+	}
+
+	for _, m := range generateConfigMapMetrics(cm) {
+		for _, content := range []string{"app.properties", "hunter2", "keystore", "binary-secret"} {
+			if strings.Contains(string(*m), content) {
+				t.Errorf("expected metric not to contain configmap data %q, got %s", content, *m)
+			}
+		}
+	}
+}
@@ -121,7 +121,7 @@ func cronJobLabelsDesc(labelKeys []string) *metricFamilyDef {
 	)
 }
 
-func generateCronJobMetrics(obj interface{}) []*metrics.Metric {
+func generateCronJobMetrics(allowedLabels []string, obj interface{}) []*metrics.Metric {
 	ms := []*metrics.Metric{}
 
 	// TODO: Refactor
@@ -153,8 +153,9 @@ func generateCronJobMetrics(obj interface{}) []*metrics.Metric {
 
 	addGauge(descCronJobInfo, 1, j.Spec.Schedule, string(j.Spec.ConcurrencyPolicy))
 
-	labelKeys, labelValues := kubeLabelsToPrometheusLabels(j.Labels)
-	addGauge(cronJobLabelsDesc(labelKeys), 1, labelValues...)
+	if labelKeys, labelValues, ok := kubeLabelsToPrometheusLabels(j.Labels, allowedLabels); ok {
+		addGauge(cronJobLabelsDesc(labelKeys), 1, labelValues...)
+	}
 
 	if !j.CreationTimestamp.IsZero() {
 		addGauge(descCronJobCreated, float64(j.CreationTimestamp.Unix()))
@@ -25,6 +25,7 @@ import (
 	batchv1beta1 "k8s.io/api/batch/v1beta1"
 	"k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/kube-state-metrics/pkg/metrics"
 )
 
 var (
@@ -219,7 +220,9 @@ func TestCronJobCollector(t *testing.T) {
 		},
 	}
 	for i, c := range cases {
-		c.Func = generateCronJobMetrics
+		c.Func = func(obj interface{}) []*metrics.Metric {
+			return generateCronJobMetrics(nil, obj)
+		}
 		if err := c.run(); err != nil {
 			t.Errorf("unexpected collecting result in %vth run:\n%s", i, err)
 		}
@@ -80,6 +80,18 @@ var (
 		descDaemonSetLabelsDefaultLabels,
 		nil,
 	)
+	descDaemonSetStatusUpdatedNumberScheduled = newMetricFamilyDef(
+		"kube_daemonset_status_updated_number_scheduled",
+		"The total number of nodes that are running updated daemon pod.",
+		descDaemonSetLabelsDefaultLabels,
+		nil,
+	)
+	descDaemonSetStatusObservedGeneration = newMetricFamilyDef(
+		"kube_daemonset_status_observed_generation",
+		"The most recent generation observed by the daemon set controller.",
+		descDaemonSetLabelsDefaultLabels,
+		nil,
+	)
 	descDaemonSetMetadataGeneration = newMetricFamilyDef(
 		"kube_daemonset_metadata_generation",
 		"Sequence number representing a specific generation of the desired state.",
@@ -129,7 +141,7 @@ func DaemonSetLabelsDesc(labelKeys []string) *metricFamilyDef {
 	)
 }
 
-func generateDaemonSetMetrics(obj interface{}) []*metrics.Metric {
+func generateDaemonSetMetrics(allowedLabels []string, obj interface{}) []*metrics.Metric {
 	ms := []*metrics.Metric{}
 
 	// TODO: Refactor
@@ -156,10 +168,13 @@ func generateDaemonSetMetrics(obj interface{}) []*metrics.Metric {
 	addGauge(descDaemonSetDesiredNumberScheduled, float64(d.Status.DesiredNumberScheduled))
 	addGauge(descDaemonSetNumberReady, float64(d.Status.NumberReady))
 	addGauge(descDaemonSetUpdatedNumberScheduled, float64(d.Status.UpdatedNumberScheduled))
+	addGauge(descDaemonSetStatusUpdatedNumberScheduled, float64(d.Status.UpdatedNumberScheduled))
+	addGauge(descDaemonSetStatusObservedGeneration, float64(d.Status.ObservedGeneration))
 	addGauge(descDaemonSetMetadataGeneration, float64(d.ObjectMeta.Generation))
 
-	labelKeys, labelValues := kubeLabelsToPrometheusLabels(d.ObjectMeta.Labels)
-	addGauge(DaemonSetLabelsDesc(labelKeys), 1, labelValues...)
+	if labelKeys, labelValues, ok := kubeLabelsToPrometheusLabels(d.ObjectMeta.Labels, allowedLabels); ok {
+		addGauge(DaemonSetLabelsDesc(labelKeys), 1, labelValues...)
+	}
 
 	return ms
 }
@@ -22,6 +22,7 @@ import (
 
 	"k8s.io/api/extensions/v1beta1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/kube-state-metrics/pkg/metrics"
 )
 
 func TestDaemonSetCollector(t *testing.T) {
@@ -46,6 +47,10 @@ func TestDaemonSetCollector(t *testing.T) {
 		# TYPE kube_daemonset_status_number_unavailable gauge
 		# HELP kube_daemonset_updated_number_scheduled The total number of nodes that are running updated daemon pod
 		# TYPE kube_daemonset_updated_number_scheduled gauge
+		# HELP kube_daemonset_status_updated_number_scheduled The total number of nodes that are running updated daemon pod.
+		# TYPE kube_daemonset_status_updated_number_scheduled gauge
+		# HELP kube_daemonset_status_observed_generation The most recent generation observed by the daemon set controller.
+		# TYPE kube_daemonset_status_observed_generation gauge
 		# HELP kube_daemonset_labels Kubernetes labels converted to Prometheus labels.
 		# TYPE kube_daemonset_labels gauge
 `
@@ -179,9 +184,56 @@ func TestDaemonSetCollector(t *testing.T) {
 				"kube_daemonset_updated_number_scheduled",
 			},
 		},
+		{
+			// A rollout in progress: two nodes no longer match the node
+			// selector and two of the desired nodes lack an available pod.
+			Obj: &v1beta1.DaemonSet{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:       "ds4",
+					Namespace:  "ns4",
+					Generation: 7,
+				},
+				Status: v1beta1.DaemonSetStatus{
+					CurrentNumberScheduled: 8,
+					NumberMisscheduled:     2,
+					DesiredNumberScheduled: 8,
+					NumberReady:            6,
+					NumberAvailable:        6,
+					NumberUnavailable:      2,
+					UpdatedNumberScheduled: 3,
+					ObservedGeneration:     6,
+				},
+			},
+			Want: `
+				kube_daemonset_metadata_generation{daemonset="ds4",namespace="ns4"} 7
+				kube_daemonset_status_current_number_scheduled{daemonset="ds4",namespace="ns4"} 8
+				kube_daemonset_status_desired_number_scheduled{daemonset="ds4",namespace="ns4"} 8
+				kube_daemonset_status_number_available{daemonset="ds4",namespace="ns4"} 6
+				kube_daemonset_status_number_misscheduled{daemonset="ds4",namespace="ns4"} 2
+				kube_daemonset_status_number_ready{daemonset="ds4",namespace="ns4"} 6
+				kube_daemonset_status_number_unavailable{daemonset="ds4",namespace="ns4"} 2
+				kube_daemonset_status_observed_generation{daemonset="ds4",namespace="ns4"} 6
+				kube_daemonset_status_updated_number_scheduled{daemonset="ds4",namespace="ns4"} 3
+				kube_daemonset_updated_number_scheduled{daemonset="ds4",namespace="ns4"} 3
+`,
+			MetricNames: []string{
+				"kube_daemonset_metadata_generation",
+				"kube_daemonset_status_current_number_scheduled",
+				"kube_daemonset_status_desired_number_scheduled",
+				"kube_daemonset_status_number_available",
+				"kube_daemonset_status_number_misscheduled",
+				"kube_daemonset_status_number_ready",
+				"kube_daemonset_status_number_unavailable",
+				"kube_daemonset_status_observed_generation",
+				"kube_daemonset_status_updated_number_scheduled",
+				"kube_daemonset_updated_number_scheduled",
+			},
+		},
 	}
 	for i, c := range cases {
-		c.Func = generateDaemonSetMetrics
+		c.Func = func(obj interface{}) []*metrics.Metric {
+			return generateDaemonSetMetrics(nil, obj)
+		}
 		if err := c.run(); err != nil {
 			t.Errorf("unexpected collecting result in %vth run:\n%s", i, err)
 		}
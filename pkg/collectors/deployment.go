@@ -33,6 +33,9 @@ var (
 	descDeploymentLabelsHelp          = "Kubernetes labels converted to Prometheus labels."
 	descDeploymentLabelsDefaultLabels = []string{"namespace", "deployment"}
 
+	descDeploymentAnnotationsName = "kube_deployment_annotations"
+	descDeploymentAnnotationsHelp = "Kubernetes annotations converted to Prometheus labels."
+
 	descDeploymentCreated = newMetricFamilyDef(
 		"kube_deployment_created",
 		"Unix creation timestamp",
@@ -86,6 +89,13 @@ var (
 		nil,
 	)
 
+	descDeploymentStrategyType = newMetricFamilyDef(
+		"kube_deployment_spec_strategy_type",
+		"The strategy used to replace old pods by new ones.",
+		append(descDeploymentLabelsDefaultLabels, "type"),
+		nil,
+	)
+
 	descDeploymentStrategyRollingUpdateMaxUnavailable = newMetricFamilyDef(
 		"kube_deployment_spec_strategy_rollingupdate_max_unavailable",
 		"Maximum number of unavailable replicas during a rolling update of a deployment.",
@@ -107,6 +117,13 @@ var (
 		nil,
 	)
 
+	descDeploymentStatusCondition = newMetricFamilyDef(
+		"kube_deployment_status_condition",
+		"The current status conditions of a deployment.",
+		append(descDeploymentLabelsDefaultLabels, "condition", "status"),
+		nil,
+	)
+
 	descDeploymentLabels = newMetricFamilyDef(
 		descDeploymentLabelsName,
 		descDeploymentLabelsHelp,
@@ -134,7 +151,19 @@ func deploymentLabelsDesc(labelKeys []string) *metricFamilyDef {
 	)
 }
 
-func generateDeploymentMetrics(obj interface{}) []*metrics.Metric {
+func deploymentAnnotationsDesc(annotationKeys []string) *metricFamilyDef {
+	return newMetricFamilyDef(
+		descDeploymentAnnotationsName,
+		descDeploymentAnnotationsHelp,
+		append(descDeploymentLabelsDefaultLabels, annotationKeys...),
+		nil,
+	)
+}
+
+// generateDeploymentMetrics generates the metrics of a deployment.
+// kube_deployment_annotations is only generated if allowedAnnotations is
+// non-nil and holds the allowed annotation keys.
+func generateDeploymentMetrics(allowedAnnotations []string, allowedLabels []string, obj interface{}) []*metrics.Metric {
 	ms := []*metrics.Metric{}
 
 	// TODO: Refactor
@@ -152,8 +181,13 @@ func generateDeploymentMetrics(obj interface{}) []*metrics.Metric {
 
 		ms = append(ms, m)
 	}
-	labelKeys, labelValues := kubeLabelsToPrometheusLabels(d.Labels)
-	addGauge(deploymentLabelsDesc(labelKeys), 1, labelValues...)
+	if labelKeys, labelValues, ok := kubeLabelsToPrometheusLabels(d.Labels, allowedLabels); ok {
+		addGauge(deploymentLabelsDesc(labelKeys), 1, labelValues...)
+	}
+	if allowedAnnotations != nil {
+		annotationKeys, annotationValues := allowedAnnotationsToPrometheusAnnotations(d.Annotations, allowedAnnotations)
+		addGauge(deploymentAnnotationsDesc(annotationKeys), 1, annotationValues...)
+	}
 	if !d.CreationTimestamp.IsZero() {
 		addGauge(descDeploymentCreated, float64(d.CreationTimestamp.Unix()))
 	}
@@ -166,11 +200,21 @@ func generateDeploymentMetrics(obj interface{}) []*metrics.Metric {
 	addGauge(descDeploymentSpecReplicas, float64(*d.Spec.Replicas))
 	addGauge(descDeploymentMetadataGeneration, float64(d.ObjectMeta.Generation))
 
+	for _, c := range d.Status.Conditions {
+		ms = append(ms, addConditionMetrics(descDeploymentStatusCondition, c.Status, d.Namespace, d.Name, string(c.Type))...)
+	}
+
+	if t := d.Spec.Strategy.Type; t != "" {
+		addGauge(descDeploymentStrategyType, 1, string(t))
+	}
+
 	if d.Spec.Strategy.RollingUpdate == nil {
-		return nil
+		return ms
 	}
 
-	maxUnavailable, err := intstr.GetValueFromIntOrPercent(d.Spec.Strategy.RollingUpdate.MaxUnavailable, int(*d.Spec.Replicas), true)
+	// Percentages are resolved like the deployment controller does, rounding
+	// down max unavailable and rounding up max surge.
+	maxUnavailable, err := intstr.GetValueFromIntOrPercent(d.Spec.Strategy.RollingUpdate.MaxUnavailable, int(*d.Spec.Replicas), false)
 	if err != nil {
 		panic(err)
 	} else {
@@ -20,9 +20,11 @@ import (
 	"testing"
 	"time"
 
+	"k8s.io/api/core/v1"
 	"k8s.io/api/extensions/v1beta1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/kube-state-metrics/pkg/metrics"
 )
 
 var (
@@ -34,6 +36,10 @@ var (
 
 	depl1MaxSurge = intstr.FromInt(10)
 	depl2MaxSurge = intstr.FromString("20%")
+
+	depl4Replicas       int32 = 10
+	depl4MaxUnavailable       = intstr.FromString("25%")
+	depl4MaxSurge             = intstr.FromString("25%")
 )
 
 func TestDeploymentCollector(t *testing.T) {
@@ -58,12 +64,18 @@ func TestDeploymentCollector(t *testing.T) {
 		# TYPE kube_deployment_status_replicas_updated gauge
 		# HELP kube_deployment_status_observed_generation The generation observed by the deployment controller.
 		# TYPE kube_deployment_status_observed_generation gauge
+		# HELP kube_deployment_spec_strategy_type The strategy used to replace old pods by new ones.
+		# TYPE kube_deployment_spec_strategy_type gauge
 		# HELP kube_deployment_spec_strategy_rollingupdate_max_unavailable Maximum number of unavailable replicas during a rolling update of a deployment.
 		# TYPE kube_deployment_spec_strategy_rollingupdate_max_unavailable gauge
 		# HELP kube_deployment_spec_strategy_rollingupdate_max_surge Maximum number of replicas that can be scheduled above the desired number of replicas during a rolling update of a deployment.
 		# TYPE kube_deployment_spec_strategy_rollingupdate_max_surge gauge
 		# HELP kube_deployment_labels Kubernetes labels converted to Prometheus labels.
 		# TYPE kube_deployment_labels gauge
+		# HELP kube_deployment_annotations Kubernetes annotations converted to Prometheus labels.
+		# TYPE kube_deployment_annotations gauge
+		# HELP kube_deployment_status_condition The current status conditions of a deployment.
+		# TYPE kube_deployment_status_condition gauge
 	`
 	cases := []generateMetricsTestCase{
 		{
@@ -151,12 +163,156 @@ func TestDeploymentCollector(t *testing.T) {
         kube_deployment_status_replicas{deployment="depl2",namespace="ns2"} 10
 `,
 		},
+		{
+			Obj: &v1beta1.Deployment{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "depl3",
+					Namespace: "ns3",
+				},
+				Status: v1beta1.DeploymentStatus{
+					Conditions: []v1beta1.DeploymentCondition{
+						{Type: v1beta1.DeploymentAvailable, Status: v1.ConditionTrue, Reason: "MinimumReplicasAvailable"},
+						{Type: v1beta1.DeploymentProgressing, Status: v1.ConditionFalse, Reason: "ProgressDeadlineExceeded"},
+					},
+				},
+				Spec: v1beta1.DeploymentSpec{
+					Replicas: &depl2Replicas,
+					Strategy: v1beta1.DeploymentStrategy{
+						RollingUpdate: &v1beta1.RollingUpdateDeployment{
+							MaxUnavailable: &depl2MaxUnavailable,
+							MaxSurge:       &depl2MaxSurge,
+						},
+					},
+				},
+			},
+			Want: `
+        kube_deployment_status_condition{condition="Available",deployment="depl3",namespace="ns3",status="false"} 0
+        kube_deployment_status_condition{condition="Available",deployment="depl3",namespace="ns3",status="true"} 1
+        kube_deployment_status_condition{condition="Available",deployment="depl3",namespace="ns3",status="unknown"} 0
+        kube_deployment_status_condition{condition="Progressing",deployment="depl3",namespace="ns3",status="false"} 1
+        kube_deployment_status_condition{condition="Progressing",deployment="depl3",namespace="ns3",status="true"} 0
+        kube_deployment_status_condition{condition="Progressing",deployment="depl3",namespace="ns3",status="unknown"} 0
+`,
+			MetricNames: []string{"kube_deployment_status_condition"},
+		},
+		{
+			// Percentages of 10 replicas, max unavailable rounded down and
+			// max surge rounded up.
+			Obj: &v1beta1.Deployment{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "depl4",
+					Namespace: "ns4",
+				},
+				Spec: v1beta1.DeploymentSpec{
+					Replicas: &depl4Replicas,
+					Strategy: v1beta1.DeploymentStrategy{
+						Type: v1beta1.RollingUpdateDeploymentStrategyType,
+						RollingUpdate: &v1beta1.RollingUpdateDeployment{
+							MaxUnavailable: &depl4MaxUnavailable,
+							MaxSurge:       &depl4MaxSurge,
+						},
+					},
+				},
+			},
+			Want: `
+        kube_deployment_spec_strategy_rollingupdate_max_surge{deployment="depl4",namespace="ns4"} 3
+        kube_deployment_spec_strategy_rollingupdate_max_unavailable{deployment="depl4",namespace="ns4"} 2
+        kube_deployment_spec_strategy_type{deployment="depl4",namespace="ns4",type="RollingUpdate"} 1
+`,
+			MetricNames: []string{"kube_deployment_spec_strategy_type", "kube_deployment_spec_strategy_rollingupdate_max_unavailable", "kube_deployment_spec_strategy_rollingupdate_max_surge"},
+		},
+		{
+			// A deployment recreating its pods has no rolling update
+			// parameters.
+			Obj: &v1beta1.Deployment{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "depl5",
+					Namespace: "ns5",
+				},
+				Spec: v1beta1.DeploymentSpec{
+					Replicas: &depl2Replicas,
+					Strategy: v1beta1.DeploymentStrategy{
+						Type: v1beta1.RecreateDeploymentStrategyType,
+					},
+				},
+			},
+			Want: `
+        kube_deployment_spec_replicas{deployment="depl5",namespace="ns5"} 5
+        kube_deployment_spec_strategy_type{deployment="depl5",namespace="ns5",type="Recreate"} 1
+`,
+			MetricNames: []string{"kube_deployment_spec_replicas", "kube_deployment_spec_strategy_type", "kube_deployment_spec_strategy_rollingupdate_max_unavailable", "kube_deployment_spec_strategy_rollingupdate_max_surge"},
+		},
 	}
 
 	for i, c := range cases {
-		c.Func = generateDeploymentMetrics
+		c.Func = func(obj interface{}) []*metrics.Metric {
+			return generateDeploymentMetrics(nil, nil, obj)
+		}
 		if err := c.run(); err != nil {
 			t.Errorf("unexpected collecting result in %vth run:\n%s", i, err)
 		}
 	}
 }
+
+func TestDeploymentCollectorAnnotations(t *testing.T) {
+	d := &v1beta1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "depl1",
+			Namespace: "ns1",
+			Annotations: map[string]string{
+				"deployment.kubernetes.io/revision":                "3",
+				"example.com/team":                                 "foo",
+				"kubectl.kubernetes.io/last-applied-configuration": `{"apiVersion":"extensions/v1beta1","kind":"Deployment"}`,
+			},
+		},
+		Spec: v1beta1.DeploymentSpec{
+			Replicas: &depl2Replicas,
+			Strategy: v1beta1.DeploymentStrategy{
+				RollingUpdate: &v1beta1.RollingUpdateDeployment{
+					MaxUnavailable: &depl2MaxUnavailable,
+					MaxSurge:       &depl2MaxSurge,
+				},
+			},
+		},
+	}
+
+	tests := []struct {
+		Desc               string
+		AllowedAnnotations []string
+		Want               string
+	}{
+		{
+			Desc: "annotations suppressed by default",
+			Want: "",
+		},
+		{
+			Desc:               "allowed annotations only",
+			AllowedAnnotations: []string{"example.com/team", "deployment.kubernetes.io/revision"},
+			Want: `
+				kube_deployment_annotations{annotation_deployment_kubernetes_io_revision="3",annotation_example_com_team="foo",deployment="depl1",namespace="ns1"} 1
+`,
+		},
+		{
+			Desc:               "allowed annotation not set",
+			AllowedAnnotations: []string{"example.com/missing"},
+			Want: `
+				kube_deployment_annotations{deployment="depl1",namespace="ns1"} 1
+`,
+		},
+	}
+
+	for _, test := range tests {
+		allowedAnnotations := test.AllowedAnnotations
+		c := generateMetricsTestCase{
+			Obj:         d,
+			MetricNames: []string{"kube_deployment_annotations"},
+			Want:        test.Want,
+			Func: func(obj interface{}) []*metrics.Metric {
+				return generateDeploymentMetrics(allowedAnnotations, nil, obj)
+			},
+		}
+		if err := c.run(); err != nil {
+			t.Errorf("unexpected collecting result for %s:\n%s", test.Desc, err)
+		}
+	}
+}
@@ -17,6 +17,8 @@ limitations under the License.
 package collectors
 
 import (
+	"strconv"
+
 	"k8s.io/kube-state-metrics/pkg/metrics"
 
 	"k8s.io/api/core/v1"
@@ -66,6 +68,13 @@ var (
 		descEndpointLabelsDefaultLabels,
 		nil,
 	)
+
+	descEndpointPorts = newMetricFamilyDef(
+		"kube_endpoint_ports",
+		"Information about the ports of the endpoint.",
+		append(descEndpointLabelsDefaultLabels, "port_name", "port_protocol", "port_number"),
+		nil,
+	)
 )
 
 func createEndpointsListWatch(kubeClient clientset.Interface, ns string) cache.ListWatch {
@@ -79,7 +88,7 @@ func createEndpointsListWatch(kubeClient clientset.Interface, ns string) cache.L
 	}
 }
 
-func generateEndpointsMetrics(obj interface{}) []*metrics.Metric {
+func generateEndpointsMetrics(allowedLabels []string, obj interface{}) []*metrics.Metric {
 	ms := []*metrics.Metric{}
 
 	// TODO: Refactor
@@ -104,8 +113,9 @@ func generateEndpointsMetrics(obj interface{}) []*metrics.Metric {
 	if !e.CreationTimestamp.IsZero() {
 		addGauge(descEndpointCreated, float64(e.CreationTimestamp.Unix()))
 	}
-	labelKeys, labelValues := kubeLabelsToPrometheusLabels(e.Labels)
-	addGauge(endpointLabelsDesc(labelKeys), 1, labelValues...)
+	if labelKeys, labelValues, ok := kubeLabelsToPrometheusLabels(e.Labels, allowedLabels); ok {
+		addGauge(endpointLabelsDesc(labelKeys), 1, labelValues...)
+	}
 
 	var available int
 	for _, s := range e.Subsets {
@@ -119,6 +129,18 @@ func generateEndpointsMetrics(obj interface{}) []*metrics.Metric {
 	}
 	addGauge(descEndpointAddressNotReady, float64(notReady))
 
+	// Subsets of ready and not ready addresses commonly share their ports.
+	seenPorts := map[v1.EndpointPort]bool{}
+	for _, s := range e.Subsets {
+		for _, p := range s.Ports {
+			if seenPorts[p] {
+				continue
+			}
+			seenPorts[p] = true
+			addGauge(descEndpointPorts, 1, p.Name, string(p.Protocol), strconv.FormatInt(int64(p.Port), 10))
+		}
+	}
+
 	return ms
 }
 
@@ -24,6 +24,7 @@ import (
 
 	"k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/kube-state-metrics/pkg/metrics"
 )
 
 func TestEndpointCollector(t *testing.T) {
@@ -40,6 +41,8 @@ func TestEndpointCollector(t *testing.T) {
 		# TYPE kube_endpoint_info gauge
 		# HELP kube_endpoint_labels Kubernetes labels converted to Prometheus labels.
 		# TYPE kube_endpoint_labels gauge
+		# HELP kube_endpoint_ports Information about the ports of the endpoint.
+		# TYPE kube_endpoint_ports gauge
 	`
 	cases := []generateMetricsTestCase{
 		{
@@ -89,11 +92,69 @@ func TestEndpointCollector(t *testing.T) {
 				kube_endpoint_created{endpoint="test-endpoint",namespace="default"} 1.5e+09
 				kube_endpoint_info{endpoint="test-endpoint",namespace="default"} 1
 				kube_endpoint_labels{endpoint="test-endpoint",label_app="foobar",namespace="default"} 1
+				kube_endpoint_ports{endpoint="test-endpoint",namespace="default",port_name="",port_number="1234",port_protocol=""} 1
+				kube_endpoint_ports{endpoint="test-endpoint",namespace="default",port_name="",port_number="5678",port_protocol=""} 1
+				kube_endpoint_ports{endpoint="test-endpoint",namespace="default",port_name="",port_number="8080",port_protocol=""} 1
+				kube_endpoint_ports{endpoint="test-endpoint",namespace="default",port_name="",port_number="8081",port_protocol=""} 1
+				kube_endpoint_ports{endpoint="test-endpoint",namespace="default",port_name="",port_number="8443",port_protocol=""} 1
+				kube_endpoint_ports{endpoint="test-endpoint",namespace="default",port_name="",port_number="9090",port_protocol=""} 1
 			`,
 		},
+		{
+			Obj: &v1.Endpoints{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "mixed-endpoint",
+					Namespace: "default",
+				},
+				Subsets: []v1.EndpointSubset{
+					{
+						Addresses: []v1.EndpointAddress{
+							{IP: "10.0.0.1"},
+						},
+						NotReadyAddresses: []v1.EndpointAddress{
+							{IP: "10.0.0.2"}, {IP: "10.0.0.3"},
+						},
+						Ports: []v1.EndpointPort{
+							{Name: "http", Protocol: v1.ProtocolTCP, Port: 80},
+							{Name: "dns", Protocol: v1.ProtocolUDP, Port: 53},
+						},
+					},
+					{
+						NotReadyAddresses: []v1.EndpointAddress{
+							{IP: "10.0.1.1"},
+						},
+						Ports: []v1.EndpointPort{
+							{Name: "http", Protocol: v1.ProtocolTCP, Port: 80},
+						},
+					},
+				},
+			},
+			Want: `
+				kube_endpoint_address_available{endpoint="mixed-endpoint",namespace="default"} 2
+				kube_endpoint_address_not_ready{endpoint="mixed-endpoint",namespace="default"} 5
+				kube_endpoint_ports{endpoint="mixed-endpoint",namespace="default",port_name="dns",port_number="53",port_protocol="UDP"} 1
+				kube_endpoint_ports{endpoint="mixed-endpoint",namespace="default",port_name="http",port_number="80",port_protocol="TCP"} 1
+			`,
+			MetricNames: []string{"kube_endpoint_address_available", "kube_endpoint_address_not_ready", "kube_endpoint_ports"},
+		},
+		{
+			Obj: &v1.Endpoints{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "no-ready-endpoint",
+					Namespace: "default",
+				},
+			},
+			Want: `
+				kube_endpoint_address_available{endpoint="no-ready-endpoint",namespace="default"} 0
+				kube_endpoint_address_not_ready{endpoint="no-ready-endpoint",namespace="default"} 0
+			`,
+			MetricNames: []string{"kube_endpoint_address_available", "kube_endpoint_address_not_ready", "kube_endpoint_ports"},
+		},
 	}
 	for i, c := range cases {
-		c.Func = generateEndpointsMetrics
+		c.Func = func(obj interface{}) []*metrics.Metric {
+			return generateEndpointsMetrics(nil, obj)
+		}
 		if err := c.run(); err != nil {
 			t.Errorf("unexpected collecting result in %vth run:\n%s", i, err)
 		}
@@ -0,0 +1,162 @@
+/*
+Copyright 2018 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package collectors
+
+import (
+	"strconv"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/kube-state-metrics/pkg/customresource"
+	"k8s.io/kube-state-metrics/pkg/log"
+	"k8s.io/kube-state-metrics/pkg/metrics"
+)
+
+var (
+	descEndpointSliceLabelsDefaultLabels = []string{"namespace", "endpointslice"}
+
+	descEndpointSliceInfo = newMetricFamilyDef(
+		"kube_endpointslice_info",
+		"Information about endpointslice.",
+		append(descEndpointSliceLabelsDefaultLabels, "addresstype"),
+		nil,
+	)
+
+	descEndpointSliceCreated = newMetricFamilyDef(
+		"kube_endpointslice_created",
+		"Unix creation timestamp",
+		descEndpointSliceLabelsDefaultLabels,
+		nil,
+	)
+
+	descEndpointSliceEndpoints = newMetricFamilyDef(
+		"kube_endpointslice_endpoints",
+		"Endpoints of the endpointslice, one per address.",
+		append(descEndpointSliceLabelsDefaultLabels, "address", "ready", "hostname", "node"),
+		nil,
+	)
+
+	descEndpointSlicePorts = newMetricFamilyDef(
+		"kube_endpointslice_ports",
+		"Port numbers of the endpointslice.",
+		append(descEndpointSliceLabelsDefaultLabels, "port_name", "port_protocol"),
+		nil,
+	)
+
+	// endpointSliceResource is the discovery.k8s.io/v1beta1 EndpointSlice
+	// resource.
+	endpointSliceResource = customresource.Resource{
+		Group:      "discovery.k8s.io",
+		Version:    "v1beta1",
+		Resource:   "endpointslices",
+		Namespaced: true,
+	}
+)
+
+// endpointSlice holds the fields of a discovery.k8s.io/v1beta1 EndpointSlice
+// exposed as metrics.
+type endpointSlice struct {
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+	AddressType       string                  `json:"addressType"`
+	Endpoints         []endpointSliceEndpoint `json:"endpoints"`
+	Ports             []endpointSlicePort     `json:"ports"`
+}
+
+type endpointSliceEndpoint struct {
+	Addresses  []string                        `json:"addresses"`
+	Conditions endpointSliceEndpointConditions `json:"conditions"`
+	Hostname   *string                         `json:"hostname,omitempty"`
+	NodeName   *string                         `json:"nodeName,omitempty"`
+	Topology   map[string]string               `json:"topology,omitempty"`
+}
+
+type endpointSliceEndpointConditions struct {
+	Ready *bool `json:"ready,omitempty"`
+}
+
+type endpointSlicePort struct {
+	Name     *string `json:"name,omitempty"`
+	Protocol *string `json:"protocol,omitempty"`
+	Port     *int32  `json:"port,omitempty"`
+}
+
+func generateEndpointSliceMetrics(obj interface{}) []*metrics.Metric {
+	ms := []*metrics.Metric{}
+
+	s := endpointSlice{}
+	if err := runtime.DefaultUnstructuredConverter.FromUnstructured(obj.(*unstructured.Unstructured).Object, &s); err != nil {
+		log.Errorf("Failed to convert endpoint slice: %v", err)
+		return ms
+	}
+
+	addGauge := func(desc *metricFamilyDef, v float64, lv ...string) {
+		lv = append([]string{s.Namespace, s.Name}, lv...)
+
+		m, err := metrics.NewMetric(desc.Name, desc.LabelKeys, lv, v)
+		if err != nil {
+			panic(err)
+		}
+
+		ms = append(ms, m)
+	}
+
+	addGauge(descEndpointSliceInfo, 1, s.AddressType)
+
+	if !s.CreationTimestamp.IsZero() {
+		addGauge(descEndpointSliceCreated, float64(s.CreationTimestamp.Unix()))
+	}
+
+	for _, e := range s.Endpoints {
+		// A nil ready condition means unknown, which consumers should
+		// treat as ready.
+		ready := "unknown"
+		if e.Conditions.Ready != nil {
+			ready = strconv.FormatBool(*e.Conditions.Ready)
+		}
+		hostname := ""
+		if e.Hostname != nil {
+			hostname = *e.Hostname
+		}
+		// The node name field was added after the topology, which holds the
+		// node as kubernetes.io/hostname.
+		node := e.Topology["kubernetes.io/hostname"]
+		if e.NodeName != nil {
+			node = *e.NodeName
+		}
+		for _, address := range e.Addresses {
+			addGauge(descEndpointSliceEndpoints, 1, address, ready, hostname, node)
+		}
+	}
+
+	for _, p := range s.Ports {
+		// A port without number matches all ports of the endpoints.
+		if p.Port == nil {
+			continue
+		}
+		name, protocol := "", "TCP"
+		if p.Name != nil {
+			name = *p.Name
+		}
+		if p.Protocol != nil {
+			protocol = *p.Protocol
+		}
+		addGauge(descEndpointSlicePorts, float64(*p.Port), name, protocol)
+	}
+
+	return ms
+}
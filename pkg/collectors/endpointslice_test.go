@@ -0,0 +1,109 @@
+/*
+Copyright 2018 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package collectors
+
+import (
+	"testing"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func TestEndpointSliceCollector(t *testing.T) {
+	// Fixed metadata on type and help text. We prepend this to every expected
+	// output so we only have to modify a single place when doing adjustments.
+	const metadata = `
+		# HELP kube_endpointslice_info Information about endpointslice.
+		# TYPE kube_endpointslice_info gauge
+		# HELP kube_endpointslice_created Unix creation timestamp
+		# TYPE kube_endpointslice_created gauge
+		# HELP kube_endpointslice_endpoints Endpoints of the endpointslice, one per address.
+		# TYPE kube_endpointslice_endpoints gauge
+		# HELP kube_endpointslice_ports Port numbers of the endpointslice.
+		# TYPE kube_endpointslice_ports gauge
+	`
+	cases := []generateMetricsTestCase{
+		{
+			Obj: &unstructured.Unstructured{Object: map[string]interface{}{
+				"apiVersion":  "discovery.k8s.io/v1beta1",
+				"kind":        "EndpointSlice",
+				"addressType": "IPv4",
+				"metadata": map[string]interface{}{
+					"name":              "test-endpointslice-abcde",
+					"namespace":         "default",
+					"creationTimestamp": "2017-07-14T02:40:00Z",
+				},
+				"endpoints": []interface{}{
+					map[string]interface{}{
+						"addresses":  []interface{}{"10.0.0.1"},
+						"conditions": map[string]interface{}{"ready": true},
+						"hostname":   "pod-1",
+						"nodeName":   "node-1",
+					},
+					map[string]interface{}{
+						"addresses":  []interface{}{"10.0.0.2"},
+						"conditions": map[string]interface{}{"ready": false},
+						"topology":   map[string]interface{}{"kubernetes.io/hostname": "node-2"},
+					},
+					map[string]interface{}{
+						"addresses":  []interface{}{"10.0.0.3"},
+						"conditions": map[string]interface{}{},
+						"nodeName":   "node-2",
+					},
+				},
+				"ports": []interface{}{
+					map[string]interface{}{"name": "http", "protocol": "TCP", "port": int64(80)},
+					map[string]interface{}{"name": "https", "protocol": "TCP", "port": int64(443)},
+					map[string]interface{}{"name": "dns", "protocol": "UDP", "port": int64(53)},
+				},
+			}},
+			Want: `
+				kube_endpointslice_created{endpointslice="test-endpointslice-abcde",namespace="default"} 1.5e+09
+				kube_endpointslice_endpoints{address="10.0.0.1",endpointslice="test-endpointslice-abcde",hostname="pod-1",namespace="default",node="node-1",ready="true"} 1
+				kube_endpointslice_endpoints{address="10.0.0.2",endpointslice="test-endpointslice-abcde",hostname="",namespace="default",node="node-2",ready="false"} 1
+				kube_endpointslice_endpoints{address="10.0.0.3",endpointslice="test-endpointslice-abcde",hostname="",namespace="default",node="node-2",ready="unknown"} 1
+				kube_endpointslice_info{addresstype="IPv4",endpointslice="test-endpointslice-abcde",namespace="default"} 1
+				kube_endpointslice_ports{endpointslice="test-endpointslice-abcde",namespace="default",port_name="dns",port_protocol="UDP"} 53
+				kube_endpointslice_ports{endpointslice="test-endpointslice-abcde",namespace="default",port_name="http",port_protocol="TCP"} 80
+				kube_endpointslice_ports{endpointslice="test-endpointslice-abcde",namespace="default",port_name="https",port_protocol="TCP"} 443
+`,
+		},
+		{
+			// A slice of a service without selector and ports, which is
+			// exposed without endpoints and ports.
+			Obj: &unstructured.Unstructured{Object: map[string]interface{}{
+				"apiVersion":  "discovery.k8s.io/v1beta1",
+				"kind":        "EndpointSlice",
+				"addressType": "IPv6",
+				"metadata": map[string]interface{}{
+					"name":      "empty-endpointslice",
+					"namespace": "ns2",
+				},
+				"endpoints": []interface{}{},
+				"ports":     []interface{}{map[string]interface{}{}},
+			}},
+			Want: `
+				kube_endpointslice_info{addresstype="IPv6",endpointslice="empty-endpointslice",namespace="ns2"} 1
+`,
+		},
+	}
+	for i, c := range cases {
+		c.Func = generateEndpointSliceMetrics
+		if err := c.run(); err != nil {
+			t.Errorf("unexpected collecting result in %vth run:\n%s", i, err)
+		}
+	}
+}
@@ -20,6 +20,7 @@ import (
 	"k8s.io/kube-state-metrics/pkg/metrics"
 
 	autoscaling "k8s.io/api/autoscaling/v2beta1"
+	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/watch"
@@ -27,11 +28,25 @@ import (
 	"k8s.io/client-go/tools/cache"
 )
 
+// Values of the metric_target_type label of the HPA target and current metric
+// families.
+const (
+	hpaMetricTargetTypeValue       = "value"
+	hpaMetricTargetTypeAverage     = "average"
+	hpaMetricTargetTypeUtilization = "utilization"
+)
+
 var (
 	descHorizontalPodAutoscalerLabelsName          = "kube_hpa_labels"
 	descHorizontalPodAutoscalerLabelsHelp          = "Kubernetes labels converted to Prometheus labels."
 	descHorizontalPodAutoscalerLabelsDefaultLabels = []string{"namespace", "hpa"}
 
+	descHorizontalPodAutoscalerCreated = newMetricFamilyDef(
+		"kube_hpa_created",
+		"Unix creation timestamp",
+		descHorizontalPodAutoscalerLabelsDefaultLabels,
+		nil,
+	)
 	descHorizontalPodAutoscalerMetadataGeneration = newMetricFamilyDef(
 		"kube_hpa_metadata_generation",
 		"The generation observed by the HorizontalPodAutoscaler controller.",
@@ -68,6 +83,18 @@ var (
 		descHorizontalPodAutoscalerLabelsDefaultLabels,
 		nil,
 	)
+	descHorizontalPodAutoscalerSpecTargetMetric = newMetricFamilyDef(
+		"kube_hpa_spec_target_metric",
+		"The metric specifications used by this autoscaler when calculating the desired replica count.",
+		append(descHorizontalPodAutoscalerLabelsDefaultLabels, "metric_name", "metric_target_type"),
+		nil,
+	)
+	descHorizontalPodAutoscalerStatusCurrentMetric = newMetricFamilyDef(
+		"kube_hpa_status_current_metric",
+		"The current metric values observed by this autoscaler.",
+		append(descHorizontalPodAutoscalerLabelsDefaultLabels, "metric_name", "metric_target_type"),
+		nil,
+	)
 	descHorizontalPodAutoscalerCondition = newMetricFamilyDef(
 		"kube_hpa_status_condition",
 		"The condition of this autoscaler.",
@@ -96,7 +123,7 @@ func hpaLabelsDesc(labelKeys []string) *metricFamilyDef {
 	)
 }
 
-func generateHPAMetrics(obj interface{}) []*metrics.Metric {
+func generateHPAMetrics(allowedLabels []string, obj interface{}) []*metrics.Metric {
 
 	ms := []*metrics.Metric{}
 
@@ -114,17 +141,93 @@ func generateHPAMetrics(obj interface{}) []*metrics.Metric {
 
 		ms = append(ms, m)
 	}
-	labelKeys, labelValues := kubeLabelsToPrometheusLabels(h.Labels)
-	addGauge(hpaLabelsDesc(labelKeys), 1, labelValues...)
+	if labelKeys, labelValues, ok := kubeLabelsToPrometheusLabels(h.Labels, allowedLabels); ok {
+		addGauge(hpaLabelsDesc(labelKeys), 1, labelValues...)
+	}
+	if !h.CreationTimestamp.IsZero() {
+		addGauge(descHorizontalPodAutoscalerCreated, float64(h.CreationTimestamp.Unix()))
+	}
 	addGauge(descHorizontalPodAutoscalerMetadataGeneration, float64(h.ObjectMeta.Generation))
 	addGauge(descHorizontalPodAutoscalerSpecMaxReplicas, float64(h.Spec.MaxReplicas))
 	addGauge(descHorizontalPodAutoscalerSpecMinReplicas, float64(*h.Spec.MinReplicas))
 	addGauge(descHorizontalPodAutoscalerStatusCurrentReplicas, float64(h.Status.CurrentReplicas))
 	addGauge(descHorizontalPodAutoscalerStatusDesiredReplicas, float64(h.Status.DesiredReplicas))
 
+	for _, m := range h.Spec.Metrics {
+		switch m.Type {
+		case autoscaling.ObjectMetricSourceType:
+			if m.Object == nil {
+				continue
+			}
+			addGauge(descHorizontalPodAutoscalerSpecTargetMetric, quantityFloat64(m.Object.TargetValue), m.Object.MetricName, hpaMetricTargetTypeValue)
+		case autoscaling.PodsMetricSourceType:
+			if m.Pods == nil {
+				continue
+			}
+			addGauge(descHorizontalPodAutoscalerSpecTargetMetric, quantityFloat64(m.Pods.TargetAverageValue), m.Pods.MetricName, hpaMetricTargetTypeAverage)
+		case autoscaling.ResourceMetricSourceType:
+			if m.Resource == nil {
+				continue
+			}
+			if m.Resource.TargetAverageUtilization != nil {
+				addGauge(descHorizontalPodAutoscalerSpecTargetMetric, float64(*m.Resource.TargetAverageUtilization), string(m.Resource.Name), hpaMetricTargetTypeUtilization)
+			}
+			if m.Resource.TargetAverageValue != nil {
+				addGauge(descHorizontalPodAutoscalerSpecTargetMetric, quantityFloat64(*m.Resource.TargetAverageValue), string(m.Resource.Name), hpaMetricTargetTypeAverage)
+			}
+		case autoscaling.ExternalMetricSourceType:
+			if m.External == nil {
+				continue
+			}
+			if m.External.TargetValue != nil {
+				addGauge(descHorizontalPodAutoscalerSpecTargetMetric, quantityFloat64(*m.External.TargetValue), m.External.MetricName, hpaMetricTargetTypeValue)
+			}
+			if m.External.TargetAverageValue != nil {
+				addGauge(descHorizontalPodAutoscalerSpecTargetMetric, quantityFloat64(*m.External.TargetAverageValue), m.External.MetricName, hpaMetricTargetTypeAverage)
+			}
+		}
+	}
+
+	for _, m := range h.Status.CurrentMetrics {
+		switch m.Type {
+		case autoscaling.ObjectMetricSourceType:
+			if m.Object == nil {
+				continue
+			}
+			addGauge(descHorizontalPodAutoscalerStatusCurrentMetric, quantityFloat64(m.Object.CurrentValue), m.Object.MetricName, hpaMetricTargetTypeValue)
+		case autoscaling.PodsMetricSourceType:
+			if m.Pods == nil {
+				continue
+			}
+			addGauge(descHorizontalPodAutoscalerStatusCurrentMetric, quantityFloat64(m.Pods.CurrentAverageValue), m.Pods.MetricName, hpaMetricTargetTypeAverage)
+		case autoscaling.ResourceMetricSourceType:
+			if m.Resource == nil {
+				continue
+			}
+			if m.Resource.CurrentAverageUtilization != nil {
+				addGauge(descHorizontalPodAutoscalerStatusCurrentMetric, float64(*m.Resource.CurrentAverageUtilization), string(m.Resource.Name), hpaMetricTargetTypeUtilization)
+			}
+			addGauge(descHorizontalPodAutoscalerStatusCurrentMetric, quantityFloat64(m.Resource.CurrentAverageValue), string(m.Resource.Name), hpaMetricTargetTypeAverage)
+		case autoscaling.ExternalMetricSourceType:
+			if m.External == nil {
+				continue
+			}
+			addGauge(descHorizontalPodAutoscalerStatusCurrentMetric, quantityFloat64(m.External.CurrentValue), m.External.MetricName, hpaMetricTargetTypeValue)
+			if m.External.CurrentAverageValue != nil {
+				addGauge(descHorizontalPodAutoscalerStatusCurrentMetric, quantityFloat64(*m.External.CurrentAverageValue), m.External.MetricName, hpaMetricTargetTypeAverage)
+			}
+		}
+	}
+
 	for _, c := range h.Status.Conditions {
 		ms = append(ms, addConditionMetrics(descHorizontalPodAutoscalerCondition, c.Status, h.Namespace, h.Name, string(c.Type))...)
 	}
 
 	return ms
 }
+
+// quantityFloat64 returns the value of the given quantity as a float64,
+// keeping fractions of up to milli precision.
+func quantityFloat64(q resource.Quantity) float64 {
+	return float64(q.MilliValue()) / 1000
+}
@@ -18,19 +18,29 @@ package collectors
 
 import (
 	"testing"
+	"time"
 
 	autoscaling "k8s.io/api/autoscaling/v2beta1"
+	"k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/kube-state-metrics/pkg/metrics"
 )
 
 var (
-	hpa1MinReplicas int32 = 2
+	hpa1MinReplicas               int32 = 2
+	hpa2TargetAverageUtilization  int32 = 80
+	hpa2CurrentAverageUtilization int32 = 60
+	hpa2ExternalTargetValue             = resource.MustParse("10")
+	hpa2ExternalCurrentAverage          = resource.MustParse("2500m")
 )
 
 func TestHPACollector(t *testing.T) {
 	// Fixed metadata on type and help text. We prepend this to every expected
 	// output so we only have to modify a single place when doing adjustments.
 	const metadata = `
+		# HELP kube_hpa_created Unix creation timestamp
+		# TYPE kube_hpa_created gauge
 		# HELP kube_hpa_metadata_generation The generation observed by the HorizontalPodAutoscaler controller.
 		# TYPE kube_hpa_metadata_generation gauge
 		# HELP kube_hpa_spec_max_replicas Upper limit for the number of pods that can be set by the autoscaler; cannot be smaller than MinReplicas.
@@ -41,15 +51,22 @@ func TestHPACollector(t *testing.T) {
 		# TYPE kube_hpa_status_current_replicas gauge
 		# HELP kube_hpa_status_desired_replicas Desired number of replicas of pods managed by this autoscaler.
 		# TYPE kube_hpa_status_desired_replicas gauge
+		# HELP kube_hpa_spec_target_metric The metric specifications used by this autoscaler when calculating the desired replica count.
+		# TYPE kube_hpa_spec_target_metric gauge
+		# HELP kube_hpa_status_current_metric The current metric values observed by this autoscaler.
+		# TYPE kube_hpa_status_current_metric gauge
+		# HELP kube_hpa_status_condition The condition of this autoscaler.
+		# TYPE kube_hpa_status_condition gauge
 	`
 	cases := []generateMetricsTestCase{
 		{
 			// Verify populating base metrics.
 			Obj: &autoscaling.HorizontalPodAutoscaler{
 				ObjectMeta: metav1.ObjectMeta{
-					Generation: 2,
-					Name:       "hpa1",
-					Namespace:  "ns1",
+					Generation:        2,
+					Name:              "hpa1",
+					Namespace:         "ns1",
+					CreationTimestamp: metav1.Time{Time: time.Unix(1500000000, 0)},
 				},
 				Spec: autoscaling.HorizontalPodAutoscalerSpec{
 					MaxReplicas: 4,
@@ -66,6 +83,7 @@ func TestHPACollector(t *testing.T) {
 				},
 			},
 			Want: `
+				kube_hpa_created{hpa="hpa1",namespace="ns1"} 1.5e+09
 				kube_hpa_metadata_generation{hpa="hpa1",namespace="ns1"} 2
 				kube_hpa_spec_max_replicas{hpa="hpa1",namespace="ns1"} 4
 				kube_hpa_spec_min_replicas{hpa="hpa1",namespace="ns1"} 2
@@ -73,6 +91,7 @@ func TestHPACollector(t *testing.T) {
 				kube_hpa_status_desired_replicas{hpa="hpa1",namespace="ns1"} 2
 			`,
 			MetricNames: []string{
+				"kube_hpa_created",
 				"kube_hpa_metadata_generation",
 				"kube_hpa_spec_max_replicas",
 				"kube_hpa_spec_min_replicas",
@@ -80,9 +99,158 @@ func TestHPACollector(t *testing.T) {
 				"kube_hpa_status_desired_replicas",
 			},
 		},
+		{
+			// Verify populating target and current metric values of all
+			// metric source types.
+			Obj: &autoscaling.HorizontalPodAutoscaler{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "hpa2",
+					Namespace: "ns2",
+				},
+				Spec: autoscaling.HorizontalPodAutoscalerSpec{
+					MaxReplicas: 10,
+					MinReplicas: &hpa1MinReplicas,
+					Metrics: []autoscaling.MetricSpec{
+						{
+							Type: autoscaling.ResourceMetricSourceType,
+							Resource: &autoscaling.ResourceMetricSource{
+								Name:                     v1.ResourceCPU,
+								TargetAverageUtilization: &hpa2TargetAverageUtilization,
+							},
+						},
+						{
+							Type: autoscaling.PodsMetricSourceType,
+							Pods: &autoscaling.PodsMetricSource{
+								MetricName:         "packets_per_second",
+								TargetAverageValue: resource.MustParse("1k"),
+							},
+						},
+						{
+							Type: autoscaling.ObjectMetricSourceType,
+							Object: &autoscaling.ObjectMetricSource{
+								MetricName:  "requests_per_second",
+								TargetValue: resource.MustParse("2k"),
+							},
+						},
+						{
+							Type: autoscaling.ExternalMetricSourceType,
+							External: &autoscaling.ExternalMetricSource{
+								MetricName:  "queue_length",
+								TargetValue: &hpa2ExternalTargetValue,
+							},
+						},
+					},
+				},
+				Status: autoscaling.HorizontalPodAutoscalerStatus{
+					CurrentMetrics: []autoscaling.MetricStatus{
+						{
+							Type: autoscaling.ResourceMetricSourceType,
+							Resource: &autoscaling.ResourceMetricStatus{
+								Name:                      v1.ResourceCPU,
+								CurrentAverageUtilization: &hpa2CurrentAverageUtilization,
+								CurrentAverageValue:       resource.MustParse("300m"),
+							},
+						},
+						{
+							Type: autoscaling.PodsMetricSourceType,
+							Pods: &autoscaling.PodsMetricStatus{
+								MetricName:          "packets_per_second",
+								CurrentAverageValue: resource.MustParse("500"),
+							},
+						},
+						{
+							Type: autoscaling.ObjectMetricSourceType,
+							Object: &autoscaling.ObjectMetricStatus{
+								MetricName:   "requests_per_second",
+								CurrentValue: resource.MustParse("1500"),
+							},
+						},
+						{
+							Type: autoscaling.ExternalMetricSourceType,
+							External: &autoscaling.ExternalMetricStatus{
+								MetricName:          "queue_length",
+								CurrentValue:        resource.MustParse("5"),
+								CurrentAverageValue: &hpa2ExternalCurrentAverage,
+							},
+						},
+					},
+				},
+			},
+			Want: `
+				kube_hpa_spec_target_metric{hpa="hpa2",metric_name="cpu",metric_target_type="utilization",namespace="ns2"} 80
+				kube_hpa_spec_target_metric{hpa="hpa2",metric_name="packets_per_second",metric_target_type="average",namespace="ns2"} 1000
+				kube_hpa_spec_target_metric{hpa="hpa2",metric_name="requests_per_second",metric_target_type="value",namespace="ns2"} 2000
+				kube_hpa_spec_target_metric{hpa="hpa2",metric_name="queue_length",metric_target_type="value",namespace="ns2"} 10
+				kube_hpa_status_current_metric{hpa="hpa2",metric_name="cpu",metric_target_type="utilization",namespace="ns2"} 60
+				kube_hpa_status_current_metric{hpa="hpa2",metric_name="cpu",metric_target_type="average",namespace="ns2"} 0.3
+				kube_hpa_status_current_metric{hpa="hpa2",metric_name="packets_per_second",metric_target_type="average",namespace="ns2"} 500
+				kube_hpa_status_current_metric{hpa="hpa2",metric_name="requests_per_second",metric_target_type="value",namespace="ns2"} 1500
+				kube_hpa_status_current_metric{hpa="hpa2",metric_name="queue_length",metric_target_type="value",namespace="ns2"} 5
+				kube_hpa_status_current_metric{hpa="hpa2",metric_name="queue_length",metric_target_type="average",namespace="ns2"} 2.5
+			`,
+			MetricNames: []string{
+				"kube_hpa_spec_target_metric",
+				"kube_hpa_status_current_metric",
+			},
+		},
+		{
+			// Verify the conditions of an autoscaler at its max replicas.
+			Obj: &autoscaling.HorizontalPodAutoscaler{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "hpa3",
+					Namespace: "ns3",
+				},
+				Spec: autoscaling.HorizontalPodAutoscalerSpec{
+					MaxReplicas: 4,
+					MinReplicas: &hpa1MinReplicas,
+				},
+				Status: autoscaling.HorizontalPodAutoscalerStatus{
+					CurrentReplicas: 4,
+					DesiredReplicas: 4,
+					Conditions: []autoscaling.HorizontalPodAutoscalerCondition{
+						{
+							Type:   autoscaling.AbleToScale,
+							Status: v1.ConditionTrue,
+							Reason: "ReadyForNewScale",
+						},
+						{
+							Type:   autoscaling.ScalingActive,
+							Status: v1.ConditionTrue,
+							Reason: "ValidMetricFound",
+						},
+						{
+							Type:    autoscaling.ScalingLimited,
+							Status:  v1.ConditionTrue,
+							Reason:  "TooManyReplicas",
+							Message: "the desired replica count is more than the maximum replica count",
+						},
+					},
+				},
+			},
+			Want: `
+				kube_hpa_spec_max_replicas{hpa="hpa3",namespace="ns3"} 4
+				kube_hpa_status_condition{condition="AbleToScale",hpa="hpa3",namespace="ns3",status="false"} 0
+				kube_hpa_status_condition{condition="AbleToScale",hpa="hpa3",namespace="ns3",status="true"} 1
+				kube_hpa_status_condition{condition="AbleToScale",hpa="hpa3",namespace="ns3",status="unknown"} 0
+				kube_hpa_status_condition{condition="ScalingActive",hpa="hpa3",namespace="ns3",status="false"} 0
+				kube_hpa_status_condition{condition="ScalingActive",hpa="hpa3",namespace="ns3",status="true"} 1
+				kube_hpa_status_condition{condition="ScalingActive",hpa="hpa3",namespace="ns3",status="unknown"} 0
+				kube_hpa_status_condition{condition="ScalingLimited",hpa="hpa3",namespace="ns3",status="false"} 0
+				kube_hpa_status_condition{condition="ScalingLimited",hpa="hpa3",namespace="ns3",status="true"} 1
+				kube_hpa_status_condition{condition="ScalingLimited",hpa="hpa3",namespace="ns3",status="unknown"} 0
+				kube_hpa_status_current_replicas{hpa="hpa3",namespace="ns3"} 4
+			`,
+			MetricNames: []string{
+				"kube_hpa_spec_max_replicas",
+				"kube_hpa_status_condition",
+				"kube_hpa_status_current_replicas",
+			},
+		},
 	}
 	for i, c := range cases {
-		c.Func = generateHPAMetrics
+		c.Func = func(obj interface{}) []*metrics.Metric {
+			return generateHPAMetrics(nil, obj)
+		}
 		if err := c.run(); err != nil {
 			t.Errorf("unexpected collecting result in %vth run:\n%s", i, err)
 		}
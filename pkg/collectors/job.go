@@ -96,7 +96,7 @@ var (
 	descJobConditionFailed = newMetricFamilyDef(
 		"kube_job_failed",
 		"The job has failed its execution.",
-		append(descJobLabelsDefaultLabels, "condition"),
+		append(descJobLabelsDefaultLabels, "reason", "condition"),
 		nil,
 	)
 	descJobStatusStartTime = newMetricFamilyDef(
@@ -133,7 +133,7 @@ func jobLabelsDesc(labelKeys []string) *metricFamilyDef {
 	)
 }
 
-func generateJobMetrics(obj interface{}) []*metrics.Metric {
+func generateJobMetrics(allowedLabels []string, obj interface{}) []*metrics.Metric {
 	ms := []*metrics.Metric{}
 
 	// TODO: Refactor
@@ -153,8 +153,9 @@ func generateJobMetrics(obj interface{}) []*metrics.Metric {
 
 	addGauge(descJobInfo, 1)
 
-	labelKeys, labelValues := kubeLabelsToPrometheusLabels(j.Labels)
-	addGauge(jobLabelsDesc(labelKeys), 1, labelValues...)
+	if labelKeys, labelValues, ok := kubeLabelsToPrometheusLabels(j.Labels, allowedLabels); ok {
+		addGauge(jobLabelsDesc(labelKeys), 1, labelValues...)
+	}
 
 	if j.Spec.Parallelism != nil {
 		addGauge(descJobSpecParallelism, float64(*j.Spec.Parallelism))
@@ -188,7 +189,7 @@ func generateJobMetrics(obj interface{}) []*metrics.Metric {
 		case v1batch.JobComplete:
 			ms = append(ms, addConditionMetrics(descJobConditionComplete, c.Status, j.Namespace, j.Name)...)
 		case v1batch.JobFailed:
-			ms = append(ms, addConditionMetrics(descJobConditionFailed, c.Status, j.Namespace, j.Name)...)
+			ms = append(ms, addConditionMetrics(descJobConditionFailed, c.Status, j.Namespace, j.Name, c.Reason)...)
 		}
 	}
 	return ms
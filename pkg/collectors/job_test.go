@@ -23,6 +23,7 @@ import (
 	v1batch "k8s.io/api/batch/v1"
 	"k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/kube-state-metrics/pkg/metrics"
 )
 
 var (
@@ -33,6 +34,7 @@ var (
 	RunningJob1StartTime, _    = time.Parse(time.RFC3339, "2017-05-26T12:00:07Z")
 	SuccessfulJob1StartTime, _ = time.Parse(time.RFC3339, "2017-05-26T12:00:07Z")
 	FailedJob1StartTime, _     = time.Parse(time.RFC3339, "2017-05-26T14:00:07Z")
+	FailedJob2StartTime, _     = time.Parse(time.RFC3339, "2017-05-26T16:00:07Z")
 	SuccessfulJob2StartTime, _ = time.Parse(time.RFC3339, "2017-05-26T12:10:07Z")
 
 	SuccessfulJob1CompletionTime, _ = time.Parse(time.RFC3339, "2017-05-26T13:00:07Z")
@@ -178,9 +180,9 @@ func TestJobCollector(t *testing.T) {
 				},
 			},
 			Want: `
-				kube_job_failed{condition="false",job_name="FailedJob1",namespace="ns1"} 0
-				kube_job_failed{condition="true",job_name="FailedJob1",namespace="ns1"} 1
-				kube_job_failed{condition="unknown",job_name="FailedJob1",namespace="ns1"} 0
+				kube_job_failed{condition="false",job_name="FailedJob1",namespace="ns1",reason=""} 0
+				kube_job_failed{condition="true",job_name="FailedJob1",namespace="ns1",reason=""} 1
+				kube_job_failed{condition="unknown",job_name="FailedJob1",namespace="ns1",reason=""} 0
 				kube_job_info{job_name="FailedJob1",namespace="ns1"} 1
 				kube_job_labels{job_name="FailedJob1",label_app="example-failed-1",namespace="ns1"} 1
 				kube_job_spec_active_deadline_seconds{job_name="FailedJob1",namespace="ns1"} 900
@@ -235,9 +237,53 @@ func TestJobCollector(t *testing.T) {
 				kube_job_status_succeeded{job_name="SuccessfulJob2NoActiveDeadlineSeconds",namespace="ns1"} 1
 `,
 		},
+		{
+			Obj: &v1batch.Job{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:       "FailedJob2DeadlineExceeded",
+					Namespace:  "ns1",
+					Generation: 1,
+				},
+				Status: v1batch.JobStatus{
+					Active:    0,
+					Failed:    1,
+					Succeeded: 0,
+					StartTime: &metav1.Time{Time: FailedJob2StartTime},
+					Conditions: []v1batch.JobCondition{
+						{Type: v1batch.JobFailed, Status: v1.ConditionTrue, Reason: "DeadlineExceeded"},
+					},
+				},
+				Spec: v1batch.JobSpec{
+					ActiveDeadlineSeconds: &ActiveDeadlineSeconds900,
+					Parallelism:           &Parallelism1,
+					Completions:           &Completions1,
+				},
+			},
+			Want: `
+				kube_job_failed{condition="false",job_name="FailedJob2DeadlineExceeded",namespace="ns1",reason="DeadlineExceeded"} 0
+				kube_job_failed{condition="true",job_name="FailedJob2DeadlineExceeded",namespace="ns1",reason="DeadlineExceeded"} 1
+				kube_job_failed{condition="unknown",job_name="FailedJob2DeadlineExceeded",namespace="ns1",reason="DeadlineExceeded"} 0
+				kube_job_spec_active_deadline_seconds{job_name="FailedJob2DeadlineExceeded",namespace="ns1"} 900
+				kube_job_status_active{job_name="FailedJob2DeadlineExceeded",namespace="ns1"} 0
+				kube_job_status_failed{job_name="FailedJob2DeadlineExceeded",namespace="ns1"} 1
+				kube_job_status_start_time{job_name="FailedJob2DeadlineExceeded",namespace="ns1"} 1.495814407e+09
+				kube_job_status_succeeded{job_name="FailedJob2DeadlineExceeded",namespace="ns1"} 0
+`,
+			MetricNames: []string{
+				"kube_job_failed",
+				"kube_job_spec_active_deadline_seconds",
+				"kube_job_status_active",
+				"kube_job_status_completion_time",
+				"kube_job_status_failed",
+				"kube_job_status_start_time",
+				"kube_job_status_succeeded",
+			},
+		},
 	}
 	for i, c := range cases {
-		c.Func = generateJobMetrics
+		c.Func = func(obj interface{}) []*metrics.Metric {
+			return generateJobMetrics(nil, obj)
+		}
 		if err := c.run(); err != nil {
 			t.Errorf("unexpected collecting result in %vth run:\n%s", i, err)
 		}
@@ -0,0 +1,110 @@
+/*
+Copyright 2018 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package collectors
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/kube-state-metrics/pkg/customresource"
+	"k8s.io/kube-state-metrics/pkg/log"
+	"k8s.io/kube-state-metrics/pkg/metrics"
+)
+
+var (
+	descLeaseLabelsDefaultLabels = []string{"namespace", "lease"}
+
+	descLeaseOwner = newMetricFamilyDef(
+		"kube_lease_owner",
+		"Information about the Lease's owner.",
+		append(descLeaseLabelsDefaultLabels, "owner_kind", "owner_name"),
+		nil,
+	)
+
+	descLeaseCreated = newMetricFamilyDef(
+		"kube_lease_created",
+		"Unix creation timestamp",
+		descLeaseLabelsDefaultLabels,
+		nil,
+	)
+
+	descLeaseRenewTime = newMetricFamilyDef(
+		"kube_lease_renew_time",
+		"Kube lease renew time.",
+		descLeaseLabelsDefaultLabels,
+		nil,
+	)
+
+	// leaseResource is the coordination.k8s.io/v1 Lease resource.
+	leaseResource = customresource.Resource{
+		Group:      "coordination.k8s.io",
+		Version:    "v1",
+		Resource:   "leases",
+		Namespaced: true,
+	}
+)
+
+// lease holds the fields of a coordination.k8s.io/v1 Lease exposed as
+// metrics.
+type lease struct {
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+	Spec              leaseSpec `json:"spec,omitempty"`
+}
+
+type leaseSpec struct {
+	RenewTime *metav1.MicroTime `json:"renewTime,omitempty"`
+}
+
+func generateLeaseMetrics(obj interface{}) []*metrics.Metric {
+	ms := []*metrics.Metric{}
+
+	l := lease{}
+	if err := runtime.DefaultUnstructuredConverter.FromUnstructured(obj.(*unstructured.Unstructured).Object, &l); err != nil {
+		log.Errorf("Failed to convert lease: %v", err)
+		return ms
+	}
+
+	addGauge := func(desc *metricFamilyDef, v float64, lv ...string) {
+		lv = append([]string{l.Namespace, l.Name}, lv...)
+
+		m, err := metrics.NewMetric(desc.Name, desc.LabelKeys, lv, v)
+		if err != nil {
+			panic(err)
+		}
+
+		ms = append(ms, m)
+	}
+
+	owners := l.GetOwnerReferences()
+	if len(owners) == 0 {
+		addGauge(descLeaseOwner, 1, "<none>", "<none>")
+	} else {
+		for _, owner := range owners {
+			addGauge(descLeaseOwner, 1, owner.Kind, owner.Name)
+		}
+	}
+
+	if !l.CreationTimestamp.IsZero() {
+		addGauge(descLeaseCreated, float64(l.CreationTimestamp.Unix()))
+	}
+
+	if l.Spec.RenewTime != nil && !l.Spec.RenewTime.IsZero() {
+		addGauge(descLeaseRenewTime, float64(l.Spec.RenewTime.Unix()))
+	}
+
+	return ms
+}
@@ -0,0 +1,89 @@
+/*
+Copyright 2018 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package collectors
+
+import (
+	"testing"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func TestLeaseCollector(t *testing.T) {
+	// Fixed metadata on type and help text. We prepend this to every expected
+	// output so we only have to modify a single place when doing adjustments.
+	const metadata = `
+		# HELP kube_lease_owner Information about the Lease's owner.
+		# TYPE kube_lease_owner gauge
+		# HELP kube_lease_created Unix creation timestamp
+		# TYPE kube_lease_created gauge
+		# HELP kube_lease_renew_time Kube lease renew time.
+		# TYPE kube_lease_renew_time gauge
+	`
+	cases := []generateMetricsTestCase{
+		{
+			Obj: &unstructured.Unstructured{Object: map[string]interface{}{
+				"apiVersion": "coordination.k8s.io/v1",
+				"kind":       "Lease",
+				"metadata": map[string]interface{}{
+					"name":              "node1",
+					"namespace":         "kube-node-lease",
+					"creationTimestamp": "2017-07-14T02:40:00Z",
+					"ownerReferences": []interface{}{
+						map[string]interface{}{
+							"apiVersion": "v1",
+							"kind":       "Node",
+							"name":       "node1",
+							"uid":        "4a5d9d3e-6a4a-4b1d-9a35-0cd7b9b8f8a1",
+						},
+					},
+				},
+				"spec": map[string]interface{}{
+					"holderIdentity":       "node1",
+					"leaseDurationSeconds": int64(40),
+					"renewTime":            "2017-07-14T02:40:00.000000Z",
+				},
+			}},
+			Want: `
+				kube_lease_created{lease="node1",namespace="kube-node-lease"} 1.5e+09
+				kube_lease_owner{lease="node1",namespace="kube-node-lease",owner_kind="Node",owner_name="node1"} 1
+				kube_lease_renew_time{lease="node1",namespace="kube-node-lease"} 1.5e+09
+`,
+		},
+		{
+			Obj: &unstructured.Unstructured{Object: map[string]interface{}{
+				"apiVersion": "coordination.k8s.io/v1",
+				"kind":       "Lease",
+				"metadata": map[string]interface{}{
+					"name":      "kube-controller-manager",
+					"namespace": "kube-system",
+				},
+				"spec": map[string]interface{}{
+					"holderIdentity": "master1_4f3b2a1c",
+				},
+			}},
+			Want: `
+				kube_lease_owner{lease="kube-controller-manager",namespace="kube-system",owner_kind="<none>",owner_name="<none>"} 1
+`,
+		},
+	}
+	for i, c := range cases {
+		c.Func = generateLeaseMetrics
+		if err := c.run(); err != nil {
+			t.Errorf("unexpected collecting result in %vth run:\n%s", i, err)
+		}
+	}
+}
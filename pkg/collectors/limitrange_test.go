@@ -77,6 +77,38 @@ func TestLimitRangeollector(t *testing.T) {
 
 		`,
 		},
+		{
+			Obj: &v1.LimitRange{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "limitsTest",
+					Namespace: "testNS",
+				},
+				Spec: v1.LimitRangeSpec{
+					Limits: []v1.LimitRangeItem{
+						{
+							Type: v1.LimitTypeContainer,
+							Min: map[v1.ResourceName]resource.Quantity{
+								v1.ResourceCPU: resource.MustParse("100m"),
+							},
+							Max: map[v1.ResourceName]resource.Quantity{
+								v1.ResourceCPU: resource.MustParse("2"),
+							},
+						},
+						{
+							Type: v1.LimitTypePersistentVolumeClaim,
+							Max: map[v1.ResourceName]resource.Quantity{
+								v1.ResourceStorage: resource.MustParse("10G"),
+							},
+						},
+					},
+				},
+			},
+			Want: `
+        kube_limitrange{constraint="max",limitrange="limitsTest",namespace="testNS",resource="cpu",type="Container"} 2
+        kube_limitrange{constraint="max",limitrange="limitsTest",namespace="testNS",resource="storage",type="PersistentVolumeClaim"} 1e+10
+        kube_limitrange{constraint="min",limitrange="limitsTest",namespace="testNS",resource="cpu",type="Container"} 0.1
+		`,
+		},
 	}
 	for i, c := range cases {
 		c.Func = generateLimitRangeMetrics
@@ -0,0 +1,95 @@
+/*
+Copyright 2018 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package collectors
+
+import (
+	"k8s.io/kube-state-metrics/pkg/metrics"
+
+	admissionregistration "k8s.io/api/admissionregistration/v1beta1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/watch"
+	clientset "k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/cache"
+)
+
+var (
+	descMutatingWebhookConfigurationLabelsDefaultLabels = []string{"mutatingwebhookconfiguration"}
+
+	descMutatingWebhookConfigurationInfo = newMetricFamilyDef(
+		"kube_mutatingwebhookconfiguration_info",
+		"Information about the MutatingWebhookConfiguration.",
+		descMutatingWebhookConfigurationLabelsDefaultLabels,
+		nil,
+	)
+	descMutatingWebhookConfigurationCreated = newMetricFamilyDef(
+		"kube_mutatingwebhookconfiguration_created",
+		"Unix creation timestamp.",
+		descMutatingWebhookConfigurationLabelsDefaultLabels,
+		nil,
+	)
+	descMutatingWebhookConfigurationWebhookClientConfigService = newMetricFamilyDef(
+		"kube_mutatingwebhookconfiguration_webhook_clientconfig_service",
+		"Service used by the apiserver to connect to a mutating webhook.",
+		append(descMutatingWebhookConfigurationLabelsDefaultLabels, "webhook", "namespace", "service"),
+		nil,
+	)
+)
+
+func createMutatingWebhookConfigurationListWatch(kubeClient clientset.Interface, ns string) cache.ListWatch {
+	return cache.ListWatch{
+		ListFunc: func(opts metav1.ListOptions) (runtime.Object, error) {
+			return kubeClient.AdmissionregistrationV1beta1().MutatingWebhookConfigurations().List(opts)
+		},
+		WatchFunc: func(opts metav1.ListOptions) (watch.Interface, error) {
+			return kubeClient.AdmissionregistrationV1beta1().MutatingWebhookConfigurations().Watch(opts)
+		},
+	}
+}
+
+func generateMutatingWebhookConfigurationMetrics(obj interface{}) []*metrics.Metric {
+	ms := []*metrics.Metric{}
+
+	// TODO: Refactor
+	wPointer := obj.(*admissionregistration.MutatingWebhookConfiguration)
+	w := *wPointer
+
+	addGauge := func(desc *metricFamilyDef, v float64, lv ...string) {
+		lv = append([]string{w.Name}, lv...)
+
+		m, err := metrics.NewMetric(desc.Name, desc.LabelKeys, lv, v)
+		if err != nil {
+			panic(err)
+		}
+
+		ms = append(ms, m)
+	}
+
+	addGauge(descMutatingWebhookConfigurationInfo, 1)
+
+	if !w.CreationTimestamp.IsZero() {
+		addGauge(descMutatingWebhookConfigurationCreated, float64(w.CreationTimestamp.Unix()))
+	}
+
+	for _, webhook := range w.Webhooks {
+		if s := webhook.ClientConfig.Service; s != nil {
+			addGauge(descMutatingWebhookConfigurationWebhookClientConfigService, 1, webhook.Name, s.Namespace, s.Name)
+		}
+	}
+
+	return ms
+}
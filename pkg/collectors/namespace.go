@@ -60,8 +60,20 @@ var (
 		append(descNamespaceLabelsDefaultLabels, "phase"),
 		nil,
 	)
+	descNamespaceObjectCount = newMetricFamilyDef(
+		"kube_namespace_status_object_count",
+		"Number of objects per namespace and resource.",
+		append(descNamespaceLabelsDefaultLabels, "resource"),
+		nil,
+	)
 )
 
+// namespaceCounter is implemented by stores able to count their objects per
+// namespace, e.g. the MetricsStore.
+type namespaceCounter interface {
+	CountByNamespace() map[string]int
+}
+
 func createNamespaceListWatch(kubeClient clientset.Interface, ns string) cache.ListWatch {
 	return cache.ListWatch{
 		ListFunc: func(opts metav1.ListOptions) (runtime.Object, error) {
@@ -73,7 +85,10 @@ func createNamespaceListWatch(kubeClient clientset.Interface, ns string) cache.L
 	}
 }
 
-func generateNamespaceMetrics(obj interface{}) []*metrics.Metric {
+// generateNamespaceMetrics generates the metrics of a namespace. Annotations
+// may hold large values like kubectl's last-applied-configuration, hence
+// kube_namespace_annotations is only generated if enableAnnotations is set.
+func generateNamespaceMetrics(enableAnnotations bool, allowedLabels []string, obj interface{}) []*metrics.Metric {
 	ms := []*metrics.Metric{}
 
 	// TODO: Refactor
@@ -98,11 +113,14 @@ func generateNamespaceMetrics(obj interface{}) []*metrics.Metric {
 		addGauge(descNamespaceCreated, float64(n.CreationTimestamp.Unix()))
 	}
 
-	labelKeys, labelValues := kubeLabelsToPrometheusLabels(n.Labels)
-	addGauge(namespaceLabelsDesc(labelKeys), 1, labelValues...)
+	if labelKeys, labelValues, ok := kubeLabelsToPrometheusLabels(n.Labels, allowedLabels); ok {
+		addGauge(namespaceLabelsDesc(labelKeys), 1, labelValues...)
+	}
 
-	annnotationKeys, annotationValues := kubeAnnotationsToPrometheusAnnotations(n.Annotations)
-	addGauge(namespaceAnnotationsDesc(annnotationKeys), 1, annotationValues...)
+	if enableAnnotations {
+		annnotationKeys, annotationValues := kubeAnnotationsToPrometheusAnnotations(n.Annotations)
+		addGauge(namespaceAnnotationsDesc(annnotationKeys), 1, annotationValues...)
+	}
 
 	return ms
 }
@@ -124,3 +142,26 @@ func namespaceAnnotationsDesc(annotationKeys []string) *metricFamilyDef {
 		nil,
 	)
 }
+
+// generateNamespaceObjectCountMetrics generates the number of objects per
+// namespace held by the stores of the given collectors, using the collector
+// names as resource. Stores unable to count their objects are skipped.
+func generateNamespaceObjectCountMetrics(collectors []*Collector) []*metrics.Metric {
+	ms := []*metrics.Metric{}
+
+	for _, c := range collectors {
+		counter, ok := c.store.(namespaceCounter)
+		if !ok {
+			continue
+		}
+		for ns, count := range counter.CountByNamespace() {
+			m, err := metrics.NewMetric(descNamespaceObjectCount.Name, descNamespaceObjectCount.LabelKeys, []string{ns, c.name}, float64(count))
+			if err != nil {
+				panic(err)
+			}
+			ms = append(ms, m)
+		}
+	}
+
+	return ms
+}
@@ -22,6 +22,8 @@ import (
 
 	"k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/kube-state-metrics/pkg/metrics"
+	metricsstore "k8s.io/kube-state-metrics/pkg/metrics_store"
 )
 
 func TestNamespaceCollector(t *testing.T) {
@@ -135,9 +137,98 @@ func TestNamespaceCollector(t *testing.T) {
 	}
 
 	for i, c := range cases {
-		c.Func = generateNamespaceMetrics
+		c.Func = func(obj interface{}) []*metrics.Metric {
+			return generateNamespaceMetrics(true, nil, obj)
+		}
 		if err := c.run(); err != nil {
 			t.Errorf("unexpected collecting result in %vth run:\n%s", i, err)
 		}
 	}
 }
+
+func TestNamespaceCollectorAnnotationsDisabled(t *testing.T) {
+	c := generateMetricsTestCase{
+		Obj: &v1.Namespace{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:              "nsStuckTerminating",
+				DeletionTimestamp: &metav1.Time{Time: time.Unix(1500000000, 0)},
+				Annotations: map[string]string{
+					"kubectl.kubernetes.io/last-applied-configuration": `{"apiVersion":"v1","kind":"Namespace"}`,
+				},
+			},
+			Spec: v1.NamespaceSpec{
+				Finalizers: []v1.FinalizerName{v1.FinalizerKubernetes},
+			},
+			Status: v1.NamespaceStatus{
+				Phase: v1.NamespaceTerminating,
+			},
+		},
+		Want: `
+			kube_namespace_labels{namespace="nsStuckTerminating"} 1
+			kube_namespace_status_phase{namespace="nsStuckTerminating",phase="Active"} 0
+			kube_namespace_status_phase{namespace="nsStuckTerminating",phase="Terminating"} 1
+`,
+		Func: func(obj interface{}) []*metrics.Metric {
+			return generateNamespaceMetrics(false, nil, obj)
+		},
+	}
+	if err := c.run(); err != nil {
+		t.Errorf("unexpected collecting result:\n%s", err)
+	}
+}
+
+// fakeCountingStore is a store of known size per namespace.
+type fakeCountingStore map[string]int
+
+func (s fakeCountingStore) GetAll() []*metrics.Metric {
+	return nil
+}
+
+func (s fakeCountingStore) CountByNamespace() map[string]int {
+	return s
+}
+
+func TestNamespaceObjectCount(t *testing.T) {
+	const metadata = `
+		# HELP kube_namespace_status_object_count Number of objects per namespace and resource.
+		# TYPE kube_namespace_status_object_count gauge
+	`
+
+	// Objects of the same name in different namespaces are counted
+	// separately, objects without metrics are not counted at all.
+	configMaps := metricsstore.NewMetricsStore(func(obj interface{}) []*metrics.Metric {
+		if obj.(*v1.ConfigMap).Namespace == "ns3" {
+			return nil
+		}
+		return []*metrics.Metric{}
+	})
+	for _, ns := range []string{"ns1", "ns2", "ns3"} {
+		if err := configMaps.Add(&v1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: "config", Namespace: ns}}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	collectors := []*Collector{
+		{name: "pods", store: fakeCountingStore{"ns1": 3, "ns2": 1}},
+		{name: "services", store: fakeCountingStore{"ns1": 2}},
+		{name: "configmaps", store: configMaps},
+		{name: "nodes", store: fakeCountingStore{}},
+		{name: "other", store: &fakeStore{}},
+	}
+
+	c := generateMetricsTestCase{
+		Func: func(interface{}) []*metrics.Metric {
+			return generateNamespaceObjectCountMetrics(collectors)
+		},
+		Want: `
+			kube_namespace_status_object_count{namespace="ns1",resource="configmaps"} 1
+			kube_namespace_status_object_count{namespace="ns1",resource="pods"} 3
+			kube_namespace_status_object_count{namespace="ns1",resource="services"} 2
+			kube_namespace_status_object_count{namespace="ns2",resource="configmaps"} 1
+			kube_namespace_status_object_count{namespace="ns2",resource="pods"} 1
+`,
+	}
+	if err := c.run(); err != nil {
+		t.Errorf("unexpected collecting result:\n%s", err)
+	}
+}
@@ -0,0 +1,110 @@
+/*
+Copyright 2018 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package collectors
+
+import (
+	"k8s.io/kube-state-metrics/pkg/metrics"
+
+	networking "k8s.io/api/networking/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/watch"
+	clientset "k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/cache"
+)
+
+var (
+	descNetworkPolicyLabelsName          = "kube_networkpolicy_labels"
+	descNetworkPolicyLabelsHelp          = "Kubernetes labels converted to Prometheus labels."
+	descNetworkPolicyLabelsDefaultLabels = []string{"namespace", "networkpolicy"}
+
+	descNetworkPolicyCreated = newMetricFamilyDef(
+		"kube_networkpolicy_created",
+		"Unix creation timestamp",
+		descNetworkPolicyLabelsDefaultLabels,
+		nil,
+	)
+	descNetworkPolicyLabels = newMetricFamilyDef(
+		descNetworkPolicyLabelsName,
+		descNetworkPolicyLabelsHelp,
+		descNetworkPolicyLabelsDefaultLabels,
+		nil,
+	)
+	descNetworkPolicySpecIngressRules = newMetricFamilyDef(
+		"kube_networkpolicy_spec_ingress_rules",
+		"Number of ingress rules on the networkpolicy.",
+		descNetworkPolicyLabelsDefaultLabels,
+		nil,
+	)
+	descNetworkPolicySpecEgressRules = newMetricFamilyDef(
+		"kube_networkpolicy_spec_egress_rules",
+		"Number of egress rules on the networkpolicy.",
+		descNetworkPolicyLabelsDefaultLabels,
+		nil,
+	)
+)
+
+func createNetworkPolicyListWatch(kubeClient clientset.Interface, ns string) cache.ListWatch {
+	return cache.ListWatch{
+		ListFunc: func(opts metav1.ListOptions) (runtime.Object, error) {
+			return kubeClient.NetworkingV1().NetworkPolicies(ns).List(opts)
+		},
+		WatchFunc: func(opts metav1.ListOptions) (watch.Interface, error) {
+			return kubeClient.NetworkingV1().NetworkPolicies(ns).Watch(opts)
+		},
+	}
+}
+
+func networkPolicyLabelsDesc(labelKeys []string) *metricFamilyDef {
+	return newMetricFamilyDef(
+		descNetworkPolicyLabelsName,
+		descNetworkPolicyLabelsHelp,
+		append(descNetworkPolicyLabelsDefaultLabels, labelKeys...),
+		nil,
+	)
+}
+
+func generateNetworkPolicyMetrics(allowedLabels []string, obj interface{}) []*metrics.Metric {
+	ms := []*metrics.Metric{}
+
+	// TODO: Refactor
+	nPointer := obj.(*networking.NetworkPolicy)
+	n := *nPointer
+
+	addGauge := func(desc *metricFamilyDef, v float64, lv ...string) {
+		lv = append([]string{n.Namespace, n.Name}, lv...)
+
+		m, err := metrics.NewMetric(desc.Name, desc.LabelKeys, lv, v)
+		if err != nil {
+			panic(err)
+		}
+
+		ms = append(ms, m)
+	}
+
+	if !n.CreationTimestamp.IsZero() {
+		addGauge(descNetworkPolicyCreated, float64(n.CreationTimestamp.Unix()))
+	}
+	if labelKeys, labelValues, ok := kubeLabelsToPrometheusLabels(n.Labels, allowedLabels); ok {
+		addGauge(networkPolicyLabelsDesc(labelKeys), 1, labelValues...)
+	}
+
+	addGauge(descNetworkPolicySpecIngressRules, float64(len(n.Spec.Ingress)))
+	addGauge(descNetworkPolicySpecEgressRules, float64(len(n.Spec.Egress)))
+
+	return ms
+}
@@ -0,0 +1,103 @@
+/*
+Copyright 2018 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package collectors
+
+import (
+	"testing"
+	"time"
+
+	networking "k8s.io/api/networking/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/kube-state-metrics/pkg/metrics"
+)
+
+func TestNetworkPolicyCollector(t *testing.T) {
+	// Fixed metadata on type and help text. We prepend this to every expected
+	// output so we only have to modify a single place when doing adjustments.
+	const metadata = `
+		# HELP kube_networkpolicy_created Unix creation timestamp
+		# TYPE kube_networkpolicy_created gauge
+		# HELP kube_networkpolicy_labels Kubernetes labels converted to Prometheus labels.
+		# TYPE kube_networkpolicy_labels gauge
+		# HELP kube_networkpolicy_spec_ingress_rules Number of ingress rules on the networkpolicy.
+		# TYPE kube_networkpolicy_spec_ingress_rules gauge
+		# HELP kube_networkpolicy_spec_egress_rules Number of egress rules on the networkpolicy.
+		# TYPE kube_networkpolicy_spec_egress_rules gauge
+	`
+	cases := []generateMetricsTestCase{
+		{
+			Obj: &networking.NetworkPolicy{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:              "netpol1",
+					Namespace:         "ns1",
+					CreationTimestamp: metav1.Time{Time: time.Unix(1500000000, 0)},
+					Labels: map[string]string{
+						"app": "web",
+					},
+				},
+				Spec: networking.NetworkPolicySpec{
+					Ingress: []networking.NetworkPolicyIngressRule{
+						{
+							From: []networking.NetworkPolicyPeer{
+								{PodSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"app": "frontend"}}},
+							},
+						},
+						{
+							From: []networking.NetworkPolicyPeer{
+								{NamespaceSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"team": "ops"}}},
+							},
+						},
+					},
+					Egress: []networking.NetworkPolicyEgressRule{
+						{
+							To: []networking.NetworkPolicyPeer{
+								{IPBlock: &networking.IPBlock{CIDR: "10.0.0.0/8"}},
+							},
+						},
+					},
+				},
+			},
+			Want: `
+				kube_networkpolicy_created{namespace="ns1",networkpolicy="netpol1"} 1.5e+09
+				kube_networkpolicy_labels{label_app="web",namespace="ns1",networkpolicy="netpol1"} 1
+				kube_networkpolicy_spec_egress_rules{namespace="ns1",networkpolicy="netpol1"} 1
+				kube_networkpolicy_spec_ingress_rules{namespace="ns1",networkpolicy="netpol1"} 2
+`,
+		},
+		{
+			Obj: &networking.NetworkPolicy{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "netpol2",
+					Namespace: "ns2",
+				},
+			},
+			Want: `
+				kube_networkpolicy_labels{namespace="ns2",networkpolicy="netpol2"} 1
+				kube_networkpolicy_spec_egress_rules{namespace="ns2",networkpolicy="netpol2"} 0
+				kube_networkpolicy_spec_ingress_rules{namespace="ns2",networkpolicy="netpol2"} 0
+`,
+		},
+	}
+	for i, c := range cases {
+		c.Func = func(obj interface{}) []*metrics.Metric {
+			return generateNetworkPolicyMetrics(nil, obj)
+		}
+		if err := c.run(); err != nil {
+			t.Errorf("unexpected collecting result in %vth run:\n%s", i, err)
+		}
+	}
+}
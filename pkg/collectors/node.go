@@ -17,11 +17,16 @@ limitations under the License.
 package collectors
 
 import (
+	"time"
+
 	"k8s.io/kube-state-metrics/pkg/constant"
+	"k8s.io/kube-state-metrics/pkg/log"
 	"k8s.io/kube-state-metrics/pkg/metrics"
+	metricsstore "k8s.io/kube-state-metrics/pkg/metrics_store"
 
 	"k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/watch"
 	clientset "k8s.io/client-go/kubernetes"
@@ -76,6 +81,12 @@ var (
 		append(descNodeLabelsDefaultLabels, "condition", "status"),
 		nil,
 	)
+	descNodeStatusConditionLastTransitionTime = newMetricFamilyDef(
+		"kube_node_status_condition_last_transition_time",
+		"Unix timestamp of the last transition of a cluster node condition.",
+		append(descNodeLabelsDefaultLabels, "condition"),
+		nil,
+	)
 	descNodeStatusPhase = newMetricFamilyDef(
 		"kube_node_status_phase",
 		"The phase the node is currently in.",
@@ -130,8 +141,30 @@ var (
 		descNodeLabelsDefaultLabels,
 		nil,
 	)
+	descNodeStatusLastHeartbeatLag = newMetricFamilyDef(
+		"kube_node_status_last_heartbeat_lag_seconds",
+		"Seconds since the kubelet of the node last renewed its lease.",
+		descNodeLabelsDefaultLabels,
+		nil,
+	)
 )
 
+// nodeLeaseNamespace is the namespace of the leases the kubelets renew as
+// heartbeats, named after their nodes.
+const nodeLeaseNamespace = "kube-node-lease"
+
+// nodeStore exposes the metrics of the stored nodes along with the metrics
+// generated by heartbeatLag for the names of the stored nodes. As the
+// heartbeat lag grows with time, it is generated on every collection.
+type nodeStore struct {
+	*metricsstore.MetricsStore
+	heartbeatLag func(nodes []string) []*metrics.Metric
+}
+
+func (s nodeStore) GetAll() []*metrics.Metric {
+	return append(s.MetricsStore.GetAll(), s.heartbeatLag(s.MetricsStore.ListKeys())...)
+}
+
 func createNodeListWatch(kubeClient clientset.Interface, ns string) cache.ListWatch {
 	return cache.ListWatch{
 		ListFunc: func(opts metav1.ListOptions) (runtime.Object, error) {
@@ -143,6 +176,36 @@ func createNodeListWatch(kubeClient clientset.Interface, ns string) cache.ListWa
 	}
 }
 
+// generateNodeHeartbeatLagMetrics generates the heartbeat lag of the given
+// nodes at now from their leases in the given store of unstructured leases.
+// Nodes without lease, e.g. of clusters without node leases, are skipped.
+func generateNodeHeartbeatLagMetrics(nodes []string, leases cache.Store, now time.Time) []*metrics.Metric {
+	ms := []*metrics.Metric{}
+
+	for _, node := range nodes {
+		obj, exists, err := leases.GetByKey(nodeLeaseNamespace + "/" + node)
+		if err != nil || !exists {
+			continue
+		}
+		l := lease{}
+		if err := runtime.DefaultUnstructuredConverter.FromUnstructured(obj.(*unstructured.Unstructured).Object, &l); err != nil {
+			log.Errorf("Failed to convert lease: %v", err)
+			continue
+		}
+		if l.Spec.RenewTime == nil || l.Spec.RenewTime.IsZero() {
+			continue
+		}
+
+		m, err := metrics.NewMetric(descNodeStatusLastHeartbeatLag.Name, descNodeStatusLastHeartbeatLag.LabelKeys, []string{node}, now.Sub(l.Spec.RenewTime.Time).Seconds())
+		if err != nil {
+			panic(err)
+		}
+		ms = append(ms, m)
+	}
+
+	return ms
+}
+
 func nodeLabelsDesc(labelKeys []string) *metricFamilyDef {
 	return newMetricFamilyDef(
 		descNodeLabelsName,
@@ -152,7 +215,7 @@ func nodeLabelsDesc(labelKeys []string) *metricFamilyDef {
 	)
 }
 
-func generateNodeMetrics(disableNodeNonGenericResourceMetrics bool, obj interface{}) []*metrics.Metric {
+func generateNodeMetrics(disableNodeNonGenericResourceMetrics bool, allowedLabels []string, obj interface{}) []*metrics.Metric {
 	ms := []*metrics.Metric{}
 
 	// TODO: Refactor
@@ -182,8 +245,9 @@ func generateNodeMetrics(disableNodeNonGenericResourceMetrics bool, obj interfac
 	if !n.CreationTimestamp.IsZero() {
 		addGauge(descNodeCreated, float64(n.CreationTimestamp.Unix()))
 	}
-	labelKeys, labelValues := kubeLabelsToPrometheusLabels(n.Labels)
-	addGauge(nodeLabelsDesc(labelKeys), 1, labelValues...)
+	if labelKeys, labelValues, ok := kubeLabelsToPrometheusLabels(n.Labels, allowedLabels); ok {
+		addGauge(nodeLabelsDesc(labelKeys), 1, labelValues...)
+	}
 
 	addGauge(descNodeSpecUnschedulable, boolFloat64(n.Spec.Unschedulable))
 
@@ -202,6 +266,9 @@ func generateNodeMetrics(disableNodeNonGenericResourceMetrics bool, obj interfac
 		// (e.g. node-problem-detector), and Kubernetes may add new core
 		// conditions in future.
 		ms = append(ms, addConditionMetrics(descNodeStatusCondition, c.Status, n.Name, string(c.Type))...)
+		if !c.LastTransitionTime.IsZero() {
+			addGauge(descNodeStatusConditionLastTransitionTime, float64(c.LastTransitionTime.Unix()), string(c.Type))
+		}
 	}
 
 	// Set current phase to 1, others to 0 if it is set.
@@ -23,6 +23,8 @@ import (
 	"k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/client-go/tools/cache"
 	"k8s.io/kube-state-metrics/pkg/metrics"
 )
 
@@ -60,6 +62,8 @@ func TestNodeCollector(t *testing.T) {
 		# HELP kube_node_status_allocatable_memory_bytes The memory resources of a node that are available for scheduling.
 		# HELP kube_node_status_condition The condition of a cluster node.
 		# TYPE kube_node_status_condition gauge
+		# HELP kube_node_status_condition_last_transition_time Unix timestamp of the last transition of a cluster node condition.
+		# TYPE kube_node_status_condition_last_transition_time gauge
 	`
 	cases := []generateMetricsTestCase{
 		// Verify populating base metrics and that metrics for unset fields are skipped.
@@ -301,13 +305,144 @@ func TestNodeCollector(t *testing.T) {
 			`,
 			MetricNames: []string{"kube_node_spec_taint"},
 		},
+		{
+			Obj: &v1.Node{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: "127.0.0.2",
+				},
+				Spec: v1.NodeSpec{
+					Taints: []v1.Taint{
+						{Key: "node-role.kubernetes.io/master", Effect: v1.TaintEffectNoSchedule},
+						{Key: "node.kubernetes.io/unreachable", Value: "true", Effect: v1.TaintEffectNoExecute},
+					},
+				},
+			},
+			Want: `
+				kube_node_spec_taint{effect="NoExecute",key="node.kubernetes.io/unreachable",node="127.0.0.2",value="true"} 1
+				kube_node_spec_taint{effect="NoSchedule",key="node-role.kubernetes.io/master",node="127.0.0.2",value=""} 1
+			`,
+			MetricNames: []string{"kube_node_spec_taint"},
+		},
+		{
+			Obj: &v1.Node{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: "127.0.0.4",
+				},
+				Status: v1.NodeStatus{
+					Conditions: []v1.NodeCondition{
+						{Type: v1.NodeReady, Status: v1.ConditionFalse, LastTransitionTime: metav1.Time{Time: time.Unix(1500000000, 0)}},
+						{Type: v1.NodeMemoryPressure, Status: v1.ConditionTrue, LastTransitionTime: metav1.Time{Time: time.Unix(1500000100, 0)}},
+						{Type: v1.NodeDiskPressure, Status: v1.ConditionFalse, LastTransitionTime: metav1.Time{Time: time.Unix(1400000000, 0)}},
+					},
+				},
+			},
+			Want: `
+        kube_node_status_condition_last_transition_time{condition="DiskPressure",node="127.0.0.4"} 1.4e+09
+        kube_node_status_condition_last_transition_time{condition="MemoryPressure",node="127.0.0.4"} 1.5000001e+09
+        kube_node_status_condition_last_transition_time{condition="Ready",node="127.0.0.4"} 1.5e+09
+`,
+			MetricNames: []string{"kube_node_status_condition_last_transition_time"},
+		},
 	}
 	for i, c := range cases {
 		c.Func = func(obj interface{}) []*metrics.Metric {
-			return generateNodeMetrics(false, obj)
+			return generateNodeMetrics(false, nil, obj)
 		}
 		if err := c.run(); err != nil {
 			t.Errorf("unexpected collecting result in %vth run:\n%s", i, err)
 		}
 	}
 }
+
+func TestNodeCollectorNonGenericResourceMetricsDisabled(t *testing.T) {
+	c := generateMetricsTestCase{
+		Obj: &v1.Node{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: "gpu-node",
+			},
+			Status: v1.NodeStatus{
+				Capacity: v1.ResourceList{
+					v1.ResourceCPU:                    resource.MustParse("8"),
+					v1.ResourceMemory:                 resource.MustParse("32G"),
+					v1.ResourcePods:                   resource.MustParse("110"),
+					v1.ResourceEphemeralStorage:       resource.MustParse("100G"),
+					v1.ResourceName("nvidia.com/gpu"): resource.MustParse("2"),
+				},
+				Allocatable: v1.ResourceList{
+					v1.ResourceCPU:                    resource.MustParse("7.5"),
+					v1.ResourceMemory:                 resource.MustParse("30G"),
+					v1.ResourcePods:                   resource.MustParse("110"),
+					v1.ResourceEphemeralStorage:       resource.MustParse("90G"),
+					v1.ResourceName("nvidia.com/gpu"): resource.MustParse("2"),
+				},
+			},
+		},
+		Want: `
+			kube_node_status_allocatable{node="gpu-node",resource="cpu",unit="core"} 7.5
+			kube_node_status_allocatable{node="gpu-node",resource="ephemeral_storage",unit="byte"} 9e+10
+			kube_node_status_allocatable{node="gpu-node",resource="memory",unit="byte"} 3e+10
+			kube_node_status_allocatable{node="gpu-node",resource="nvidia_com_gpu",unit="integer"} 2
+			kube_node_status_allocatable{node="gpu-node",resource="pods",unit="integer"} 110
+			kube_node_status_capacity{node="gpu-node",resource="cpu",unit="core"} 8
+			kube_node_status_capacity{node="gpu-node",resource="ephemeral_storage",unit="byte"} 1e+11
+			kube_node_status_capacity{node="gpu-node",resource="memory",unit="byte"} 3.2e+10
+			kube_node_status_capacity{node="gpu-node",resource="nvidia_com_gpu",unit="integer"} 2
+			kube_node_status_capacity{node="gpu-node",resource="pods",unit="integer"} 110
+`,
+		MetricNames: []string{
+			"kube_node_status_allocatable",
+			"kube_node_status_allocatable_cpu_cores",
+			"kube_node_status_allocatable_memory_bytes",
+			"kube_node_status_allocatable_pods",
+			"kube_node_status_capacity",
+			"kube_node_status_capacity_cpu_cores",
+			"kube_node_status_capacity_memory_bytes",
+			"kube_node_status_capacity_pods",
+		},
+		Func: func(obj interface{}) []*metrics.Metric {
+			return generateNodeMetrics(true, nil, obj)
+		},
+	}
+	if err := c.run(); err != nil {
+		t.Errorf("unexpected collecting result:\n%s", err)
+	}
+}
+
+func TestNodeHeartbeatLagMetrics(t *testing.T) {
+	const metadata = `
+		# HELP kube_node_status_last_heartbeat_lag_seconds Seconds since the kubelet of the node last renewed its lease.
+		# TYPE kube_node_status_last_heartbeat_lag_seconds gauge
+	`
+	now := time.Unix(1500000300, 0)
+	leases := cache.NewStore(cache.MetaNamespaceKeyFunc)
+	for name, renewTime := range map[string]string{
+		"node1": "2017-07-14T02:44:50.000000Z",
+		// Stale lease of a node whose kubelet stopped heartbeating.
+		"node2": "2017-07-14T02:40:00.000000Z",
+		// Lease of a node not in the store, e.g. of another shard.
+		"node4": "2017-07-14T02:44:50.000000Z",
+	} {
+		leases.Add(&unstructured.Unstructured{Object: map[string]interface{}{
+			"apiVersion": "coordination.k8s.io/v1",
+			"kind":       "Lease",
+			"metadata":   map[string]interface{}{"namespace": nodeLeaseNamespace, "name": name},
+			"spec":       map[string]interface{}{"holderIdentity": name, "renewTime": renewTime},
+		}})
+	}
+
+	// node3 has no lease.
+	c := generateMetricsTestCase{
+		// node3 has no lease.
+		Obj: []string{"node1", "node2", "node3"},
+		Want: `
+			kube_node_status_last_heartbeat_lag_seconds{node="node1"} 10
+			kube_node_status_last_heartbeat_lag_seconds{node="node2"} 300
+		`,
+		Func: func(obj interface{}) []*metrics.Metric {
+			return generateNodeHeartbeatLagMetrics(obj.([]string), leases, now)
+		},
+	}
+	if err := c.run(); err != nil {
+		t.Errorf("unexpected collecting result:\n%s", err)
+	}
+}
@@ -0,0 +1,160 @@
+/*
+Copyright 2018 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package collectors
+
+import (
+	"sort"
+
+	"k8s.io/kube-state-metrics/pkg/customresource"
+	"k8s.io/kube-state-metrics/pkg/log"
+
+	authorization "k8s.io/api/authorization/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// collectorResources maps the collectors to the resource they list and watch.
+var collectorResources = map[string]customresource.Resource{
+	"apiservices":                     apiServiceResource,
+	"clusterrolebindings":             {Group: "rbac.authorization.k8s.io", Version: "v1", Resource: "clusterrolebindings"},
+	"clusterroles":                    {Group: "rbac.authorization.k8s.io", Version: "v1", Resource: "clusterroles"},
+	"configmaps":                      {Version: "v1", Resource: "configmaps", Namespaced: true},
+	"cronjobs":                        {Group: "batch", Version: "v1beta1", Resource: "cronjobs", Namespaced: true},
+	"daemonsets":                      {Group: "extensions", Version: "v1beta1", Resource: "daemonsets", Namespaced: true},
+	"deployments":                     {Group: "extensions", Version: "v1beta1", Resource: "deployments", Namespaced: true},
+	"endpoints":                       {Version: "v1", Resource: "endpoints", Namespaced: true},
+	"endpointslices":                  endpointSliceResource,
+	"horizontalpodautoscalers":        {Group: "autoscaling", Version: "v2beta1", Resource: "horizontalpodautoscalers", Namespaced: true},
+	"jobs":                            {Group: "batch", Version: "v1", Resource: "jobs", Namespaced: true},
+	"leases":                          leaseResource,
+	"limitranges":                     {Version: "v1", Resource: "limitranges", Namespaced: true},
+	"mutatingwebhookconfigurations":   {Group: "admissionregistration.k8s.io", Version: "v1beta1", Resource: "mutatingwebhookconfigurations"},
+	"namespaces":                      {Version: "v1", Resource: "namespaces"},
+	"networkpolicies":                 {Group: "networking.k8s.io", Version: "v1", Resource: "networkpolicies", Namespaced: true},
+	"nodes":                           {Version: "v1", Resource: "nodes"},
+	"persistentvolumeclaims":          {Version: "v1", Resource: "persistentvolumeclaims", Namespaced: true},
+	"persistentvolumes":               {Version: "v1", Resource: "persistentvolumes"},
+	"poddisruptionbudgets":            {Group: "policy", Version: "v1beta1", Resource: "poddisruptionbudgets", Namespaced: true},
+	"pods":                            {Version: "v1", Resource: "pods", Namespaced: true},
+	"priorityclasses":                 {Group: "scheduling.k8s.io", Version: "v1beta1", Resource: "priorityclasses"},
+	"replicasets":                     {Group: "extensions", Version: "v1beta1", Resource: "replicasets", Namespaced: true},
+	"replicationcontrollers":          {Version: "v1", Resource: "replicationcontrollers", Namespaced: true},
+	"resourcequotas":                  {Version: "v1", Resource: "resourcequotas", Namespaced: true},
+	"runtimeclasses":                  runtimeClassResource,
+	"secrets":                         {Version: "v1", Resource: "secrets", Namespaced: true},
+	"services":                        {Version: "v1", Resource: "services", Namespaced: true},
+	"statefulsets":                    {Group: "apps", Version: "v1beta1", Resource: "statefulsets", Namespaced: true},
+	"validatingwebhookconfigurations": {Group: "admissionregistration.k8s.io", Version: "v1beta1", Resource: "validatingwebhookconfigurations"},
+	"verticalpodautoscalers":          verticalPodAutoscalerResource,
+	"volumeattachments":               {Group: "storage.k8s.io", Version: "v1beta1", Resource: "volumeattachments"},
+}
+
+// CheckPermissions reviews whether kube-state-metrics is permitted to list
+// and watch the resources of the enabled collectors and custom resources in
+// the watched namespaces of every added cluster. Denied permissions are
+// logged as warnings and counted, to surface RBAC misconfigurations on
+// startup instead of as failing list and watch requests later on.
+func (b *Builder) CheckPermissions() {
+	for _, cb := range b.clusterBuilders() {
+		cb.checkPermissions()
+	}
+}
+
+func (b *Builder) checkPermissions() {
+	names := []string{}
+	for c := range b.enabledCollectors {
+		if r, ok := collectorResources[c]; ok && b.inResourceScope(r) {
+			names = append(names, c)
+		}
+	}
+	sort.Strings(names)
+
+	for _, c := range names {
+		b.checkResourcePermissions(c, collectorResources[c])
+	}
+	if b.customResources != nil {
+		for _, r := range b.customResources.Resources {
+			if !b.inResourceScope(r) {
+				continue
+			}
+			b.checkResourcePermissions(customResourceCollectorName(r), r)
+		}
+	}
+}
+
+// checkResourcePermissions reviews the list and watch permissions on the
+// given resource, which is counted as the given collector.
+func (b *Builder) checkResourcePermissions(collector string, r customresource.Resource) {
+	namespaces := []string{metav1.NamespaceAll}
+	if r.Namespaced {
+		namespaces = b.namespaces
+	}
+
+	for _, ns := range namespaces {
+		for _, verb := range []string{"list", "watch"} {
+			allowed, err := b.reviewPermission(r, ns, verb)
+			if err != nil {
+				log.Errorf("Failed to review the %s permission on %s: %v", verb, collector, err)
+				continue
+			}
+			if allowed {
+				continue
+			}
+
+			PermissionDeniedTotalMetric.WithLabelValues(collector, verb).Inc()
+			where := "cluster-wide"
+			if ns != metav1.NamespaceAll {
+				where = "in namespace " + ns
+			}
+			if b.cluster != "" {
+				where += " of cluster " + b.cluster
+			}
+			log.Warningf("Not permitted to %s %s %s, check the RBAC rules of the service account", verb, collector, where)
+		}
+	}
+}
+
+// permitted reports whether kube-state-metrics is permitted to list and watch
+// the given resource in the given namespace. Failing reviews are treated as
+// denied.
+func (b *Builder) permitted(r customresource.Resource, ns string) bool {
+	for _, verb := range []string{"list", "watch"} {
+		if allowed, err := b.reviewPermission(r, ns, verb); err != nil || !allowed {
+			return false
+		}
+	}
+	return true
+}
+
+// reviewPermission reviews whether kube-state-metrics is permitted to use the
+// given verb on the given resource in the given namespace.
+func (b *Builder) reviewPermission(r customresource.Resource, ns, verb string) (bool, error) {
+	review, err := b.kubeClient.AuthorizationV1().SelfSubjectAccessReviews().Create(&authorization.SelfSubjectAccessReview{
+		Spec: authorization.SelfSubjectAccessReviewSpec{
+			ResourceAttributes: &authorization.ResourceAttributes{
+				Namespace: ns,
+				Verb:      verb,
+				Group:     r.Group,
+				Version:   r.Version,
+				Resource:  r.Resource,
+			},
+		},
+	})
+	if err != nil {
+		return false, err
+	}
+	return review.Status.Allowed, nil
+}
@@ -0,0 +1,89 @@
+/*
+Copyright 2018 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package collectors
+
+import (
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+	"golang.org/x/net/context"
+	authorization "k8s.io/api/authorization/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+	"k8s.io/kube-state-metrics/pkg/options"
+)
+
+func TestCheckPermissions(t *testing.T) {
+	kubeClient := fake.NewSimpleClientset()
+	reviewed := []authorization.ResourceAttributes{}
+	kubeClient.PrependReactor("create", "selfsubjectaccessreviews", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		review := action.(k8stesting.CreateAction).GetObject().(*authorization.SelfSubjectAccessReview)
+		attrs := *review.Spec.ResourceAttributes
+		reviewed = append(reviewed, attrs)
+		// Deny all access to secrets and watching nodes.
+		review.Status.Allowed = attrs.Resource != "secrets" && !(attrs.Resource == "nodes" && attrs.Verb == "watch")
+		return true, review, nil
+	})
+
+	b := NewBuilder(context.TODO(), options.NewOptions())
+	b.WithEnabledCollectors(options.CollectorSet{"pods": struct{}{}, "secrets": struct{}{}, "nodes": struct{}{}})
+	b.WithKubeClient(kubeClient)
+	b.WithNamespaces(options.NamespaceList{"default", "kube-system"})
+	b.CheckPermissions()
+
+	// list and watch for nodes once, for pods and secrets per namespace.
+	if len(reviewed) != 10 {
+		t.Fatalf("expected 10 access reviews, got %d: %v", len(reviewed), reviewed)
+	}
+	for _, attrs := range reviewed {
+		if attrs.Resource == "nodes" && attrs.Namespace != "" {
+			t.Errorf("expected cluster-wide review of nodes, got namespace %q", attrs.Namespace)
+		}
+	}
+
+	tests := []struct {
+		Resource     string
+		Verb         string
+		WantedDenied float64
+	}{
+		{Resource: "pods", Verb: "list", WantedDenied: 0},
+		{Resource: "pods", Verb: "watch", WantedDenied: 0},
+		{Resource: "secrets", Verb: "list", WantedDenied: 2},
+		{Resource: "secrets", Verb: "watch", WantedDenied: 2},
+		{Resource: "nodes", Verb: "list", WantedDenied: 0},
+		{Resource: "nodes", Verb: "watch", WantedDenied: 1},
+	}
+	for _, test := range tests {
+		m := &dto.Metric{}
+		if err := PermissionDeniedTotalMetric.WithLabelValues(test.Resource, test.Verb).(prometheus.Metric).Write(m); err != nil {
+			t.Fatal(err)
+		}
+		if got := m.GetCounter().GetValue(); got != test.WantedDenied {
+			t.Errorf("expected %v denied %s permissions on %s, got %v", test.WantedDenied, test.Verb, test.Resource, got)
+		}
+	}
+}
+
+func TestCollectorResources(t *testing.T) {
+	for c := range availableCollectors {
+		if r, ok := collectorResources[c]; !ok || r.Resource != c {
+			t.Errorf("expected the resource of collector %s in collectorResources", c)
+		}
+	}
+}
@@ -17,6 +17,8 @@ limitations under the License.
 package collectors
 
 import (
+	"strings"
+
 	"k8s.io/kube-state-metrics/pkg/metrics"
 
 	"k8s.io/api/core/v1"
@@ -38,6 +40,12 @@ var (
 		descPersistentVolumeLabelsDefaultLabels,
 		nil,
 	)
+	descPersistentVolumeCreated = newMetricFamilyDef(
+		"kube_persistentvolume_created",
+		"Unix creation timestamp",
+		descPersistentVolumeLabelsDefaultLabels,
+		nil,
+	)
 	descPersistentVolumeStatusPhase = newMetricFamilyDef(
 		"kube_persistentvolume_status_phase",
 		"The phase indicates if a volume is available, bound to a claim, or released by a claim.",
@@ -47,7 +55,19 @@ var (
 	descPersistentVolumeInfo = newMetricFamilyDef(
 		"kube_persistentvolume_info",
 		"Information about persistentvolume.",
-		append(descPersistentVolumeLabelsDefaultLabels, "storageclass"),
+		append(descPersistentVolumeLabelsDefaultLabels, "storageclass", "reclaim_policy", "access_modes"),
+		nil,
+	)
+	descPersistentVolumeCapacityBytes = newMetricFamilyDef(
+		"kube_persistentvolume_capacity_bytes",
+		"Persistentvolume capacity in bytes.",
+		descPersistentVolumeLabelsDefaultLabels,
+		nil,
+	)
+	descPersistentVolumeClaimRef = newMetricFamilyDef(
+		"kube_persistentvolume_claim_ref",
+		"Information about the Persistent Volume Claim Reference.",
+		append(descPersistentVolumeLabelsDefaultLabels, "claim_namespace", "persistentvolumeclaim"),
 		nil,
 	)
 )
@@ -72,7 +92,7 @@ func persistentVolumeLabelsDesc(labelKeys []string) *metricFamilyDef {
 	)
 }
 
-func generatePersistentVolumeMetrics(obj interface{}) []*metrics.Metric {
+func generatePersistentVolumeMetrics(allowedLabels []string, obj interface{}) []*metrics.Metric {
 	ms := []*metrics.Metric{}
 
 	// TODO: Refactor
@@ -90,10 +110,30 @@ func generatePersistentVolumeMetrics(obj interface{}) []*metrics.Metric {
 		ms = append(ms, m)
 	}
 
-	labelKeys, labelValues := kubeLabelsToPrometheusLabels(p.Labels)
-	addGauge(persistentVolumeLabelsDesc(labelKeys), 1, labelValues...)
+	if labelKeys, labelValues, ok := kubeLabelsToPrometheusLabels(p.Labels, allowedLabels); ok {
+		addGauge(persistentVolumeLabelsDesc(labelKeys), 1, labelValues...)
+	}
+
+	if !p.CreationTimestamp.IsZero() {
+		addGauge(descPersistentVolumeCreated, float64(p.CreationTimestamp.Unix()))
+	}
+
+	accessModes := []string{}
+	for _, mode := range p.Spec.AccessModes {
+		accessModes = append(accessModes, string(mode))
+	}
+	addGauge(descPersistentVolumeInfo, 1, p.Spec.StorageClassName, string(p.Spec.PersistentVolumeReclaimPolicy), strings.Join(accessModes, ","))
+
+	if storage, ok := p.Spec.Capacity[v1.ResourceStorage]; ok {
+		addGauge(descPersistentVolumeCapacityBytes, float64(storage.Value()))
+	}
+
+	// Volumes never bound to a claim have no claim reference. Released
+	// volumes keep the reference to their former claim.
+	if ref := p.Spec.ClaimRef; ref != nil {
+		addGauge(descPersistentVolumeClaimRef, 1, ref.Namespace, ref.Name)
+	}
 
-	addGauge(descPersistentVolumeInfo, 1, p.Spec.StorageClassName)
 	// Set current phase to 1, others to 0 if it is set.
 	if p := p.Status.Phase; p != "" {
 		addGauge(descPersistentVolumeStatusPhase, boolFloat64(p == v1.VolumePending), string(v1.VolumePending))
@@ -18,9 +18,12 @@ package collectors
 
 import (
 	"testing"
+	"time"
 
 	"k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/kube-state-metrics/pkg/metrics"
 )
 
 func TestPersistentVolumeCollector(t *testing.T) {
@@ -33,13 +36,20 @@ func TestPersistentVolumeCollector(t *testing.T) {
 			# TYPE kube_persistentvolume_labels gauge
 			# HELP kube_persistentvolume_info Information about persistentvolume.
 			# TYPE kube_persistentvolume_info gauge
+			# HELP kube_persistentvolume_capacity_bytes Persistentvolume capacity in bytes.
+			# TYPE kube_persistentvolume_capacity_bytes gauge
+			# HELP kube_persistentvolume_claim_ref Information about the Persistent Volume Claim Reference.
+			# TYPE kube_persistentvolume_claim_ref gauge
+			# HELP kube_persistentvolume_created Unix creation timestamp
+			# TYPE kube_persistentvolume_created gauge
 	`
 	cases := []generateMetricsTestCase{
 		// Verify phase enumerations.
 		{
 			Obj: &v1.PersistentVolume{
 				ObjectMeta: metav1.ObjectMeta{
-					Name: "test-pv-pending",
+					Name:              "test-pv-pending",
+					CreationTimestamp: metav1.Time{Time: time.Unix(1500000000, 0)},
 				},
 				Status: v1.PersistentVolumeStatus{
 					Phase: v1.VolumePending,
@@ -51,9 +61,11 @@ func TestPersistentVolumeCollector(t *testing.T) {
 					kube_persistentvolume_status_phase{persistentvolume="test-pv-pending",phase="Failed"} 0
 					kube_persistentvolume_status_phase{persistentvolume="test-pv-pending",phase="Pending"} 1
 					kube_persistentvolume_status_phase{persistentvolume="test-pv-pending",phase="Released"} 0
+					kube_persistentvolume_created{persistentvolume="test-pv-pending"} 1.5e+09
 `,
 			MetricNames: []string{
 				"kube_persistentvolume_status_phase",
+				"kube_persistentvolume_created",
 			},
 		},
 		{
@@ -162,7 +174,7 @@ func TestPersistentVolumeCollector(t *testing.T) {
 				},
 			},
 			Want: `
-					kube_persistentvolume_info{persistentvolume="test-pv-available",storageclass=""} 1
+					kube_persistentvolume_info{access_modes="",persistentvolume="test-pv-available",reclaim_policy="",storageclass=""} 1
 				`,
 			MetricNames: []string{"kube_persistentvolume_info"},
 		},
@@ -200,9 +212,114 @@ func TestPersistentVolumeCollector(t *testing.T) {
 				`,
 			MetricNames: []string{"kube_persistentvolume_labels"},
 		},
+		// Verify capacity, info and claim reference over the life cycle of a
+		// volume.
+		{
+			Obj: &v1.PersistentVolume{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: "test-pv-unclaimed",
+				},
+				Spec: v1.PersistentVolumeSpec{
+					StorageClassName:              "standard",
+					PersistentVolumeReclaimPolicy: v1.PersistentVolumeReclaimRetain,
+					AccessModes:                   []v1.PersistentVolumeAccessMode{v1.ReadWriteOnce},
+					Capacity: v1.ResourceList{
+						v1.ResourceStorage: resource.MustParse("5Gi"),
+					},
+				},
+				Status: v1.PersistentVolumeStatus{
+					Phase: v1.VolumeAvailable,
+				},
+			},
+			Want: `
+					kube_persistentvolume_capacity_bytes{persistentvolume="test-pv-unclaimed"} 5.36870912e+09
+					kube_persistentvolume_info{access_modes="ReadWriteOnce",persistentvolume="test-pv-unclaimed",reclaim_policy="Retain",storageclass="standard"} 1
+				`,
+			MetricNames: []string{
+				"kube_persistentvolume_capacity_bytes",
+				"kube_persistentvolume_info",
+				"kube_persistentvolume_claim_ref",
+			},
+		},
+		{
+			Obj: &v1.PersistentVolume{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: "test-pv-bound",
+				},
+				Spec: v1.PersistentVolumeSpec{
+					StorageClassName:              "standard",
+					PersistentVolumeReclaimPolicy: v1.PersistentVolumeReclaimDelete,
+					AccessModes:                   []v1.PersistentVolumeAccessMode{v1.ReadWriteOnce, v1.ReadOnlyMany},
+					Capacity: v1.ResourceList{
+						v1.ResourceStorage: resource.MustParse("1Gi"),
+					},
+					ClaimRef: &v1.ObjectReference{
+						Kind:      "PersistentVolumeClaim",
+						Namespace: "default",
+						Name:      "mysql-data",
+					},
+				},
+				Status: v1.PersistentVolumeStatus{
+					Phase: v1.VolumeBound,
+				},
+			},
+			Want: `
+					kube_persistentvolume_capacity_bytes{persistentvolume="test-pv-bound"} 1.073741824e+09
+					kube_persistentvolume_claim_ref{claim_namespace="default",persistentvolume="test-pv-bound",persistentvolumeclaim="mysql-data"} 1
+					kube_persistentvolume_info{access_modes="ReadWriteOnce,ReadOnlyMany",persistentvolume="test-pv-bound",reclaim_policy="Delete",storageclass="standard"} 1
+					kube_persistentvolume_status_phase{persistentvolume="test-pv-bound",phase="Available"} 0
+					kube_persistentvolume_status_phase{persistentvolume="test-pv-bound",phase="Bound"} 1
+					kube_persistentvolume_status_phase{persistentvolume="test-pv-bound",phase="Failed"} 0
+					kube_persistentvolume_status_phase{persistentvolume="test-pv-bound",phase="Pending"} 0
+					kube_persistentvolume_status_phase{persistentvolume="test-pv-bound",phase="Released"} 0
+				`,
+			MetricNames: []string{
+				"kube_persistentvolume_capacity_bytes",
+				"kube_persistentvolume_info",
+				"kube_persistentvolume_claim_ref",
+				"kube_persistentvolume_status_phase",
+			},
+		},
+		{
+			Obj: &v1.PersistentVolume{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: "test-pv-released",
+				},
+				Spec: v1.PersistentVolumeSpec{
+					StorageClassName:              "standard",
+					PersistentVolumeReclaimPolicy: v1.PersistentVolumeReclaimRetain,
+					AccessModes:                   []v1.PersistentVolumeAccessMode{v1.ReadWriteOnce},
+					ClaimRef: &v1.ObjectReference{
+						Kind:      "PersistentVolumeClaim",
+						Namespace: "default",
+						Name:      "mysql-data",
+					},
+				},
+				Status: v1.PersistentVolumeStatus{
+					Phase: v1.VolumeReleased,
+				},
+			},
+			Want: `
+					kube_persistentvolume_claim_ref{claim_namespace="default",persistentvolume="test-pv-released",persistentvolumeclaim="mysql-data"} 1
+					kube_persistentvolume_info{access_modes="ReadWriteOnce",persistentvolume="test-pv-released",reclaim_policy="Retain",storageclass="standard"} 1
+					kube_persistentvolume_status_phase{persistentvolume="test-pv-released",phase="Available"} 0
+					kube_persistentvolume_status_phase{persistentvolume="test-pv-released",phase="Bound"} 0
+					kube_persistentvolume_status_phase{persistentvolume="test-pv-released",phase="Failed"} 0
+					kube_persistentvolume_status_phase{persistentvolume="test-pv-released",phase="Pending"} 0
+					kube_persistentvolume_status_phase{persistentvolume="test-pv-released",phase="Released"} 1
+				`,
+			MetricNames: []string{
+				"kube_persistentvolume_capacity_bytes",
+				"kube_persistentvolume_info",
+				"kube_persistentvolume_claim_ref",
+				"kube_persistentvolume_status_phase",
+			},
+		},
 	}
 	for i, c := range cases {
-		c.Func = generatePersistentVolumeMetrics
+		c.Func = func(obj interface{}) []*metrics.Metric {
+			return generatePersistentVolumeMetrics(nil, obj)
+		}
 		if err := c.run(); err != nil {
 			t.Errorf("unexpected collecting result in %vth run:\n%s", i, err)
 		}
@@ -38,6 +38,12 @@ var (
 		descPersistentVolumeClaimLabelsDefaultLabels,
 		nil,
 	)
+	descPersistentVolumeClaimCreated = newMetricFamilyDef(
+		"kube_persistentvolumeclaim_created",
+		"Unix creation timestamp",
+		descPersistentVolumeClaimLabelsDefaultLabels,
+		nil,
+	)
 	descPersistentVolumeClaimInfo = newMetricFamilyDef(
 		"kube_persistentvolumeclaim_info",
 		"Information about persistent volume claim.",
@@ -56,6 +62,12 @@ var (
 		descPersistentVolumeClaimLabelsDefaultLabels,
 		nil,
 	)
+	descPersistentVolumeClaimStatusCapacity = newMetricFamilyDef(
+		"kube_persistentvolumeclaim_status_capacity_bytes",
+		"The capacity of storage provisioned for the persistent volume claim.",
+		descPersistentVolumeClaimLabelsDefaultLabels,
+		nil,
+	)
 )
 
 func createPersistentVolumeClaimListWatch(kubeClient clientset.Interface, ns string) cache.ListWatch {
@@ -93,7 +105,7 @@ func getPersistentVolumeClaimClass(claim *v1.PersistentVolumeClaim) string {
 	return "<none>"
 }
 
-func generatePersistentVolumeClaimMetrics(obj interface{}) []*metrics.Metric {
+func generatePersistentVolumeClaimMetrics(allowedLabels []string, obj interface{}) []*metrics.Metric {
 	ms := []*metrics.Metric{}
 
 	// TODO: Refactor
@@ -111,8 +123,13 @@ func generatePersistentVolumeClaimMetrics(obj interface{}) []*metrics.Metric {
 		ms = append(ms, m)
 	}
 
-	labelKeys, labelValues := kubeLabelsToPrometheusLabels(p.Labels)
-	addGauge(persistentVolumeClaimLabelsDesc(labelKeys), 1, labelValues...)
+	if labelKeys, labelValues, ok := kubeLabelsToPrometheusLabels(p.Labels, allowedLabels); ok {
+		addGauge(persistentVolumeClaimLabelsDesc(labelKeys), 1, labelValues...)
+	}
+
+	if !p.CreationTimestamp.IsZero() {
+		addGauge(descPersistentVolumeClaimCreated, float64(p.CreationTimestamp.Unix()))
+	}
 
 	storageClassName := getPersistentVolumeClaimClass(&p)
 	volumeName := p.Spec.VolumeName
@@ -129,5 +146,9 @@ func generatePersistentVolumeClaimMetrics(obj interface{}) []*metrics.Metric {
 		addGauge(descPersistentVolumeClaimResourceRequestsStorage, float64(storage.Value()))
 	}
 
+	if storage, ok := p.Status.Capacity[v1.ResourceStorage]; ok {
+		addGauge(descPersistentVolumeClaimStatusCapacity, float64(storage.Value()))
+	}
+
 	return ms
 }
@@ -18,10 +18,12 @@ package collectors
 
 import (
 	"testing"
+	"time"
 
 	"k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/kube-state-metrics/pkg/metrics"
 )
 
 func TestPersistentVolumeClaimCollector(t *testing.T) {
@@ -36,6 +38,10 @@ func TestPersistentVolumeClaimCollector(t *testing.T) {
 		# TYPE kube_persistentvolumeclaim_status_phase gauge
 		# HELP kube_persistentvolumeclaim_resource_requests_storage_bytes The capacity of storage requested by the persistent volume claim.
 		# TYPE kube_persistentvolumeclaim_resource_requests_storage_bytes gauge
+		# HELP kube_persistentvolumeclaim_status_capacity_bytes The capacity of storage provisioned for the persistent volume claim.
+		# TYPE kube_persistentvolumeclaim_status_capacity_bytes gauge
+		# HELP kube_persistentvolumeclaim_created Unix creation timestamp
+		# TYPE kube_persistentvolumeclaim_created gauge
 	`
 	storageClassName := "rbd"
 	cases := []generateMetricsTestCase{
@@ -43,8 +49,9 @@ func TestPersistentVolumeClaimCollector(t *testing.T) {
 		{
 			Obj: &v1.PersistentVolumeClaim{
 				ObjectMeta: metav1.ObjectMeta{
-					Name:      "mysql-data",
-					Namespace: "default",
+					Name:              "mysql-data",
+					Namespace:         "default",
+					CreationTimestamp: metav1.Time{Time: time.Unix(1500000000, 0)},
 					Labels: map[string]string{
 						"app": "mysql-server",
 					},
@@ -60,6 +67,9 @@ func TestPersistentVolumeClaimCollector(t *testing.T) {
 				},
 				Status: v1.PersistentVolumeClaimStatus{
 					Phase: v1.ClaimBound,
+					Capacity: v1.ResourceList{
+						v1.ResourceStorage: resource.MustParse("2Gi"),
+					},
 				},
 			},
 			Want: `
@@ -68,9 +78,11 @@ func TestPersistentVolumeClaimCollector(t *testing.T) {
 				kube_persistentvolumeclaim_status_phase{namespace="default",persistentvolumeclaim="mysql-data",phase="Lost"} 0
 				kube_persistentvolumeclaim_status_phase{namespace="default",persistentvolumeclaim="mysql-data",phase="Pending"} 0
 				kube_persistentvolumeclaim_resource_requests_storage_bytes{namespace="default",persistentvolumeclaim="mysql-data"} 1.073741824e+09
+				kube_persistentvolumeclaim_status_capacity_bytes{namespace="default",persistentvolumeclaim="mysql-data"} 2.147483648e+09
 				kube_persistentvolumeclaim_labels{label_app="mysql-server",namespace="default",persistentvolumeclaim="mysql-data"} 1
+				kube_persistentvolumeclaim_created{namespace="default",persistentvolumeclaim="mysql-data"} 1.5e+09
 `,
-			MetricNames: []string{"kube_persistentvolumeclaim_info", "kube_persistentvolumeclaim_status_phase", "kube_persistentvolumeclaim_resource_requests_storage_bytes", "kube_persistentvolumeclaim_labels"},
+			MetricNames: []string{"kube_persistentvolumeclaim_created", "kube_persistentvolumeclaim_info", "kube_persistentvolumeclaim_status_phase", "kube_persistentvolumeclaim_resource_requests_storage_bytes", "kube_persistentvolumeclaim_status_capacity_bytes", "kube_persistentvolumeclaim_labels"},
 		},
 		{
 			Obj: &v1.PersistentVolumeClaim{
@@ -80,7 +92,12 @@ func TestPersistentVolumeClaimCollector(t *testing.T) {
 				},
 				Spec: v1.PersistentVolumeClaimSpec{
 					StorageClassName: &storageClassName,
-					VolumeName:       "pvc-prometheus-data",
+					Resources: v1.ResourceRequirements{
+						Requests: v1.ResourceList{
+							v1.ResourceStorage: resource.MustParse("10Gi"),
+						},
+					},
+					VolumeName: "pvc-prometheus-data",
 				},
 				Status: v1.PersistentVolumeClaimStatus{
 					Phase: v1.ClaimPending,
@@ -91,9 +108,10 @@ func TestPersistentVolumeClaimCollector(t *testing.T) {
 				kube_persistentvolumeclaim_status_phase{namespace="default",persistentvolumeclaim="prometheus-data",phase="Bound"} 0
 				kube_persistentvolumeclaim_status_phase{namespace="default",persistentvolumeclaim="prometheus-data",phase="Lost"} 0
 				kube_persistentvolumeclaim_status_phase{namespace="default",persistentvolumeclaim="prometheus-data",phase="Pending"} 1
+				kube_persistentvolumeclaim_resource_requests_storage_bytes{namespace="default",persistentvolumeclaim="prometheus-data"} 1.073741824e+10
 				kube_persistentvolumeclaim_labels{namespace="default",persistentvolumeclaim="prometheus-data"} 1
 			`,
-			MetricNames: []string{"kube_persistentvolumeclaim_info", "kube_persistentvolumeclaim_status_phase", "kube_persistentvolumeclaim_resource_requests_storage_bytes", "kube_persistentvolumeclaim_labels"},
+			MetricNames: []string{"kube_persistentvolumeclaim_info", "kube_persistentvolumeclaim_status_phase", "kube_persistentvolumeclaim_resource_requests_storage_bytes", "kube_persistentvolumeclaim_status_capacity_bytes", "kube_persistentvolumeclaim_labels"},
 		},
 		{
 			Obj: &v1.PersistentVolumeClaim{
@@ -111,11 +129,13 @@ func TestPersistentVolumeClaimCollector(t *testing.T) {
 				kube_persistentvolumeclaim_status_phase{namespace="",persistentvolumeclaim="mongo-data",phase="Pending"} 0
 				kube_persistentvolumeclaim_labels{namespace="",persistentvolumeclaim="mongo-data"} 1
 `,
-			MetricNames: []string{"kube_persistentvolumeclaim_info", "kube_persistentvolumeclaim_status_phase", "kube_persistentvolumeclaim_resource_requests_storage_bytes", "kube_persistentvolumeclaim_labels"},
+			MetricNames: []string{"kube_persistentvolumeclaim_info", "kube_persistentvolumeclaim_status_phase", "kube_persistentvolumeclaim_resource_requests_storage_bytes", "kube_persistentvolumeclaim_status_capacity_bytes", "kube_persistentvolumeclaim_labels"},
 		},
 	}
 	for i, c := range cases {
-		c.Func = generatePersistentVolumeClaimMetrics
+		c.Func = func(obj interface{}) []*metrics.Metric {
+			return generatePersistentVolumeClaimMetrics(nil, obj)
+		}
 		if err := c.run(); err != nil {
 			t.Errorf("unexpected collecting result in %vth run:\n%s", i, err)
 		}
@@ -37,7 +37,6 @@ var (
 	descPodLabelsName          = "kube_pod_labels"
 	descPodLabelsHelp          = "Kubernetes labels converted to Prometheus labels."
 	descPodLabelsDefaultLabels = []string{"namespace", "pod"}
-	containerWaitingReasons    = []string{"ContainerCreating", "CrashLoopBackOff", "ErrImagePull", "ImagePullBackOff"}
 	containerTerminatedReasons = []string{"OOMKilled", "Completed", "Error", "ContainerCannotRun"}
 
 	descPodInfo = newMetricFamilyDef(
@@ -64,6 +63,9 @@ var (
 		append(descPodLabelsDefaultLabels, "owner_kind", "owner_name", "owner_is_controller"),
 		nil,
 	)
+	descPodAnnotationsName = "kube_pod_annotations"
+	descPodAnnotationsHelp = "Kubernetes annotations converted to Prometheus labels."
+
 	descPodLabels = newMetricFamilyDef(
 		descPodLabelsName,
 		descPodLabelsHelp,
@@ -88,6 +90,12 @@ var (
 		append(descPodLabelsDefaultLabels, "phase"),
 		nil,
 	)
+	descPodStatusQOSClass = newMetricFamilyDef(
+		"kube_pod_status_qos_class",
+		"The pods current qos class.",
+		append(descPodLabelsDefaultLabels, "qos_class"),
+		nil,
+	)
 	descPodStatusReady = newMetricFamilyDef(
 		"kube_pod_status_ready",
 		"Describes whether the pod is ready to serve requests.",
@@ -100,6 +108,18 @@ var (
 		append(descPodLabelsDefaultLabels, "condition"),
 		nil,
 	)
+	descPodStatusUnschedulable = newMetricFamilyDef(
+		"kube_pod_status_unschedulable",
+		"Describes whether the scheduler found no node the pod fits on.",
+		descPodLabelsDefaultLabels,
+		nil,
+	)
+	descPodStatusCondition = newMetricFamilyDef(
+		"kube_pod_status_condition",
+		"The condition of a pod, including readiness gates.",
+		append(descPodLabelsDefaultLabels, "condition", "status"),
+		nil,
+	)
 	descPodContainerInfo = newMetricFamilyDef(
 		"kube_pod_container_info",
 		"Information about a container in a pod.",
@@ -142,6 +162,12 @@ var (
 		append(descPodLabelsDefaultLabels, "container", "reason"),
 		nil,
 	)
+	descPodContainerStatusLastTerminatedExitCode = newMetricFamilyDef(
+		"kube_pod_container_status_last_terminated_exitcode",
+		"Describes the exit code of the last time the container was in terminated state.",
+		append(descPodLabelsDefaultLabels, "container"),
+		nil,
+	)
 
 	descPodContainerStatusReady = newMetricFamilyDef(
 		"kube_pod_container_status_ready",
@@ -191,6 +217,78 @@ var (
 		append(descPodLabelsDefaultLabels, "container", "node"),
 		nil,
 	)
+	descPodInitContainerInfo = newMetricFamilyDef(
+		"kube_pod_init_container_info",
+		"Information about an init container in a pod.",
+		append(descPodLabelsDefaultLabels, "container", "image", "image_id", "container_id"),
+		nil,
+	)
+	descPodInitContainerStatusWaiting = newMetricFamilyDef(
+		"kube_pod_init_container_status_waiting",
+		"Describes whether the init container is currently in waiting state.",
+		append(descPodLabelsDefaultLabels, "container"),
+		nil,
+	)
+	descPodInitContainerStatusWaitingReason = newMetricFamilyDef(
+		"kube_pod_init_container_status_waiting_reason",
+		"Describes the reason the init container is currently in waiting state.",
+		append(descPodLabelsDefaultLabels, "container", "reason"),
+		nil,
+	)
+	descPodInitContainerStatusRunning = newMetricFamilyDef(
+		"kube_pod_init_container_status_running",
+		"Describes whether the init container is currently in running state.",
+		append(descPodLabelsDefaultLabels, "container"),
+		nil,
+	)
+	descPodInitContainerStatusTerminated = newMetricFamilyDef(
+		"kube_pod_init_container_status_terminated",
+		"Describes whether the init container is currently in terminated state.",
+		append(descPodLabelsDefaultLabels, "container"),
+		nil,
+	)
+	descPodInitContainerStatusTerminatedReason = newMetricFamilyDef(
+		"kube_pod_init_container_status_terminated_reason",
+		"Describes the reason the init container is currently in terminated state.",
+		append(descPodLabelsDefaultLabels, "container", "reason"),
+		nil,
+	)
+	descPodInitContainerStatusLastTerminatedReason = newMetricFamilyDef(
+		"kube_pod_init_container_status_last_terminated_reason",
+		"Describes the last reason the init container was in terminated state.",
+		append(descPodLabelsDefaultLabels, "container", "reason"),
+		nil,
+	)
+	descPodInitContainerStatusLastTerminatedExitCode = newMetricFamilyDef(
+		"kube_pod_init_container_status_last_terminated_exitcode",
+		"Describes the exit code of the last time the init container was in terminated state.",
+		append(descPodLabelsDefaultLabels, "container"),
+		nil,
+	)
+	descPodInitContainerStatusReady = newMetricFamilyDef(
+		"kube_pod_init_container_status_ready",
+		"Describes whether the init containers readiness check succeeded.",
+		append(descPodLabelsDefaultLabels, "container"),
+		nil,
+	)
+	descPodInitContainerStatusRestarts = newMetricFamilyDef(
+		"kube_pod_init_container_status_restarts_total",
+		"The number of restarts for the init container.",
+		append(descPodLabelsDefaultLabels, "container"),
+		nil,
+	)
+	descPodInitContainerResourceRequests = newMetricFamilyDef(
+		"kube_pod_init_container_resource_requests",
+		"The number of requested request resource by an init container.",
+		append(descPodLabelsDefaultLabels, "container", "node", "resource", "unit"),
+		nil,
+	)
+	descPodInitContainerResourceLimits = newMetricFamilyDef(
+		"kube_pod_init_container_resource_limits",
+		"The number of requested limit resource by an init container.",
+		append(descPodLabelsDefaultLabels, "container", "node", "resource", "unit"),
+		nil,
+	)
 	descPodSpecVolumesPersistentVolumeClaimsInfo = newMetricFamilyDef(
 		"kube_pod_spec_volumes_persistentvolumeclaims_info",
 		"Information about persistentvolumeclaim volumes in a pod.",
@@ -203,6 +301,12 @@ var (
 		append(descPodLabelsDefaultLabels, "volume", "persistentvolumeclaim"),
 		nil,
 	)
+	descPodSpecTolerations = newMetricFamilyDef(
+		"kube_pod_spec_tolerations",
+		"Information about the tolerations of the pod.",
+		append(descPodLabelsDefaultLabels, "key", "operator", "value", "effect", "toleration_seconds"),
+		nil,
+	)
 )
 
 func createPodListWatch(kubeClient clientset.Interface, ns string) cache.ListWatch {
@@ -227,6 +331,7 @@ func createPodListWatch(kubeClient clientset.Interface, ns string) cache.ListWat
 // 	ch <- descPodCreated
 // 	ch <- descPodStatusScheduledTime
 // 	ch <- descPodStatusPhase
+// 	ch <- descPodStatusQOSClass
 // 	ch <- descPodStatusReady
 // 	ch <- descPodStatusScheduled
 // 	ch <- descPodContainerInfo
@@ -242,6 +347,19 @@ func createPodListWatch(kubeClient clientset.Interface, ns string) cache.ListWat
 // 	ch <- descPodContainerResourceRequests
 // 	ch <- descPodContainerResourceLimits
 // 	ch <- descPodContainerStatusLastTerminatedReason
+// 	ch <- descPodContainerStatusLastTerminatedExitCode
+// 	ch <- descPodInitContainerInfo
+// 	ch <- descPodInitContainerStatusWaiting
+// 	ch <- descPodInitContainerStatusWaitingReason
+// 	ch <- descPodInitContainerStatusRunning
+// 	ch <- descPodInitContainerStatusTerminated
+// 	ch <- descPodInitContainerStatusTerminatedReason
+// 	ch <- descPodInitContainerStatusLastTerminatedReason
+// 	ch <- descPodInitContainerStatusLastTerminatedExitCode
+// 	ch <- descPodInitContainerStatusReady
+// 	ch <- descPodInitContainerStatusRestarts
+// 	ch <- descPodInitContainerResourceRequests
+// 	ch <- descPodInitContainerResourceLimits
 //
 // 	if !c.opts.DisablePodNonGenericResourceMetrics {
 // 		ch <- descPodContainerResourceRequestsCPUCores
@@ -251,6 +369,18 @@ func createPodListWatch(kubeClient clientset.Interface, ns string) cache.ListWat
 // 	}
 // }
 
+// withoutLabel returns the given label keys and values without the label of
+// the given name.
+func withoutLabel(keys, values []string, name string) ([]string, []string) {
+	for i, k := range keys {
+		if k == name {
+			return append(append([]string{}, keys[:i]...), keys[i+1:]...),
+				append(append([]string{}, values[:i]...), values[i+1:]...)
+		}
+	}
+	return keys, values
+}
+
 func podLabelsDesc(labelKeys []string) *metricFamilyDef {
 	return newMetricFamilyDef(
 		descPodLabelsName,
@@ -260,7 +390,21 @@ func podLabelsDesc(labelKeys []string) *metricFamilyDef {
 	)
 }
 
-func generatePodMetrics(disablePodNonGenericResourceMetrics bool, obj interface{}) []*metrics.Metric {
+func podAnnotationsDesc(annotationKeys []string) *metricFamilyDef {
+	return newMetricFamilyDef(
+		descPodAnnotationsName,
+		descPodAnnotationsHelp,
+		append(descPodLabelsDefaultLabels, annotationKeys...),
+		nil,
+	)
+}
+
+// generatePodMetrics generates the metrics of a pod. If useInfoMetrics is
+// set, the node of the pod is only exposed by kube_pod_info instead of by
+// every per-container resource metric, to be joined on namespace and pod.
+// kube_pod_annotations is only generated if allowedAnnotations is non-nil
+// and holds the allowed annotation keys.
+func generatePodMetrics(disablePodNonGenericResourceMetrics, useInfoMetrics bool, allowedAnnotations []string, allowedLabels []string, obj interface{}) []*metrics.Metric {
 	ms := []*metrics.Metric{}
 
 	// TODO: Refactor
@@ -271,7 +415,12 @@ func generatePodMetrics(disablePodNonGenericResourceMetrics bool, obj interface{
 	addConstMetric := func(desc *metricFamilyDef, v float64, lv ...string) {
 		lv = append([]string{p.Namespace, p.Name}, lv...)
 
-		m, err := metrics.NewMetric(desc.Name, desc.LabelKeys, lv, v)
+		labelKeys := desc.LabelKeys
+		if useInfoMetrics && desc != descPodInfo {
+			labelKeys, lv = withoutLabel(labelKeys, lv, "node")
+		}
+
+		m, err := metrics.NewMetric(desc.Name, labelKeys, lv, v)
 		if err != nil {
 			panic(err)
 		}
@@ -316,8 +465,14 @@ func generatePodMetrics(disablePodNonGenericResourceMetrics bool, obj interface{
 		}
 	}
 
-	labelKeys, labelValues := kubeLabelsToPrometheusLabels(p.Labels)
-	addGauge(podLabelsDesc(labelKeys), 1, labelValues...)
+	if labelKeys, labelValues, ok := kubeLabelsToPrometheusLabels(p.Labels, allowedLabels); ok {
+		addGauge(podLabelsDesc(labelKeys), 1, labelValues...)
+	}
+
+	if allowedAnnotations != nil {
+		annotationKeys, annotationValues := allowedAnnotationsToPrometheusAnnotations(p.Annotations, allowedAnnotations)
+		addGauge(podAnnotationsDesc(annotationKeys), 1, annotationValues...)
+	}
 
 	if phase := p.Status.Phase; phase != "" {
 		addGauge(descPodStatusPhase, boolFloat64(phase == v1.PodPending), string(v1.PodPending))
@@ -329,11 +484,14 @@ func generatePodMetrics(disablePodNonGenericResourceMetrics bool, obj interface{
 		addGauge(descPodStatusPhase, boolFloat64(phase == v1.PodUnknown || (p.DeletionTimestamp != nil && p.Status.Reason == node.NodeUnreachablePodReason)), string(v1.PodUnknown))
 	}
 
+	addGauge(descPodStatusQOSClass, 1, string(podQOSClass(&p)))
+
 	if !p.CreationTimestamp.IsZero() {
 		addGauge(descPodCreated, float64(p.CreationTimestamp.Unix()))
 	}
 
 	for _, c := range p.Status.Conditions {
+		ms = append(ms, addConditionMetrics(descPodStatusCondition, c.Status, p.Namespace, p.Name, string(c.Type))...)
 		switch c.Type {
 		case v1.PodReady:
 			ms = append(ms, addConditionMetrics(descPodStatusReady, c.Status, p.Namespace, p.Name)...)
@@ -342,16 +500,10 @@ func generatePodMetrics(disablePodNonGenericResourceMetrics bool, obj interface{
 			if c.Status == v1.ConditionTrue {
 				addGauge(descPodStatusScheduledTime, float64(c.LastTransitionTime.Unix()))
 			}
+			addGauge(descPodStatusUnschedulable, boolFloat64(c.Status == v1.ConditionFalse && c.Reason == v1.PodReasonUnschedulable))
 		}
 	}
 
-	waitingReason := func(cs v1.ContainerStatus, reason string) bool {
-		if cs.State.Waiting == nil {
-			return false
-		}
-		return cs.State.Waiting.Reason == reason
-	}
-
 	terminationReason := func(cs v1.ContainerStatus, reason string) bool {
 		if cs.State.Terminated == nil {
 			return false
@@ -373,16 +525,23 @@ func generatePodMetrics(disablePodNonGenericResourceMetrics bool, obj interface{
 			cs.Name, cs.Image, cs.ImageID, cs.ContainerID,
 		)
 		addGauge(descPodContainerStatusWaiting, boolFloat64(cs.State.Waiting != nil), cs.Name)
-		for _, reason := range containerWaitingReasons {
-			addGauge(descPodContainerStatusWaitingReason, boolFloat64(waitingReason(cs, reason)), cs.Name, reason)
+		// Only the current waiting reason is exposed, so that containers
+		// not waiting have no series and unlisted reasons are covered.
+		if cs.State.Waiting != nil && cs.State.Waiting.Reason != "" {
+			addGauge(descPodContainerStatusWaitingReason, 1, cs.Name, cs.State.Waiting.Reason)
 		}
 		addGauge(descPodContainerStatusRunning, boolFloat64(cs.State.Running != nil), cs.Name)
 		addGauge(descPodContainerStatusTerminated, boolFloat64(cs.State.Terminated != nil), cs.Name)
 		for _, reason := range containerTerminatedReasons {
 			addGauge(descPodContainerStatusTerminatedReason, boolFloat64(terminationReason(cs, reason)), cs.Name, reason)
 		}
-		for _, reason := range containerTerminatedReasons {
-			addGauge(descPodContainerStatusLastTerminatedReason, boolFloat64(lastTerminationReason(cs, reason)), cs.Name, reason)
+		// Containers which have never terminated don't have a last
+		// termination reason or exit code.
+		if cs.LastTerminationState.Terminated != nil {
+			for _, reason := range containerTerminatedReasons {
+				addGauge(descPodContainerStatusLastTerminatedReason, boolFloat64(lastTerminationReason(cs, reason)), cs.Name, reason)
+			}
+			addGauge(descPodContainerStatusLastTerminatedExitCode, float64(cs.LastTerminationState.Terminated.ExitCode), cs.Name)
 		}
 		addGauge(descPodContainerStatusReady, boolFloat64(cs.Ready), cs.Name)
 		addCounter(descPodContainerStatusRestarts, float64(cs.RestartCount), cs.Name)
@@ -398,6 +557,29 @@ func generatePodMetrics(disablePodNonGenericResourceMetrics bool, obj interface{
 		addGauge(descPodCompletionTime, lastFinishTime)
 	}
 
+	for _, cs := range p.Status.InitContainerStatuses {
+		addGauge(descPodInitContainerInfo, 1,
+			cs.Name, cs.Image, cs.ImageID, cs.ContainerID,
+		)
+		addGauge(descPodInitContainerStatusWaiting, boolFloat64(cs.State.Waiting != nil), cs.Name)
+		if cs.State.Waiting != nil && cs.State.Waiting.Reason != "" {
+			addGauge(descPodInitContainerStatusWaitingReason, 1, cs.Name, cs.State.Waiting.Reason)
+		}
+		addGauge(descPodInitContainerStatusRunning, boolFloat64(cs.State.Running != nil), cs.Name)
+		addGauge(descPodInitContainerStatusTerminated, boolFloat64(cs.State.Terminated != nil), cs.Name)
+		for _, reason := range containerTerminatedReasons {
+			addGauge(descPodInitContainerStatusTerminatedReason, boolFloat64(terminationReason(cs, reason)), cs.Name, reason)
+		}
+		if cs.LastTerminationState.Terminated != nil {
+			for _, reason := range containerTerminatedReasons {
+				addGauge(descPodInitContainerStatusLastTerminatedReason, boolFloat64(lastTerminationReason(cs, reason)), cs.Name, reason)
+			}
+			addGauge(descPodInitContainerStatusLastTerminatedExitCode, float64(cs.LastTerminationState.Terminated.ExitCode), cs.Name)
+		}
+		addGauge(descPodInitContainerStatusReady, boolFloat64(cs.Ready), cs.Name)
+		addCounter(descPodInitContainerStatusRestarts, float64(cs.RestartCount), cs.Name)
+	}
+
 	if !disablePodNonGenericResourceMetrics {
 		for _, c := range p.Spec.Containers {
 			req := c.Resources.Requests
@@ -424,33 +606,33 @@ func generatePodMetrics(disablePodNonGenericResourceMetrics bool, obj interface{
 		}
 	}
 
-	for _, c := range p.Spec.Containers {
+	addContainerResourceMetrics := func(c v1.Container, requestsDesc, limitsDesc *metricFamilyDef) {
 		req := c.Resources.Requests
 		lim := c.Resources.Limits
 
 		for resourceName, val := range req {
 			switch resourceName {
 			case v1.ResourceCPU:
-				addGauge(descPodContainerResourceRequests, float64(val.MilliValue())/1000,
+				addGauge(requestsDesc, float64(val.MilliValue())/1000,
 					c.Name, nodeName, sanitizeLabelName(string(resourceName)), string(constant.UnitCore))
 			case v1.ResourceStorage:
 				fallthrough
 			case v1.ResourceEphemeralStorage:
 				fallthrough
 			case v1.ResourceMemory:
-				addGauge(descPodContainerResourceRequests, float64(val.Value()),
+				addGauge(requestsDesc, float64(val.Value()),
 					c.Name, nodeName, sanitizeLabelName(string(resourceName)), string(constant.UnitByte))
 			default:
 				if helper.IsHugePageResourceName(resourceName) {
-					addGauge(descPodContainerResourceRequests, float64(val.Value()),
+					addGauge(requestsDesc, float64(val.Value()),
 						c.Name, nodeName, sanitizeLabelName(string(resourceName)), string(constant.UnitByte))
 				}
 				if helper.IsAttachableVolumeResourceName(resourceName) {
-					addGauge(descPodContainerResourceRequests, float64(val.Value()),
+					addGauge(requestsDesc, float64(val.Value()),
 						c.Name, nodeName, sanitizeLabelName(string(resourceName)), string(constant.UnitByte))
 				}
 				if helper.IsExtendedResourceName(resourceName) {
-					addGauge(descPodContainerResourceRequests, float64(val.Value()),
+					addGauge(requestsDesc, float64(val.Value()),
 						c.Name, nodeName, sanitizeLabelName(string(resourceName)), string(constant.UnitInteger))
 				}
 			}
@@ -459,32 +641,39 @@ func generatePodMetrics(disablePodNonGenericResourceMetrics bool, obj interface{
 		for resourceName, val := range lim {
 			switch resourceName {
 			case v1.ResourceCPU:
-				addGauge(descPodContainerResourceLimits, float64(val.MilliValue())/1000,
+				addGauge(limitsDesc, float64(val.MilliValue())/1000,
 					c.Name, nodeName, sanitizeLabelName(string(resourceName)), string(constant.UnitCore))
 			case v1.ResourceStorage:
 				fallthrough
 			case v1.ResourceEphemeralStorage:
 				fallthrough
 			case v1.ResourceMemory:
-				addGauge(descPodContainerResourceLimits, float64(val.Value()),
+				addGauge(limitsDesc, float64(val.Value()),
 					c.Name, nodeName, sanitizeLabelName(string(resourceName)), string(constant.UnitByte))
 			default:
 				if helper.IsHugePageResourceName(resourceName) {
-					addGauge(descPodContainerResourceLimits, float64(val.Value()),
+					addGauge(limitsDesc, float64(val.Value()),
 						c.Name, nodeName, sanitizeLabelName(string(resourceName)), string(constant.UnitByte))
 				}
 				if helper.IsAttachableVolumeResourceName(resourceName) {
-					addGauge(descPodContainerResourceLimits, float64(val.Value()),
+					addGauge(limitsDesc, float64(val.Value()),
 						c.Name, nodeName, sanitizeLabelName(string(resourceName)), string(constant.UnitByte))
 				}
 				if helper.IsExtendedResourceName(resourceName) {
-					addGauge(descPodContainerResourceLimits, float64(val.Value()),
+					addGauge(limitsDesc, float64(val.Value()),
 						c.Name, nodeName, sanitizeLabelName(string(resourceName)), string(constant.UnitInteger))
 				}
 			}
 		}
 	}
 
+	for _, c := range p.Spec.InitContainers {
+		addContainerResourceMetrics(c, descPodInitContainerResourceRequests, descPodInitContainerResourceLimits)
+	}
+	for _, c := range p.Spec.Containers {
+		addContainerResourceMetrics(c, descPodContainerResourceRequests, descPodContainerResourceLimits)
+	}
+
 	for _, v := range p.Spec.Volumes {
 		if v.PersistentVolumeClaim != nil {
 			addGauge(descPodSpecVolumesPersistentVolumeClaimsInfo, 1, v.Name, v.PersistentVolumeClaim.ClaimName)
@@ -496,5 +685,63 @@ func generatePodMetrics(disablePodNonGenericResourceMetrics bool, obj interface{
 		}
 	}
 
+	// An empty key with the Exists operator tolerates all taints, an empty
+	// effect all effects.
+	for _, t := range p.Spec.Tolerations {
+		tolerationSeconds := ""
+		if t.TolerationSeconds != nil {
+			tolerationSeconds = strconv.FormatInt(*t.TolerationSeconds, 10)
+		}
+		addGauge(descPodSpecTolerations, 1, t.Key, string(t.Operator), t.Value, string(t.Effect), tolerationSeconds)
+	}
+
 	return ms
 }
+
+// podQOSClass returns the QoS class of the pod as reported in its status. If
+// the status doesn't carry it yet, the class is computed from the resource
+// requests and limits of the pod's containers, following
+// k8s.io/kubernetes/pkg/apis/core/v1/helper/qos.GetPodQOS.
+func podQOSClass(p *v1.Pod) v1.PodQOSClass {
+	if p.Status.QOSClass != "" {
+		return p.Status.QOSClass
+	}
+
+	requests := v1.ResourceList{}
+	limits := v1.ResourceList{}
+	isGuaranteed := true
+	for _, containers := range [][]v1.Container{p.Spec.InitContainers, p.Spec.Containers} {
+		for _, c := range containers {
+			for _, name := range []v1.ResourceName{v1.ResourceCPU, v1.ResourceMemory} {
+				if req, ok := c.Resources.Requests[name]; ok && !req.IsZero() {
+					sum := requests[name]
+					sum.Add(req)
+					requests[name] = sum
+				}
+				if lim, ok := c.Resources.Limits[name]; ok && !lim.IsZero() {
+					sum := limits[name]
+					sum.Add(lim)
+					limits[name] = sum
+				} else {
+					isGuaranteed = false
+				}
+			}
+		}
+	}
+
+	if len(requests) == 0 && len(limits) == 0 {
+		return v1.PodQOSBestEffort
+	}
+	if isGuaranteed {
+		for name, req := range requests {
+			if lim, ok := limits[name]; !ok || lim.Cmp(req) != 0 {
+				isGuaranteed = false
+				break
+			}
+		}
+	}
+	if isGuaranteed {
+		return v1.PodQOSGuaranteed
+	}
+	return v1.PodQOSBurstable
+}
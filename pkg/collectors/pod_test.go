@@ -17,13 +17,16 @@ limitations under the License.
 package collectors
 
 import (
+	"strconv"
 	"testing"
 	"time"
 
 	"k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/kube-state-metrics/pkg/metrics"
+	metricsstore "k8s.io/kube-state-metrics/pkg/metrics_store"
 	"k8s.io/kubernetes/pkg/util/node"
 )
 
@@ -34,6 +37,7 @@ func TestPodCollector(t *testing.T) {
 
 	startTime := 1501569018
 	metav1StartTime := metav1.Unix(int64(startTime), 0)
+	tolerationSeconds := int64(300)
 
 	// TODO: renable metadata
 	const metadata = ""
@@ -44,6 +48,8 @@ func TestPodCollector(t *testing.T) {
 	// # TYPE kube_pod_container_info gauge
 	// # HELP kube_pod_labels Kubernetes labels converted to Prometheus labels.
 	// # TYPE kube_pod_labels gauge
+	// # HELP kube_pod_annotations Kubernetes annotations converted to Prometheus labels.
+	// # TYPE kube_pod_annotations gauge
 	// # HELP kube_pod_container_status_ready Describes whether the containers readiness check succeeded.
 	// # TYPE kube_pod_container_status_ready gauge
 	// # HELP kube_pod_container_status_restarts_total The number of container restarts per container.
@@ -56,6 +62,8 @@ func TestPodCollector(t *testing.T) {
 	// # TYPE kube_pod_container_status_terminated_reason gauge
 	// # HELP kube_pod_container_status_last_terminated_reason Describes the last reason the container was in terminated state.
 	// # TYPE kube_pod_container_status_last_terminated_reason gauge
+	// # HELP kube_pod_container_status_last_terminated_exitcode Describes the exit code of the last time the container was in terminated state.
+	// # TYPE kube_pod_container_status_last_terminated_exitcode gauge
 	// # HELP kube_pod_container_status_waiting Describes whether the container is currently in waiting state.
 	// # TYPE kube_pod_container_status_waiting gauge
 	// # HELP kube_pod_container_status_waiting_reason Describes the reason the container is currently in waiting state.
@@ -72,10 +80,16 @@ func TestPodCollector(t *testing.T) {
 	// # TYPE kube_pod_owner gauge
 	// # HELP kube_pod_status_phase The pods current phase.
 	// # TYPE kube_pod_status_phase gauge
+	// # HELP kube_pod_status_qos_class The pods current qos class.
+	// # TYPE kube_pod_status_qos_class gauge
 	// # HELP kube_pod_status_ready Describes whether the pod is ready to serve requests.
 	// # TYPE kube_pod_status_ready gauge
 	// # HELP kube_pod_status_scheduled Describes the status of the scheduling process for the pod.
 	// # TYPE kube_pod_status_scheduled gauge
+	// # HELP kube_pod_status_unschedulable Describes whether the scheduler found no node the pod fits on.
+	// # TYPE kube_pod_status_unschedulable gauge
+	// # HELP kube_pod_status_condition The condition of a pod, including readiness gates.
+	// # TYPE kube_pod_status_condition gauge
 	// # HELP kube_pod_container_resource_requests The number of requested request resource by a container.
 	// # TYPE kube_pod_container_resource_requests gauge
 	// # HELP kube_pod_container_resource_limits The number of requested limit resource by a container.
@@ -88,10 +102,36 @@ func TestPodCollector(t *testing.T) {
 	// # TYPE kube_pod_container_resource_limits_cpu_cores gauge
 	// # HELP kube_pod_container_resource_limits_memory_bytes The limit on memory to be used by a container in bytes.
 	// # TYPE kube_pod_container_resource_limits_memory_bytes gauge
+	// # HELP kube_pod_init_container_info Information about an init container in a pod.
+	// # TYPE kube_pod_init_container_info gauge
+	// # HELP kube_pod_init_container_status_waiting Describes whether the init container is currently in waiting state.
+	// # TYPE kube_pod_init_container_status_waiting gauge
+	// # HELP kube_pod_init_container_status_waiting_reason Describes the reason the init container is currently in waiting state.
+	// # TYPE kube_pod_init_container_status_waiting_reason gauge
+	// # HELP kube_pod_init_container_status_running Describes whether the init container is currently in running state.
+	// # TYPE kube_pod_init_container_status_running gauge
+	// # HELP kube_pod_init_container_status_terminated Describes whether the init container is currently in terminated state.
+	// # TYPE kube_pod_init_container_status_terminated gauge
+	// # HELP kube_pod_init_container_status_terminated_reason Describes the reason the init container is currently in terminated state.
+	// # TYPE kube_pod_init_container_status_terminated_reason gauge
+	// # HELP kube_pod_init_container_status_last_terminated_reason Describes the last reason the init container was in terminated state.
+	// # TYPE kube_pod_init_container_status_last_terminated_reason gauge
+	// # HELP kube_pod_init_container_status_last_terminated_exitcode Describes the exit code of the last time the init container was in terminated state.
+	// # TYPE kube_pod_init_container_status_last_terminated_exitcode gauge
+	// # HELP kube_pod_init_container_status_ready Describes whether the init containers readiness check succeeded.
+	// # TYPE kube_pod_init_container_status_ready gauge
+	// # HELP kube_pod_init_container_status_restarts_total The number of restarts for the init container.
+	// # TYPE kube_pod_init_container_status_restarts_total counter
+	// # HELP kube_pod_init_container_resource_requests The number of requested request resource by an init container.
+	// # TYPE kube_pod_init_container_resource_requests gauge
+	// # HELP kube_pod_init_container_resource_limits The number of requested limit resource by an init container.
+	// # TYPE kube_pod_init_container_resource_limits gauge
 	// # HELP kube_pod_spec_volumes_persistentvolumeclaims_info Information about persistentvolumeclaim volumes in a pod.
 	// # TYPE kube_pod_spec_volumes_persistentvolumeclaims_info gauge
 	// # HELP kube_pod_spec_volumes_persistentvolumeclaims_readonly Describes whether a persistentvolumeclaim is mounted read only.
 	// # TYPE kube_pod_spec_volumes_persistentvolumeclaims_readonly gauge
+	// # HELP kube_pod_spec_tolerations Information about the tolerations of the pod.
+	// # TYPE kube_pod_spec_tolerations gauge
 	// 	`
 	cases := []generateMetricsTestCase{
 		{
@@ -114,6 +154,26 @@ func TestPodCollector(t *testing.T) {
 			Want:        `kube_pod_container_info{container="container1",container_id="docker://ab123",image="k8s.gcr.io/hyperkube1",image_id="docker://sha256:aaa",namespace="ns1",pod="pod1"} 1`,
 			MetricNames: []string{"kube_pod_container_info"},
 		},
+		{
+			Obj: &v1.Pod{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "pod1",
+					Namespace: "ns1",
+				},
+				Status: v1.PodStatus{
+					ContainerStatuses: []v1.ContainerStatus{
+						v1.ContainerStatus{
+							Name:        "container1",
+							Image:       "k8s.gcr.io/hyperkube1@sha256:6f3b3c3d4ad1e8c1d8a4f0e5e0c5c1b5f0e9d7f7a2a5f6b0c3d8e9f1a2b3c4d5",
+							ImageID:     "docker-pullable://k8s.gcr.io/hyperkube1@sha256:6f3b3c3d4ad1e8c1d8a4f0e5e0c5c1b5f0e9d7f7a2a5f6b0c3d8e9f1a2b3c4d5",
+							ContainerID: "docker://ab123",
+						},
+					},
+				},
+			},
+			Want:        `kube_pod_container_info{container="container1",container_id="docker://ab123",image="k8s.gcr.io/hyperkube1@sha256:6f3b3c3d4ad1e8c1d8a4f0e5e0c5c1b5f0e9d7f7a2a5f6b0c3d8e9f1a2b3c4d5",image_id="docker-pullable://k8s.gcr.io/hyperkube1@sha256:6f3b3c3d4ad1e8c1d8a4f0e5e0c5c1b5f0e9d7f7a2a5f6b0c3d8e9f1a2b3c4d5",namespace="ns1",pod="pod1"} 1`,
+			MetricNames: []string{"kube_pod_container_info"},
+		},
 		{
 			Obj: &v1.Pod{
 				ObjectMeta: metav1.ObjectMeta{
@@ -252,10 +312,6 @@ func TestPodCollector(t *testing.T) {
 				kube_pod_container_status_terminated_reason{container="container1",namespace="ns1",pod="pod1",reason="OOMKilled"} 0
                 kube_pod_container_status_terminated{container="container1",namespace="ns1",pod="pod1"} 0
 				kube_pod_container_status_waiting{container="container1",namespace="ns1",pod="pod1"} 0
-				kube_pod_container_status_waiting_reason{container="container1",namespace="ns1",pod="pod1",reason="ContainerCreating"} 0
-				kube_pod_container_status_waiting_reason{container="container1",namespace="ns1",pod="pod1",reason="ImagePullBackOff"} 0
-				kube_pod_container_status_waiting_reason{container="container1",namespace="ns1",pod="pod1",reason="CrashLoopBackOff"} 0
-				kube_pod_container_status_waiting_reason{container="container1",namespace="ns1",pod="pod1",reason="ErrImagePull"} 0
 `,
 
 			MetricNames: []string{
@@ -308,14 +364,7 @@ func TestPodCollector(t *testing.T) {
 				kube_pod_container_status_waiting{container="container2",namespace="ns2",pod="pod2"} 0
                 kube_pod_container_status_waiting{container="container3",namespace="ns2",pod="pod2"} 1
                 kube_pod_container_status_terminated{container="container3",namespace="ns2",pod="pod2"} 0
-				kube_pod_container_status_waiting_reason{container="container2",namespace="ns2",pod="pod2",reason="ContainerCreating"} 0
-				kube_pod_container_status_waiting_reason{container="container2",namespace="ns2",pod="pod2",reason="ImagePullBackOff"} 0
-				kube_pod_container_status_waiting_reason{container="container2",namespace="ns2",pod="pod2",reason="CrashLoopBackOff"} 0
-				kube_pod_container_status_waiting_reason{container="container2",namespace="ns2",pod="pod2",reason="ErrImagePull"} 0
                 kube_pod_container_status_waiting_reason{container="container3",namespace="ns2",pod="pod2",reason="ContainerCreating"} 1
-                kube_pod_container_status_waiting_reason{container="container3",namespace="ns2",pod="pod2",reason="CrashLoopBackOff"} 0
-                kube_pod_container_status_waiting_reason{container="container3",namespace="ns2",pod="pod2",reason="ErrImagePull"} 0
-                kube_pod_container_status_waiting_reason{container="container3",namespace="ns2",pod="pod2",reason="ImagePullBackOff"} 0
 `,
 			MetricNames: []string{
 				"kube_pod_container_status_running",
@@ -352,14 +401,7 @@ kube_pod_container_status_terminated_reason{container="container4",namespace="ns
 				kube_pod_container_status_terminated_reason{container="container4",namespace="ns3",pod="pod3",reason="Error"} 0
 				kube_pod_container_status_terminated_reason{container="container4",namespace="ns3",pod="pod3",reason="OOMKilled"} 0
 				kube_pod_container_status_waiting{container="container4",namespace="ns3",pod="pod3"} 1
-kube_pod_container_status_waiting_reason{container="container4",namespace="ns3",pod="pod3",reason="ContainerCreating"} 0
-				kube_pod_container_status_waiting_reason{container="container4",namespace="ns3",pod="pod3",reason="ImagePullBackOff"} 0
 				kube_pod_container_status_waiting_reason{container="container4",namespace="ns3",pod="pod3",reason="CrashLoopBackOff"} 1
-				kube_pod_container_status_waiting_reason{container="container4",namespace="ns3",pod="pod3",reason="ErrImagePull"} 0
-kube_pod_container_status_last_terminated_reason{container="container4",namespace="ns3",pod="pod3",reason="Completed"} 0
-				kube_pod_container_status_last_terminated_reason{container="container4",namespace="ns3",pod="pod3",reason="ContainerCannotRun"} 0
-				kube_pod_container_status_last_terminated_reason{container="container4",namespace="ns3",pod="pod3",reason="Error"} 0
-				kube_pod_container_status_last_terminated_reason{container="container4",namespace="ns3",pod="pod3",reason="OOMKilled"} 0
 `,
 			MetricNames: []string{
 				"kube_pod_container_status_running",
@@ -374,9 +416,44 @@ kube_pod_container_status_last_terminated_reason{container="container4",namespac
 				"kube_pod_container_status_waiting_reason",
 				"kube_pod_container_status_waiting_reason",
 				"kube_pod_container_status_last_terminated_reason",
-				"kube_pod_container_status_last_terminated_reason",
-				"kube_pod_container_status_last_terminated_reason",
-				"kube_pod_container_status_last_terminated_reason",
+				"kube_pod_container_status_last_terminated_exitcode",
+			},
+		},
+		{
+			Obj: &v1.Pod{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "pod3",
+					Namespace: "ns3",
+				},
+				Status: v1.PodStatus{
+					InitContainerStatuses: []v1.ContainerStatus{
+						v1.ContainerStatus{
+							Name: "init1",
+							State: v1.ContainerState{
+								Waiting: &v1.ContainerStateWaiting{
+									Reason: "ErrImagePull",
+								},
+							},
+						},
+					},
+				},
+			},
+			Want: `
+				kube_pod_init_container_status_running{container="init1",namespace="ns3",pod="pod3"} 0
+				kube_pod_init_container_status_terminated{container="init1",namespace="ns3",pod="pod3"} 0
+				kube_pod_init_container_status_terminated_reason{container="init1",namespace="ns3",pod="pod3",reason="Completed"} 0
+				kube_pod_init_container_status_terminated_reason{container="init1",namespace="ns3",pod="pod3",reason="ContainerCannotRun"} 0
+				kube_pod_init_container_status_terminated_reason{container="init1",namespace="ns3",pod="pod3",reason="Error"} 0
+				kube_pod_init_container_status_terminated_reason{container="init1",namespace="ns3",pod="pod3",reason="OOMKilled"} 0
+				kube_pod_init_container_status_waiting{container="init1",namespace="ns3",pod="pod3"} 1
+				kube_pod_init_container_status_waiting_reason{container="init1",namespace="ns3",pod="pod3",reason="ErrImagePull"} 1
+`,
+			MetricNames: []string{
+				"kube_pod_init_container_status_running",
+				"kube_pod_init_container_status_terminated",
+				"kube_pod_init_container_status_terminated_reason",
+				"kube_pod_init_container_status_waiting",
+				"kube_pod_init_container_status_waiting_reason",
 			},
 		},
 		{
@@ -395,7 +472,8 @@ kube_pod_container_status_last_terminated_reason{container="container4",namespac
 							},
 							LastTerminationState: v1.ContainerState{
 								Terminated: &v1.ContainerStateTerminated{
-									Reason: "OOMKilled",
+									Reason:   "OOMKilled",
+									ExitCode: 137,
 								},
 							},
 						},
@@ -410,17 +488,15 @@ kube_pod_container_status_terminated_reason{container="container7",namespace="ns
 				kube_pod_container_status_terminated_reason{container="container7",namespace="ns6",pod="pod6",reason="Error"} 0
 				kube_pod_container_status_terminated_reason{container="container7",namespace="ns6",pod="pod6",reason="OOMKilled"} 0
 				kube_pod_container_status_waiting{container="container7",namespace="ns6",pod="pod6"} 0
-kube_pod_container_status_waiting_reason{container="container7",namespace="ns6",pod="pod6",reason="ContainerCreating"} 0
-				kube_pod_container_status_waiting_reason{container="container7",namespace="ns6",pod="pod6",reason="ImagePullBackOff"} 0
-				kube_pod_container_status_waiting_reason{container="container7",namespace="ns6",pod="pod6",reason="CrashLoopBackOff"} 0
-				kube_pod_container_status_waiting_reason{container="container7",namespace="ns6",pod="pod6",reason="ErrImagePull"} 0
 kube_pod_container_status_last_terminated_reason{container="container7",namespace="ns6",pod="pod6",reason="Completed"} 0
 				kube_pod_container_status_last_terminated_reason{container="container7",namespace="ns6",pod="pod6",reason="ContainerCannotRun"} 0
 				kube_pod_container_status_last_terminated_reason{container="container7",namespace="ns6",pod="pod6",reason="Error"} 0
 				kube_pod_container_status_last_terminated_reason{container="container7",namespace="ns6",pod="pod6",reason="OOMKilled"} 1
+				kube_pod_container_status_last_terminated_exitcode{container="container7",namespace="ns6",pod="pod6"} 137
 `,
 			MetricNames: []string{
 				"kube_pod_container_status_last_terminated_reason",
+				"kube_pod_container_status_last_terminated_exitcode",
 				"kube_pod_container_status_running",
 				"kube_pod_container_status_terminated",
 				"kube_pod_container_status_terminated_reason",
@@ -455,10 +531,7 @@ kube_pod_container_status_last_terminated_reason{container="container7",namespac
 				kube_pod_container_status_terminated_reason{container="container5",namespace="ns4",pod="pod4",reason="Error"} 0
 				kube_pod_container_status_terminated_reason{container="container5",namespace="ns4",pod="pod4",reason="OOMKilled"} 0
 				kube_pod_container_status_waiting{container="container5",namespace="ns4",pod="pod4"} 1
-				kube_pod_container_status_waiting_reason{container="container5",namespace="ns4",pod="pod4",reason="ContainerCreating"} 0
 				kube_pod_container_status_waiting_reason{container="container5",namespace="ns4",pod="pod4",reason="ImagePullBackOff"} 1
-				kube_pod_container_status_waiting_reason{container="container5",namespace="ns4",pod="pod4",reason="CrashLoopBackOff"} 0
-				kube_pod_container_status_waiting_reason{container="container5",namespace="ns4",pod="pod4",reason="ErrImagePull"} 0
 `,
 			MetricNames: []string{
 				"kube_pod_container_status_running",
@@ -495,9 +568,6 @@ kube_pod_container_status_last_terminated_reason{container="container7",namespac
 				kube_pod_container_status_terminated_reason{container="container6",namespace="ns5",pod="pod5",reason="Error"} 0
 				kube_pod_container_status_terminated_reason{container="container6",namespace="ns5",pod="pod5",reason="OOMKilled"} 0
 				kube_pod_container_status_waiting{container="container6",namespace="ns5",pod="pod5"} 1
-				kube_pod_container_status_waiting_reason{container="container6",namespace="ns5",pod="pod5",reason="ContainerCreating"} 0
-				kube_pod_container_status_waiting_reason{container="container6",namespace="ns5",pod="pod5",reason="ImagePullBackOff"} 0
-				kube_pod_container_status_waiting_reason{container="container6",namespace="ns5",pod="pod5",reason="CrashLoopBackOff"} 0
 				kube_pod_container_status_waiting_reason{container="container6",namespace="ns5",pod="pod5",reason="ErrImagePull"} 1
 				`,
 			MetricNames: []string{
@@ -508,6 +578,33 @@ kube_pod_container_status_last_terminated_reason{container="container7",namespac
 				"kube_pod_container_status_waiting_reason",
 			},
 		},
+		{
+			// Waiting reasons other than the common ones are exposed as well.
+			Obj: &v1.Pod{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "pod6",
+					Namespace: "ns6",
+				},
+				Status: v1.PodStatus{
+					ContainerStatuses: []v1.ContainerStatus{
+						v1.ContainerStatus{
+							Name: "container7",
+							State: v1.ContainerState{
+								Waiting: &v1.ContainerStateWaiting{
+									Reason: "CreateContainerConfigError",
+								},
+							},
+						},
+					},
+				},
+			},
+			Want: `
+				kube_pod_container_status_waiting_reason{container="container7",namespace="ns6",pod="pod6",reason="CreateContainerConfigError"} 1
+				`,
+			MetricNames: []string{
+				"kube_pod_container_status_waiting_reason",
+			},
+		},
 		{
 
 			Obj: &v1.Pod{
@@ -751,8 +848,9 @@ kube_pod_container_status_last_terminated_reason{container="container7",namespac
 				kube_pod_status_scheduled{condition="false",namespace="ns1",pod="pod1"} 0
 				kube_pod_status_scheduled{condition="true",namespace="ns1",pod="pod1"} 1
 				kube_pod_status_scheduled{condition="unknown",namespace="ns1",pod="pod1"} 0
+				kube_pod_status_unschedulable{namespace="ns1",pod="pod1"} 0
 			`,
-			MetricNames: []string{"kube_pod_status_scheduled", "kube_pod_status_scheduled_time"},
+			MetricNames: []string{"kube_pod_status_scheduled", "kube_pod_status_scheduled_time", "kube_pod_status_unschedulable"},
 		},
 		{
 			Obj: &v1.Pod{
@@ -773,8 +871,83 @@ kube_pod_container_status_last_terminated_reason{container="container7",namespac
 				kube_pod_status_scheduled{condition="false",namespace="ns2",pod="pod2"} 1
 				kube_pod_status_scheduled{condition="true",namespace="ns2",pod="pod2"} 0
 				kube_pod_status_scheduled{condition="unknown",namespace="ns2",pod="pod2"} 0
+				kube_pod_status_unschedulable{namespace="ns2",pod="pod2"} 0
 			`,
-			MetricNames: []string{"kube_pod_status_scheduled", "kube_pod_status_scheduled_time"},
+			MetricNames: []string{"kube_pod_status_scheduled", "kube_pod_status_scheduled_time", "kube_pod_status_unschedulable"},
+		},
+		{
+			// A pod with a custom readiness gate, whose condition is set
+			// by an external controller.
+			Obj: &v1.Pod{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "pod4",
+					Namespace: "ns4",
+				},
+				Spec: v1.PodSpec{
+					ReadinessGates: []v1.PodReadinessGate{
+						{ConditionType: "www.example.com/feature-1"},
+					},
+				},
+				Status: v1.PodStatus{
+					Conditions: []v1.PodCondition{
+						{Type: v1.PodInitialized, Status: v1.ConditionTrue},
+						{Type: v1.ContainersReady, Status: v1.ConditionTrue},
+						{Type: "www.example.com/feature-1", Status: v1.ConditionFalse},
+						{Type: v1.PodReady, Status: v1.ConditionFalse},
+					},
+				},
+			},
+			Want: metadata + `
+				kube_pod_status_condition{condition="ContainersReady",namespace="ns4",pod="pod4",status="false"} 0
+				kube_pod_status_condition{condition="ContainersReady",namespace="ns4",pod="pod4",status="true"} 1
+				kube_pod_status_condition{condition="ContainersReady",namespace="ns4",pod="pod4",status="unknown"} 0
+				kube_pod_status_condition{condition="Initialized",namespace="ns4",pod="pod4",status="false"} 0
+				kube_pod_status_condition{condition="Initialized",namespace="ns4",pod="pod4",status="true"} 1
+				kube_pod_status_condition{condition="Initialized",namespace="ns4",pod="pod4",status="unknown"} 0
+				kube_pod_status_condition{condition="Ready",namespace="ns4",pod="pod4",status="false"} 1
+				kube_pod_status_condition{condition="Ready",namespace="ns4",pod="pod4",status="true"} 0
+				kube_pod_status_condition{condition="Ready",namespace="ns4",pod="pod4",status="unknown"} 0
+				kube_pod_status_condition{condition="www.example.com/feature-1",namespace="ns4",pod="pod4",status="false"} 1
+				kube_pod_status_condition{condition="www.example.com/feature-1",namespace="ns4",pod="pod4",status="true"} 0
+				kube_pod_status_condition{condition="www.example.com/feature-1",namespace="ns4",pod="pod4",status="unknown"} 0
+				kube_pod_status_ready{condition="false",namespace="ns4",pod="pod4"} 1
+				kube_pod_status_ready{condition="true",namespace="ns4",pod="pod4"} 0
+				kube_pod_status_ready{condition="unknown",namespace="ns4",pod="pod4"} 0
+			`,
+			MetricNames: []string{"kube_pod_status_condition", "kube_pod_status_ready"},
+		},
+		{
+			// A pending pod the scheduler found no fitting node for has no
+			// scheduled time, only its creation time.
+			Obj: &v1.Pod{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:              "pod3",
+					Namespace:         "ns3",
+					CreationTimestamp: metav1.Time{Time: time.Unix(1501569018, 0)},
+				},
+				Status: v1.PodStatus{
+					Phase: v1.PodPending,
+					Conditions: []v1.PodCondition{
+						v1.PodCondition{
+							Type:    v1.PodScheduled,
+							Status:  v1.ConditionFalse,
+							Reason:  v1.PodReasonUnschedulable,
+							Message: "0/3 nodes are available: 3 Insufficient cpu.",
+							LastTransitionTime: metav1.Time{
+								Time: time.Unix(1501569018, 0),
+							},
+						},
+					},
+				},
+			},
+			Want: metadata + `
+				kube_pod_created{namespace="ns3",pod="pod3"} 1.501569018e+09
+				kube_pod_status_scheduled{condition="false",namespace="ns3",pod="pod3"} 1
+				kube_pod_status_scheduled{condition="true",namespace="ns3",pod="pod3"} 0
+				kube_pod_status_scheduled{condition="unknown",namespace="ns3",pod="pod3"} 0
+				kube_pod_status_unschedulable{namespace="ns3",pod="pod3"} 1
+			`,
+			MetricNames: []string{"kube_pod_created", "kube_pod_status_scheduled", "kube_pod_status_scheduled_time", "kube_pod_status_unschedulable"},
 		},
 		{
 			Obj: &v1.Pod{
@@ -853,6 +1026,44 @@ kube_pod_container_status_last_terminated_reason{container="container7",namespac
 				"kube_pod_container_resource_limits",
 			},
 		},
+		{
+			// Extended resources are exposed as integers, huge pages in
+			// bytes.
+			Obj: &v1.Pod{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "pod7",
+					Namespace: "ns7",
+				},
+				Spec: v1.PodSpec{
+					NodeName: "node7",
+					Containers: []v1.Container{
+						{
+							Name: "pod7_con1",
+							Resources: v1.ResourceRequirements{
+								Requests: map[v1.ResourceName]resource.Quantity{
+									v1.ResourceName("nvidia.com/gpu"): resource.MustParse("2"),
+									v1.ResourceName("hugepages-2Mi"):  resource.MustParse("4Mi"),
+								},
+								Limits: map[v1.ResourceName]resource.Quantity{
+									v1.ResourceName("nvidia.com/gpu"): resource.MustParse("2"),
+									v1.ResourceName("hugepages-2Mi"):  resource.MustParse("4Mi"),
+								},
+							},
+						},
+					},
+				},
+			},
+			Want: metadata + `
+				kube_pod_container_resource_limits{container="pod7_con1",namespace="ns7",node="node7",pod="pod7",resource="hugepages_2Mi",unit="byte"} 4.194304e+06
+				kube_pod_container_resource_limits{container="pod7_con1",namespace="ns7",node="node7",pod="pod7",resource="nvidia_com_gpu",unit="integer"} 2
+				kube_pod_container_resource_requests{container="pod7_con1",namespace="ns7",node="node7",pod="pod7",resource="hugepages_2Mi",unit="byte"} 4.194304e+06
+				kube_pod_container_resource_requests{container="pod7_con1",namespace="ns7",node="node7",pod="pod7",resource="nvidia_com_gpu",unit="integer"} 2
+		`,
+			MetricNames: []string{
+				"kube_pod_container_resource_requests",
+				"kube_pod_container_resource_limits",
+			},
+		},
 		{
 
 			Obj: &v1.Pod{
@@ -933,6 +1144,176 @@ kube_pod_container_status_last_terminated_reason{container="container7",namespac
 				"kube_pod_container_resource_limits",
 			},
 		},
+		{
+			Obj: &v1.Pod{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "pod3",
+					Namespace: "ns3",
+				},
+				Spec: v1.PodSpec{
+					NodeName: "node3",
+					InitContainers: []v1.Container{
+						v1.Container{
+							Name: "pod3_init",
+							Resources: v1.ResourceRequirements{
+								Requests: map[v1.ResourceName]resource.Quantity{
+									v1.ResourceEphemeralStorage: resource.MustParse("100M"),
+								},
+								Limits: map[v1.ResourceName]resource.Quantity{
+									v1.ResourceEphemeralStorage: resource.MustParse("200M"),
+								},
+							},
+						},
+					},
+					Containers: []v1.Container{
+						// A container with only an ephemeral-storage limit. No
+						// requests series will be emitted for that.
+						v1.Container{
+							Name: "pod3_con1",
+							Resources: v1.ResourceRequirements{
+								Limits: map[v1.ResourceName]resource.Quantity{
+									v1.ResourceEphemeralStorage: resource.MustParse("500M"),
+								},
+							},
+						},
+					},
+				},
+			},
+			Want: metadata + `
+				kube_pod_init_container_resource_requests{container="pod3_init",namespace="ns3",node="node3",pod="pod3",resource="ephemeral_storage",unit="byte"} 1e+08
+				kube_pod_init_container_resource_limits{container="pod3_init",namespace="ns3",node="node3",pod="pod3",resource="ephemeral_storage",unit="byte"} 2e+08
+				kube_pod_container_resource_limits{container="pod3_con1",namespace="ns3",node="node3",pod="pod3",resource="ephemeral_storage",unit="byte"} 5e+08
+		`,
+			MetricNames: []string{
+				"kube_pod_container_resource_requests",
+				"kube_pod_container_resource_limits",
+				"kube_pod_init_container_resource_requests",
+				"kube_pod_init_container_resource_limits",
+			},
+		},
+		{
+			// A pod with two init containers, the first one completed after
+			// a restart, the second one still running.
+			Obj: &v1.Pod{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "pod_init",
+					Namespace: "ns1",
+				},
+				Spec: v1.PodSpec{
+					NodeName: "node1",
+					InitContainers: []v1.Container{
+						v1.Container{
+							Name: "init1",
+							Resources: v1.ResourceRequirements{
+								Requests: map[v1.ResourceName]resource.Quantity{
+									v1.ResourceCPU: resource.MustParse("100m"),
+								},
+							},
+						},
+						v1.Container{
+							Name: "init2",
+							Resources: v1.ResourceRequirements{
+								Limits: map[v1.ResourceName]resource.Quantity{
+									v1.ResourceMemory: resource.MustParse("100M"),
+								},
+							},
+						},
+					},
+					Containers: []v1.Container{
+						v1.Container{
+							Name: "container1",
+						},
+					},
+				},
+				Status: v1.PodStatus{
+					InitContainerStatuses: []v1.ContainerStatus{
+						v1.ContainerStatus{
+							Name:         "init1",
+							Image:        "k8s.gcr.io/busybox",
+							ImageID:      "docker://sha256:aaa",
+							ContainerID:  "docker://init1",
+							RestartCount: 1,
+							State: v1.ContainerState{
+								Terminated: &v1.ContainerStateTerminated{
+									Reason: "Completed",
+								},
+							},
+							LastTerminationState: v1.ContainerState{
+								Terminated: &v1.ContainerStateTerminated{
+									Reason:   "Error",
+									ExitCode: 1,
+								},
+							},
+						},
+						v1.ContainerStatus{
+							Name:        "init2",
+							Image:       "k8s.gcr.io/busybox",
+							ImageID:     "docker://sha256:aaa",
+							ContainerID: "docker://init2",
+							State: v1.ContainerState{
+								Running: &v1.ContainerStateRunning{},
+							},
+						},
+					},
+					ContainerStatuses: []v1.ContainerStatus{
+						v1.ContainerStatus{
+							Name: "container1",
+							State: v1.ContainerState{
+								Waiting: &v1.ContainerStateWaiting{
+									Reason: "PodInitializing",
+								},
+							},
+						},
+					},
+				},
+			},
+			Want: metadata + `
+				kube_pod_container_status_ready{container="container1",namespace="ns1",pod="pod_init"} 0
+				kube_pod_init_container_info{container="init1",container_id="docker://init1",image="k8s.gcr.io/busybox",image_id="docker://sha256:aaa",namespace="ns1",pod="pod_init"} 1
+				kube_pod_init_container_info{container="init2",container_id="docker://init2",image="k8s.gcr.io/busybox",image_id="docker://sha256:aaa",namespace="ns1",pod="pod_init"} 1
+				kube_pod_init_container_status_waiting{container="init1",namespace="ns1",pod="pod_init"} 0
+				kube_pod_init_container_status_waiting{container="init2",namespace="ns1",pod="pod_init"} 0
+				kube_pod_init_container_status_running{container="init1",namespace="ns1",pod="pod_init"} 0
+				kube_pod_init_container_status_running{container="init2",namespace="ns1",pod="pod_init"} 1
+				kube_pod_init_container_status_terminated{container="init1",namespace="ns1",pod="pod_init"} 1
+				kube_pod_init_container_status_terminated{container="init2",namespace="ns1",pod="pod_init"} 0
+				kube_pod_init_container_status_terminated_reason{container="init1",namespace="ns1",pod="pod_init",reason="Completed"} 1
+				kube_pod_init_container_status_terminated_reason{container="init1",namespace="ns1",pod="pod_init",reason="ContainerCannotRun"} 0
+				kube_pod_init_container_status_terminated_reason{container="init1",namespace="ns1",pod="pod_init",reason="Error"} 0
+				kube_pod_init_container_status_terminated_reason{container="init1",namespace="ns1",pod="pod_init",reason="OOMKilled"} 0
+				kube_pod_init_container_status_terminated_reason{container="init2",namespace="ns1",pod="pod_init",reason="Completed"} 0
+				kube_pod_init_container_status_terminated_reason{container="init2",namespace="ns1",pod="pod_init",reason="ContainerCannotRun"} 0
+				kube_pod_init_container_status_terminated_reason{container="init2",namespace="ns1",pod="pod_init",reason="Error"} 0
+				kube_pod_init_container_status_terminated_reason{container="init2",namespace="ns1",pod="pod_init",reason="OOMKilled"} 0
+				kube_pod_init_container_status_last_terminated_reason{container="init1",namespace="ns1",pod="pod_init",reason="Completed"} 0
+				kube_pod_init_container_status_last_terminated_reason{container="init1",namespace="ns1",pod="pod_init",reason="ContainerCannotRun"} 0
+				kube_pod_init_container_status_last_terminated_reason{container="init1",namespace="ns1",pod="pod_init",reason="Error"} 1
+				kube_pod_init_container_status_last_terminated_reason{container="init1",namespace="ns1",pod="pod_init",reason="OOMKilled"} 0
+				kube_pod_init_container_status_last_terminated_exitcode{container="init1",namespace="ns1",pod="pod_init"} 1
+				kube_pod_init_container_status_ready{container="init1",namespace="ns1",pod="pod_init"} 0
+				kube_pod_init_container_status_ready{container="init2",namespace="ns1",pod="pod_init"} 0
+				kube_pod_init_container_status_restarts_total{container="init1",namespace="ns1",pod="pod_init"} 1
+				kube_pod_init_container_status_restarts_total{container="init2",namespace="ns1",pod="pod_init"} 0
+				kube_pod_init_container_resource_requests{container="init1",namespace="ns1",node="node1",pod="pod_init",resource="cpu",unit="core"} 0.1
+				kube_pod_init_container_resource_limits{container="init2",namespace="ns1",node="node1",pod="pod_init",resource="memory",unit="byte"} 1e+08
+		`,
+			MetricNames: []string{
+				"kube_pod_container_status_ready",
+				"kube_pod_container_resource_requests",
+				"kube_pod_container_resource_limits",
+				"kube_pod_init_container_info",
+				"kube_pod_init_container_status_waiting",
+				"kube_pod_init_container_status_waiting_reason",
+				"kube_pod_init_container_status_running",
+				"kube_pod_init_container_status_terminated",
+				"kube_pod_init_container_status_last_terminated_reason",
+				"kube_pod_init_container_status_last_terminated_exitcode",
+				"kube_pod_init_container_status_ready",
+				"kube_pod_init_container_status_restarts_total",
+				"kube_pod_init_container_resource_requests",
+				"kube_pod_init_container_resource_limits",
+			},
+		},
 		{
 			Obj: &v1.Pod{
 				ObjectMeta: metav1.ObjectMeta{
@@ -1003,14 +1384,397 @@ kube_pod_container_status_last_terminated_reason{container="container7",namespac
 				"kube_pod_spec_volumes_persistentvolumeclaims_readonly",
 			},
 		},
+		{
+			Obj: &v1.Pod{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "pod1",
+					Namespace: "ns1",
+				},
+				Spec: v1.PodSpec{
+					Tolerations: []v1.Toleration{
+						{
+							Key:      "dedicated",
+							Operator: v1.TolerationOpEqual,
+							Value:    "gpu",
+							Effect:   v1.TaintEffectNoSchedule,
+						},
+						{
+							Key:               "node.kubernetes.io/unreachable",
+							Operator:          v1.TolerationOpExists,
+							Effect:            v1.TaintEffectNoExecute,
+							TolerationSeconds: &tolerationSeconds,
+						},
+						{
+							Operator: v1.TolerationOpExists,
+						},
+					},
+				},
+			},
+			Want: metadata + `
+				kube_pod_spec_tolerations{effect="",key="",namespace="ns1",operator="Exists",pod="pod1",toleration_seconds="",value=""} 1
+				kube_pod_spec_tolerations{effect="NoExecute",key="node.kubernetes.io/unreachable",namespace="ns1",operator="Exists",pod="pod1",toleration_seconds="300",value=""} 1
+				kube_pod_spec_tolerations{effect="NoSchedule",key="dedicated",namespace="ns1",operator="Equal",pod="pod1",toleration_seconds="",value="gpu"} 1
+			`,
+			MetricNames: []string{
+				"kube_pod_spec_tolerations",
+			},
+		},
+		{
+			Obj: &v1.Pod{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "pod2",
+					Namespace: "ns1",
+				},
+				Spec: v1.PodSpec{
+					Volumes: []v1.Volume{
+						v1.Volume{
+							Name: "not-pvc-vol",
+							VolumeSource: v1.VolumeSource{
+								EmptyDir: &v1.EmptyDirVolumeSource{},
+							},
+						},
+					},
+				},
+			},
+			Want: metadata,
+			MetricNames: []string{
+				"kube_pod_spec_volumes_persistentvolumeclaims_info",
+				"kube_pod_spec_volumes_persistentvolumeclaims_readonly",
+			},
+		},
+		{
+			Obj: &v1.Pod{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "pod1",
+					Namespace: "ns1",
+				},
+				Spec: v1.PodSpec{
+					Containers: []v1.Container{
+						v1.Container{
+							Name: "container1",
+							Resources: v1.ResourceRequirements{
+								Requests: map[v1.ResourceName]resource.Quantity{
+									v1.ResourceCPU: resource.MustParse("100m"),
+								},
+							},
+						},
+					},
+				},
+				Status: v1.PodStatus{
+					QOSClass: v1.PodQOSGuaranteed,
+				},
+			},
+			Want: `
+				kube_pod_status_qos_class{namespace="ns1",pod="pod1",qos_class="Guaranteed"} 1
+		`,
+			MetricNames: []string{"kube_pod_status_qos_class"},
+		},
+		{
+			Obj: &v1.Pod{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "pod2",
+					Namespace: "ns2",
+				},
+				Spec: v1.PodSpec{
+					Containers: []v1.Container{
+						v1.Container{
+							Name: "container1",
+							Resources: v1.ResourceRequirements{
+								Requests: map[v1.ResourceName]resource.Quantity{
+									v1.ResourceCPU:    resource.MustParse("200m"),
+									v1.ResourceMemory: resource.MustParse("100M"),
+								},
+								Limits: map[v1.ResourceName]resource.Quantity{
+									v1.ResourceCPU:    resource.MustParse("200m"),
+									v1.ResourceMemory: resource.MustParse("100M"),
+								},
+							},
+						},
+					},
+				},
+			},
+			Want: `
+				kube_pod_status_qos_class{namespace="ns2",pod="pod2",qos_class="Guaranteed"} 1
+		`,
+			MetricNames: []string{"kube_pod_status_qos_class"},
+		},
+		{
+			Obj: &v1.Pod{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "pod3",
+					Namespace: "ns3",
+				},
+				Spec: v1.PodSpec{
+					Containers: []v1.Container{
+						v1.Container{
+							Name: "container1",
+							Resources: v1.ResourceRequirements{
+								Requests: map[v1.ResourceName]resource.Quantity{
+									v1.ResourceCPU: resource.MustParse("100m"),
+								},
+								Limits: map[v1.ResourceName]resource.Quantity{
+									v1.ResourceCPU: resource.MustParse("200m"),
+								},
+							},
+						},
+					},
+				},
+			},
+			Want: `
+				kube_pod_status_qos_class{namespace="ns3",pod="pod3",qos_class="Burstable"} 1
+		`,
+			MetricNames: []string{"kube_pod_status_qos_class"},
+		},
+		{
+			Obj: &v1.Pod{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "pod4",
+					Namespace: "ns4",
+				},
+				Spec: v1.PodSpec{
+					Containers: []v1.Container{
+						v1.Container{
+							Name: "container1",
+						},
+					},
+				},
+			},
+			Want: `
+				kube_pod_status_qos_class{namespace="ns4",pod="pod4",qos_class="BestEffort"} 1
+		`,
+			MetricNames: []string{"kube_pod_status_qos_class"},
+		},
 	}
 
 	for i, c := range cases {
 		c.Func = func(obj interface{}) []*metrics.Metric {
-			return generatePodMetrics(false, obj)
+			return generatePodMetrics(false, false, nil, nil, obj)
 		}
 		if err := c.run(); err != nil {
 			t.Errorf("unexpected collecting result in %vth run:\n%s", i, err)
 		}
 	}
 }
+
+func TestPodCollectorInfoMetrics(t *testing.T) {
+	pod := &v1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "pod1",
+			Namespace: "ns1",
+			UID:       "abc-123-xxx",
+		},
+		Spec: v1.PodSpec{
+			NodeName: "node1",
+			Containers: []v1.Container{
+				{
+					Name: "pod1_con1",
+					Resources: v1.ResourceRequirements{
+						Requests: v1.ResourceList{
+							v1.ResourceCPU: resource.MustParse("200m"),
+						},
+						Limits: v1.ResourceList{
+							v1.ResourceMemory: resource.MustParse("100M"),
+						},
+					},
+				},
+			},
+		},
+	}
+	metricNames := []string{"kube_pod_info", "kube_pod_container_resource_"}
+
+	tests := []struct {
+		Desc           string
+		UseInfoMetrics bool
+		Want           string
+	}{
+		{
+			Desc:           "node on every resource metric",
+			UseInfoMetrics: false,
+			Want: `
+				kube_pod_container_resource_limits{container="pod1_con1",namespace="ns1",node="node1",pod="pod1",resource="memory",unit="byte"} 1e+08
+				kube_pod_container_resource_limits_memory_bytes{container="pod1_con1",namespace="ns1",node="node1",pod="pod1"} 1e+08
+				kube_pod_container_resource_requests{container="pod1_con1",namespace="ns1",node="node1",pod="pod1",resource="cpu",unit="core"} 0.2
+				kube_pod_container_resource_requests_cpu_cores{container="pod1_con1",namespace="ns1",node="node1",pod="pod1"} 0.2
+				kube_pod_info{created_by_kind="<none>",created_by_name="<none>",host_ip="",namespace="ns1",node="node1",pod="pod1",pod_ip="",uid="abc-123-xxx"} 1
+`,
+		},
+		{
+			Desc:           "node on kube_pod_info only",
+			UseInfoMetrics: true,
+			Want: `
+				kube_pod_container_resource_limits{container="pod1_con1",namespace="ns1",pod="pod1",resource="memory",unit="byte"} 1e+08
+				kube_pod_container_resource_limits_memory_bytes{container="pod1_con1",namespace="ns1",pod="pod1"} 1e+08
+				kube_pod_container_resource_requests{container="pod1_con1",namespace="ns1",pod="pod1",resource="cpu",unit="core"} 0.2
+				kube_pod_container_resource_requests_cpu_cores{container="pod1_con1",namespace="ns1",pod="pod1"} 0.2
+				kube_pod_info{created_by_kind="<none>",created_by_name="<none>",host_ip="",namespace="ns1",node="node1",pod="pod1",pod_ip="",uid="abc-123-xxx"} 1
+`,
+		},
+	}
+
+	for _, test := range tests {
+		useInfoMetrics := test.UseInfoMetrics
+		c := generateMetricsTestCase{
+			Obj:         pod,
+			MetricNames: metricNames,
+			Want:        test.Want,
+			Func: func(obj interface{}) []*metrics.Metric {
+				return generatePodMetrics(false, useInfoMetrics, nil, nil, obj)
+			},
+		}
+		if err := c.run(); err != nil {
+			t.Errorf("unexpected collecting result for %s:\n%s", test.Desc, err)
+		}
+	}
+}
+
+func TestPodCollectorAnnotations(t *testing.T) {
+	pod := &v1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "pod1",
+			Namespace: "ns1",
+			Annotations: map[string]string{
+				"prometheus.io/scrape": "true",
+				"example.com/team":     "foo",
+				"kubectl.kubernetes.io/last-applied-configuration": `{"apiVersion":"v1","kind":"Pod"}`,
+			},
+		},
+	}
+
+	tests := []struct {
+		Desc               string
+		AllowedAnnotations []string
+		Want               string
+	}{
+		{
+			Desc: "annotations suppressed by default",
+			Want: "",
+		},
+		{
+			Desc:               "allowed annotations only",
+			AllowedAnnotations: []string{"prometheus.io/scrape", "example.com/team", "example.com/missing"},
+			Want: `
+				kube_pod_annotations{annotation_example_com_team="foo",annotation_prometheus_io_scrape="true",namespace="ns1",pod="pod1"} 1
+`,
+		},
+		{
+			Desc:               "no allowed annotation set",
+			AllowedAnnotations: []string{},
+			Want: `
+				kube_pod_annotations{namespace="ns1",pod="pod1"} 1
+`,
+		},
+	}
+
+	for _, test := range tests {
+		allowedAnnotations := test.AllowedAnnotations
+		c := generateMetricsTestCase{
+			Obj:         pod,
+			MetricNames: []string{"kube_pod_annotations"},
+			Want:        test.Want,
+			Func: func(obj interface{}) []*metrics.Metric {
+				return generatePodMetrics(false, false, allowedAnnotations, nil, obj)
+			},
+		}
+		if err := c.run(); err != nil {
+			t.Errorf("unexpected collecting result for %s:\n%s", test.Desc, err)
+		}
+	}
+}
+
+// BenchmarkMetricsStoreResync measures relisting pods into a metrics store,
+// with the metrics of unchanged pods kept by their UID and resource version,
+// and without, as for pods lacking a resource version. runs/op reports how
+// often the pod metrics are generated per relist.
+func BenchmarkMetricsStoreResync(b *testing.B) {
+	const pods = 1000
+	benchmarks := []struct {
+		Name string
+		// Version returns the resource version of the i-th pod in the n-th
+		// of two alternating lists.
+		Version func(i, n int) string
+	}{
+		{Name: "cached unchanged", Version: func(i, n int) string { return "1" }},
+		{Name: "cached 10% changed", Version: func(i, n int) string {
+			if i%10 == 0 {
+				return strconv.Itoa(n + 1)
+			}
+			return "1"
+		}},
+		{Name: "uncached", Version: func(i, n int) string { return "" }},
+	}
+
+	for _, bm := range benchmarks {
+		b.Run(bm.Name, func(b *testing.B) {
+			lists := [2][]interface{}{}
+			for n := range lists {
+				lists[n] = make([]interface{}, pods)
+				for i := range lists[n] {
+					lists[n][i] = benchmarkPod(i, bm.Version(i, n))
+				}
+			}
+
+			runs := 0
+			s := metricsstore.NewMetricsStore(func(obj interface{}) []*metrics.Metric {
+				runs++
+				return generatePodMetrics(false, false, nil, nil, obj)
+			})
+			s.Replace(lists[1], "")
+			runs = 0
+
+			b.ResetTimer()
+			for n := 0; n < b.N; n++ {
+				s.Replace(lists[n%2], "")
+			}
+			b.StopTimer()
+			b.ReportMetric(float64(runs)/float64(b.N), "runs/op")
+		})
+	}
+}
+
+// benchmarkPod returns the i-th pod of BenchmarkMetricsStoreResync, running
+// two containers with resource requests and limits.
+func benchmarkPod(i int, resourceVersion string) *v1.Pod {
+	resources := v1.ResourceRequirements{
+		Requests: v1.ResourceList{
+			v1.ResourceCPU:    resource.MustParse("100m"),
+			v1.ResourceMemory: resource.MustParse("128Mi"),
+		},
+		Limits: v1.ResourceList{
+			v1.ResourceCPU:    resource.MustParse("500m"),
+			v1.ResourceMemory: resource.MustParse("256Mi"),
+		},
+	}
+	name := "pod" + strconv.Itoa(i)
+	return &v1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:              name,
+			Namespace:         "default",
+			UID:               types.UID("uid" + strconv.Itoa(i)),
+			ResourceVersion:   resourceVersion,
+			CreationTimestamp: metav1.Unix(1500000000, 0),
+			Labels:            map[string]string{"app": "benchmark"},
+			OwnerReferences:   []metav1.OwnerReference{{Kind: "ReplicaSet", Name: "benchmark"}},
+		},
+		Spec: v1.PodSpec{
+			NodeName: "node1",
+			Containers: []v1.Container{
+				{Name: "app", Image: "app:1", Resources: resources},
+				{Name: "sidecar", Image: "sidecar:1", Resources: resources},
+			},
+		},
+		Status: v1.PodStatus{
+			Phase:     v1.PodRunning,
+			HostIP:    "10.0.0.1",
+			PodIP:     "10.1.0." + strconv.Itoa(i%256),
+			StartTime: &metav1.Time{Time: time.Unix(1500000000, 0)},
+			Conditions: []v1.PodCondition{
+				{Type: v1.PodReady, Status: v1.ConditionTrue},
+				{Type: v1.PodScheduled, Status: v1.ConditionTrue},
+			},
+			ContainerStatuses: []v1.ContainerStatus{
+				{Name: "app", Image: "app:1", ContainerID: "docker://app" + strconv.Itoa(i), Ready: true, State: v1.ContainerState{Running: &v1.ContainerStateRunning{}}},
+				{Name: "sidecar", Image: "sidecar:1", ContainerID: "docker://sidecar" + strconv.Itoa(i), Ready: true, State: v1.ContainerState{Running: &v1.ContainerStateRunning{}}},
+			},
+		},
+	}
+}
@@ -0,0 +1,97 @@
+/*
+Copyright 2018 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package collectors
+
+import (
+	"k8s.io/kube-state-metrics/pkg/metrics"
+
+	scheduling "k8s.io/api/scheduling/v1beta1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/watch"
+	clientset "k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/cache"
+)
+
+var (
+	descPriorityClassLabelsDefaultLabels = []string{"priorityclass"}
+
+	descPriorityClassInfo = newMetricFamilyDef(
+		"kube_priorityclass_info",
+		"Information about priorityclass.",
+		descPriorityClassLabelsDefaultLabels,
+		nil,
+	)
+	descPriorityClassValue = newMetricFamilyDef(
+		"kube_priorityclass_value",
+		"Priority value of the priorityclass, which pods using it receive.",
+		descPriorityClassLabelsDefaultLabels,
+		nil,
+	)
+	descPriorityClassCreated = newMetricFamilyDef(
+		"kube_priorityclass_created",
+		"Unix creation timestamp",
+		descPriorityClassLabelsDefaultLabels,
+		nil,
+	)
+	descPriorityClassIsGlobalDefault = newMetricFamilyDef(
+		"kube_priorityclass_is_global_default",
+		"Whether the priorityclass is the default for pods without a priority class.",
+		descPriorityClassLabelsDefaultLabels,
+		nil,
+	)
+)
+
+func createPriorityClassListWatch(kubeClient clientset.Interface, ns string) cache.ListWatch {
+	return cache.ListWatch{
+		ListFunc: func(opts metav1.ListOptions) (runtime.Object, error) {
+			return kubeClient.SchedulingV1beta1().PriorityClasses().List(opts)
+		},
+		WatchFunc: func(opts metav1.ListOptions) (watch.Interface, error) {
+			return kubeClient.SchedulingV1beta1().PriorityClasses().Watch(opts)
+		},
+	}
+}
+
+func generatePriorityClassMetrics(obj interface{}) []*metrics.Metric {
+	ms := []*metrics.Metric{}
+
+	// TODO: Refactor
+	pPointer := obj.(*scheduling.PriorityClass)
+	p := *pPointer
+
+	addGauge := func(desc *metricFamilyDef, v float64, lv ...string) {
+		lv = append([]string{p.Name}, lv...)
+
+		m, err := metrics.NewMetric(desc.Name, desc.LabelKeys, lv, v)
+		if err != nil {
+			panic(err)
+		}
+
+		ms = append(ms, m)
+	}
+
+	addGauge(descPriorityClassInfo, 1)
+	addGauge(descPriorityClassValue, float64(p.Value))
+	addGauge(descPriorityClassIsGlobalDefault, boolFloat64(p.GlobalDefault))
+
+	if !p.CreationTimestamp.IsZero() {
+		addGauge(descPriorityClassCreated, float64(p.CreationTimestamp.Unix()))
+	}
+
+	return ms
+}
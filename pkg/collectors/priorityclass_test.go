@@ -0,0 +1,77 @@
+/*
+Copyright 2018 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package collectors
+
+import (
+	"testing"
+	"time"
+
+	scheduling "k8s.io/api/scheduling/v1beta1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestPriorityClassCollector(t *testing.T) {
+	// Fixed metadata on type and help text. We prepend this to every expected
+	// output so we only have to modify a single place when doing adjustments.
+	const metadata = `
+		# HELP kube_priorityclass_info Information about priorityclass.
+		# TYPE kube_priorityclass_info gauge
+		# HELP kube_priorityclass_value Priority value of the priorityclass, which pods using it receive.
+		# TYPE kube_priorityclass_value gauge
+		# HELP kube_priorityclass_created Unix creation timestamp
+		# TYPE kube_priorityclass_created gauge
+		# HELP kube_priorityclass_is_global_default Whether the priorityclass is the default for pods without a priority class.
+		# TYPE kube_priorityclass_is_global_default gauge
+	`
+	cases := []generateMetricsTestCase{
+		{
+			Obj: &scheduling.PriorityClass{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:              "high-priority",
+					CreationTimestamp: metav1.Time{Time: time.Unix(1500000000, 0)},
+				},
+				Value: 1000000,
+			},
+			Want: `
+				kube_priorityclass_created{priorityclass="high-priority"} 1.5e+09
+				kube_priorityclass_info{priorityclass="high-priority"} 1
+				kube_priorityclass_is_global_default{priorityclass="high-priority"} 0
+				kube_priorityclass_value{priorityclass="high-priority"} 1e+06
+`,
+		},
+		{
+			Obj: &scheduling.PriorityClass{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: "default-priority",
+				},
+				Value:         -10,
+				GlobalDefault: true,
+			},
+			Want: `
+				kube_priorityclass_info{priorityclass="default-priority"} 1
+				kube_priorityclass_is_global_default{priorityclass="default-priority"} 1
+				kube_priorityclass_value{priorityclass="default-priority"} -10
+`,
+		},
+	}
+	for i, c := range cases {
+		c.Func = generatePriorityClassMetrics
+		if err := c.run(); err != nil {
+			t.Errorf("unexpected collecting result in %vth run:\n%s", i, err)
+		}
+	}
+}
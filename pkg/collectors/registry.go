@@ -0,0 +1,176 @@
+/*
+Copyright 2018 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package collectors
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/net/context"
+)
+
+// syncPollInterval is the interval WaitForSync checks the collectors in.
+const syncPollInterval = 100 * time.Millisecond
+
+// Registry holds the collectors exposed by the metrics endpoint. In addition
+// to the static collectors it was created with, groups of collectors can be
+// added, replaced and removed at runtime, e.g. the collectors of a reloaded
+// custom resource config file.
+type Registry struct {
+	mutex  sync.RWMutex
+	static []*Collector
+	groups map[string]collectorGroup
+}
+
+type collectorGroup struct {
+	collectors []*Collector
+	stop       func()
+}
+
+// NewRegistry returns a new Registry holding the given static collectors.
+func NewRegistry(static []*Collector) *Registry {
+	return &Registry{
+		static: static,
+		groups: map[string]collectorGroup{},
+	}
+}
+
+// Set adds the given group of collectors, replacing any previous group with
+// the same key. The stop function of the replaced group is called, it should
+// stop the reflectors feeding its collectors.
+func (r *Registry) Set(key string, collectors []*Collector, stop func()) {
+	r.mutex.Lock()
+	old, ok := r.groups[key]
+	r.groups[key] = collectorGroup{collectors: collectors, stop: stop}
+	r.mutex.Unlock()
+
+	if ok && old.stop != nil {
+		old.stop()
+	}
+}
+
+// Delete removes the group of collectors with the given key and calls its
+// stop function.
+func (r *Registry) Delete(key string) {
+	r.mutex.Lock()
+	old, ok := r.groups[key]
+	delete(r.groups, key)
+	r.mutex.Unlock()
+
+	if ok && old.stop != nil {
+		old.stop()
+	}
+}
+
+// Collectors returns the static collectors followed by the collectors of all
+// groups, ordered by group key.
+func (r *Registry) Collectors() []*Collector {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	keys := make([]string, 0, len(r.groups))
+	for k := range r.groups {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	collectors := append([]*Collector{}, r.static...)
+	for _, k := range keys {
+		collectors = append(collectors, r.groups[k].collectors...)
+	}
+	return collectors
+}
+
+// WaitForSync waits until all collectors have synced. It returns an error
+// naming the collectors which have not synced within timeout, or once ctx is
+// done.
+func (r *Registry) WaitForSync(ctx context.Context, timeout time.Duration) error {
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+	ticker := time.NewTicker(syncPollInterval)
+	defer ticker.Stop()
+
+	for {
+		if r.HasSynced() {
+			return nil
+		}
+		select {
+		case <-ticker.C:
+		case <-timer.C:
+			return fmt.Errorf("collectors %s did not sync within %v", strings.Join(r.unsynced(), ","), timeout)
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+// UpdateWatchCacheMetrics sets the watch cache metrics to the number of objects
+// held by the collectors of each resource, summed across clusters. Collectors
+// without watched objects are skipped.
+func (r *Registry) UpdateWatchCacheMetrics() {
+	counts := map[string]int{}
+	for _, c := range r.Collectors() {
+		if n, ok := c.objects(); ok {
+			counts[c.Name()] += n
+		}
+	}
+
+	WatchCacheObjectsMetric.Reset()
+	for name, n := range counts {
+		WatchCacheObjectsMetric.WithLabelValues(name).Set(float64(n))
+	}
+}
+
+// RunWatchCacheMetrics updates the watch cache metrics every interval until ctx
+// is done.
+func (r *Registry) RunWatchCacheMetrics(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		r.UpdateWatchCacheMetrics()
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// unsynced returns the names of the collectors which have not synced.
+func (r *Registry) unsynced() []string {
+	names := []string{}
+	for _, c := range r.Collectors() {
+		if !c.HasSynced() {
+			names = append(names, c.Name())
+		}
+	}
+	return names
+}
+
+// HasSynced returns true if all collectors have synced.
+func (r *Registry) HasSynced() bool {
+	for _, c := range r.Collectors() {
+		if !c.HasSynced() {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,150 @@
+/*
+Copyright 2018 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package collectors
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+	"golang.org/x/net/context"
+	"k8s.io/kube-state-metrics/pkg/metrics"
+)
+
+// keyStore is a fake store holding objects with the given keys.
+type keyStore []string
+
+func (s keyStore) GetAll() []*metrics.Metric {
+	return nil
+}
+
+func (s keyStore) ListKeys() []string {
+	return s
+}
+
+func TestRegistry(t *testing.T) {
+	static := &Collector{name: "static"}
+	a1 := &Collector{name: "a1"}
+	a2 := &Collector{name: "a2"}
+	b := &Collector{name: "b"}
+
+	stopped := map[string]int{}
+	stop := func(name string) func() {
+		return func() { stopped[name]++ }
+	}
+
+	r := NewRegistry([]*Collector{static})
+	r.Set("b", []*Collector{b}, stop("b"))
+	r.Set("a", []*Collector{a1}, stop("a1"))
+	if got, want := r.Collectors(), []*Collector{static, a1, b}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("expected %v, got %v", want, got)
+	}
+
+	r.Set("a", []*Collector{a2}, stop("a2"))
+	if got, want := r.Collectors(), []*Collector{static, a2, b}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("expected %v after replacing group a, got %v", want, got)
+	}
+	if stopped["a1"] != 1 {
+		t.Fatalf("expected replaced group to be stopped once, got %d", stopped["a1"])
+	}
+
+	r.Delete("b")
+	r.Delete("unknown")
+	if got, want := r.Collectors(), []*Collector{static, a2}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("expected %v after deleting group b, got %v", want, got)
+	}
+	if want := map[string]int{"a1": 1, "b": 1}; !reflect.DeepEqual(stopped, want) {
+		t.Fatalf("expected stopped groups %v, got %v", want, stopped)
+	}
+}
+
+func TestRegistryWaitForSync(t *testing.T) {
+	static := &Collector{name: "static"}
+	never := &Collector{name: "never", hasSynced: func() bool { return false }}
+
+	r := NewRegistry([]*Collector{static})
+	if err := r.WaitForSync(context.TODO(), time.Second); err != nil {
+		t.Fatalf("expected synced registry not to time out, got %v", err)
+	}
+
+	r.Set("a", []*Collector{never}, nil)
+	err := r.WaitForSync(context.TODO(), 50*time.Millisecond)
+	if err == nil {
+		t.Fatal("expected an error if a collector never syncs")
+	}
+	if !strings.Contains(err.Error(), "never") || strings.Contains(err.Error(), "static") {
+		t.Errorf("expected error to only name the unsynced collector, got %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.TODO())
+	cancel()
+	if err := r.WaitForSync(ctx, time.Minute); err != context.Canceled {
+		t.Errorf("expected waiting to stop once the context is done, got %v", err)
+	}
+}
+
+func TestRegistryUpdateWatchCacheMetrics(t *testing.T) {
+	pods := &Collector{name: "pods", store: keyStore{"a", "b", "c"}}
+	otherClusterPods := &Collector{name: "pods", store: keyStore{"d"}}
+	nodes := &Collector{name: "nodes", store: keyStore{}}
+	generated := &Collector{name: "namespaceobjectcounts", store: generatedStore(func() []*metrics.Metric { return nil })}
+
+	r := NewRegistry([]*Collector{pods, otherClusterPods, nodes, generated})
+	r.UpdateWatchCacheMetrics()
+
+	want := map[string]float64{"pods": 4, "nodes": 0}
+	for resource, n := range want {
+		m := &dto.Metric{}
+		if err := WatchCacheObjectsMetric.WithLabelValues(resource).(prometheus.Metric).Write(m); err != nil {
+			t.Fatal(err)
+		}
+		if got := m.GetGauge().GetValue(); got != n {
+			t.Errorf("expected %v objects of %s, got %v", n, resource, got)
+		}
+	}
+
+	ch := make(chan prometheus.Metric, 10)
+	WatchCacheObjectsMetric.Collect(ch)
+	close(ch)
+	if len(ch) != len(want) {
+		t.Errorf("expected %d resources, got %d", len(want), len(ch))
+	}
+}
+
+func TestRegistryHasSynced(t *testing.T) {
+	synced := false
+	static := &Collector{name: "static"}
+	pending := &Collector{name: "pending", hasSynced: func() bool { return synced }}
+
+	r := NewRegistry([]*Collector{static})
+	if !r.HasSynced() {
+		t.Fatal("expected collector without reflectors to be synced")
+	}
+
+	r.Set("a", []*Collector{pending}, nil)
+	if r.HasSynced() {
+		t.Fatal("expected registry not to be synced before all collectors are")
+	}
+
+	synced = true
+	if !r.HasSynced() {
+		t.Fatal("expected registry to be synced once all collectors are")
+	}
+}
@@ -27,6 +27,7 @@ import (
 var (
 	rc1Replicas int32 = 5
 	rc2Replicas int32 = 0
+	rc3Replicas int32 = 3
 )
 
 func TestReplicationControllerCollector(t *testing.T) {
@@ -107,6 +108,36 @@ func TestReplicationControllerCollector(t *testing.T) {
 				kube_replicationcontroller_status_ready_replicas{namespace="ns2",replicationcontroller="rc2"} 0
 				kube_replicationcontroller_status_available_replicas{namespace="ns2",replicationcontroller="rc2"} 0
 				kube_replicationcontroller_spec_replicas{namespace="ns2",replicationcontroller="rc2"} 0
+`,
+		},
+		{
+			// Scaled up from one to three replicas, not yet observed by the
+			// ReplicationController controller.
+			Obj: &v1.ReplicationController{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:       "rc3",
+					Namespace:  "ns3",
+					Generation: 2,
+				},
+				Status: v1.ReplicationControllerStatus{
+					Replicas:             1,
+					FullyLabeledReplicas: 1,
+					ReadyReplicas:        1,
+					AvailableReplicas:    1,
+					ObservedGeneration:   1,
+				},
+				Spec: v1.ReplicationControllerSpec{
+					Replicas: &rc3Replicas,
+				},
+			},
+			Want: `
+				kube_replicationcontroller_metadata_generation{namespace="ns3",replicationcontroller="rc3"} 2
+				kube_replicationcontroller_status_replicas{namespace="ns3",replicationcontroller="rc3"} 1
+				kube_replicationcontroller_status_observed_generation{namespace="ns3",replicationcontroller="rc3"} 1
+				kube_replicationcontroller_status_fully_labeled_replicas{namespace="ns3",replicationcontroller="rc3"} 1
+				kube_replicationcontroller_status_ready_replicas{namespace="ns3",replicationcontroller="rc3"} 1
+				kube_replicationcontroller_status_available_replicas{namespace="ns3",replicationcontroller="rc3"} 1
+				kube_replicationcontroller_spec_replicas{namespace="ns3",replicationcontroller="rc3"} 3
 `,
 		},
 	}
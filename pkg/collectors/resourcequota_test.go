@@ -130,6 +130,34 @@ func TestResourceQuotaCollector(t *testing.T) {
 			kube_resourcequota{namespace="testNS",resource="storage",resourcequota="quotaTest",type="used"} 9e+09
 			`,
 		},
+		{
+			Obj: &v1.ResourceQuota{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "computeQuota",
+					Namespace: "testNS",
+				},
+				Status: v1.ResourceQuotaStatus{
+					Hard: v1.ResourceList{
+						v1.ResourceRequestsCPU:  resource.MustParse("2"),
+						v1.ResourceLimitsMemory: resource.MustParse("4Gi"),
+						v1.ResourcePods:         resource.MustParse("10"),
+					},
+					Used: v1.ResourceList{
+						v1.ResourceRequestsCPU:  resource.MustParse("500m"),
+						v1.ResourceLimitsMemory: resource.MustParse("1Gi"),
+						v1.ResourcePods:         resource.MustParse("3"),
+					},
+				},
+			},
+			Want: `
+			kube_resourcequota{namespace="testNS",resource="limits.memory",resourcequota="computeQuota",type="hard"} 4.294967296e+09
+			kube_resourcequota{namespace="testNS",resource="limits.memory",resourcequota="computeQuota",type="used"} 1.073741824e+09
+			kube_resourcequota{namespace="testNS",resource="pods",resourcequota="computeQuota",type="hard"} 10
+			kube_resourcequota{namespace="testNS",resource="pods",resourcequota="computeQuota",type="used"} 3
+			kube_resourcequota{namespace="testNS",resource="requests.cpu",resourcequota="computeQuota",type="hard"} 2
+			kube_resourcequota{namespace="testNS",resource="requests.cpu",resourcequota="computeQuota",type="used"} 0.5
+			`,
+		},
 	}
 	for i, c := range cases {
 		c.Func = generateResourceQuotaMetrics
@@ -0,0 +1,88 @@
+/*
+Copyright 2018 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package collectors
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/kube-state-metrics/pkg/customresource"
+	"k8s.io/kube-state-metrics/pkg/log"
+	"k8s.io/kube-state-metrics/pkg/metrics"
+)
+
+var (
+	descRuntimeClassLabelsDefaultLabels = []string{"runtimeclass"}
+
+	descRuntimeClassInfo = newMetricFamilyDef(
+		"kube_runtimeclass_info",
+		"Information about runtimeclass.",
+		append(descRuntimeClassLabelsDefaultLabels, "handler"),
+		nil,
+	)
+
+	descRuntimeClassCreated = newMetricFamilyDef(
+		"kube_runtimeclass_created",
+		"Unix creation timestamp",
+		descRuntimeClassLabelsDefaultLabels,
+		nil,
+	)
+
+	// runtimeClassResource is the node.k8s.io/v1beta1 RuntimeClass
+	// resource.
+	runtimeClassResource = customresource.Resource{
+		Group:    "node.k8s.io",
+		Version:  "v1beta1",
+		Resource: "runtimeclasses",
+	}
+)
+
+// runtimeClass holds the fields of a node.k8s.io/v1beta1 RuntimeClass exposed
+// as metrics.
+type runtimeClass struct {
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+	Handler           string `json:"handler"`
+}
+
+func generateRuntimeClassMetrics(obj interface{}) []*metrics.Metric {
+	ms := []*metrics.Metric{}
+
+	r := runtimeClass{}
+	if err := runtime.DefaultUnstructuredConverter.FromUnstructured(obj.(*unstructured.Unstructured).Object, &r); err != nil {
+		log.Errorf("Failed to convert runtime class: %v", err)
+		return ms
+	}
+
+	addGauge := func(desc *metricFamilyDef, v float64, lv ...string) {
+		lv = append([]string{r.Name}, lv...)
+
+		m, err := metrics.NewMetric(desc.Name, desc.LabelKeys, lv, v)
+		if err != nil {
+			panic(err)
+		}
+
+		ms = append(ms, m)
+	}
+
+	addGauge(descRuntimeClassInfo, 1, r.Handler)
+
+	if !r.CreationTimestamp.IsZero() {
+		addGauge(descRuntimeClassCreated, float64(r.CreationTimestamp.Unix()))
+	}
+
+	return ms
+}
@@ -0,0 +1,76 @@
+/*
+Copyright 2018 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package collectors
+
+import (
+	"testing"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func TestRuntimeClassCollector(t *testing.T) {
+	// Fixed metadata on type and help text. We prepend this to every expected
+	// output so we only have to modify a single place when doing adjustments.
+	const metadata = `
+		# HELP kube_runtimeclass_info Information about runtimeclass.
+		# TYPE kube_runtimeclass_info gauge
+		# HELP kube_runtimeclass_created Unix creation timestamp
+		# TYPE kube_runtimeclass_created gauge
+	`
+	cases := []generateMetricsTestCase{
+		{
+			Obj: &unstructured.Unstructured{Object: map[string]interface{}{
+				"apiVersion": "node.k8s.io/v1beta1",
+				"kind":       "RuntimeClass",
+				"metadata": map[string]interface{}{
+					"name":              "gvisor",
+					"creationTimestamp": "2017-07-14T02:40:00Z",
+				},
+				"handler": "runsc",
+			}},
+			Want: `
+				kube_runtimeclass_created{runtimeclass="gvisor"} 1.5e+09
+				kube_runtimeclass_info{handler="runsc",runtimeclass="gvisor"} 1
+`,
+		},
+		{
+			Obj: &unstructured.Unstructured{Object: map[string]interface{}{
+				"apiVersion": "node.k8s.io/v1beta1",
+				"kind":       "RuntimeClass",
+				"metadata": map[string]interface{}{
+					"name": "kata",
+				},
+				"handler": "kata-qemu",
+				"overhead": map[string]interface{}{
+					"podFixed": map[string]interface{}{
+						"cpu":    "250m",
+						"memory": "160Mi",
+					},
+				},
+			}},
+			Want: `
+				kube_runtimeclass_info{handler="kata-qemu",runtimeclass="kata"} 1
+`,
+		},
+	}
+	for i, c := range cases {
+		c.Func = generateRuntimeClassMetrics
+		if err := c.run(); err != nil {
+			t.Errorf("unexpected collecting result in %vth run:\n%s", i, err)
+		}
+	}
+}
@@ -87,7 +87,7 @@ func secretLabelsDesc(labelKeys []string) *metricFamilyDef {
 	)
 }
 
-func generateSecretMetrics(obj interface{}) []*metrics.Metric {
+func generateSecretMetrics(allowedLabels []string, obj interface{}) []*metrics.Metric {
 	ms := []*metrics.Metric{}
 
 	// TODO: Refactor
@@ -112,8 +112,9 @@ func generateSecretMetrics(obj interface{}) []*metrics.Metric {
 	if !s.CreationTimestamp.IsZero() {
 		addGauge(descSecretCreated, float64(s.CreationTimestamp.Unix()))
 	}
-	labelKeys, labelValues := kubeLabelsToPrometheusLabels(s.Labels)
-	addGauge(secretLabelsDesc(labelKeys), 1, labelValues...)
+	if labelKeys, labelValues, ok := kubeLabelsToPrometheusLabels(s.Labels, allowedLabels); ok {
+		addGauge(secretLabelsDesc(labelKeys), 1, labelValues...)
+	}
 
 	addGauge(descSecretMetadataResourceVersion, 1, string(s.ObjectMeta.ResourceVersion))
 
@@ -21,6 +21,7 @@ import (
 
 	"k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/kube-state-metrics/pkg/metrics"
 )
 
 func TestSecretCollector(t *testing.T) {
@@ -99,9 +100,36 @@ func TestSecretCollector(t *testing.T) {
 `,
 			MetricNames: []string{"kube_secret_info", "kube_secret_metadata_resource_version", "kube_secret_created", "kube_secret_labels", "kube_secret_type"},
 		},
+		{
+			// The data of a secret must never be exposed, hence all metrics
+			// are compared here.
+			Obj: &v1.Secret{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:            "secret4",
+					Namespace:       "ns4",
+					ResourceVersion: "654321",
+				},
+				Type: v1.SecretTypeBasicAuth,
+				Data: map[string][]byte{
+					"username": []byte("admin"),
+					"password": []byte("s3cr3t"),
+				},
+				StringData: map[string]string{
+					"token": "t0k3n",
+				},
+			},
+			Want: `
+				kube_secret_info{namespace="ns4",secret="secret4"} 1
+				kube_secret_type{namespace="ns4",secret="secret4",type="kubernetes.io/basic-auth"} 1
+				kube_secret_metadata_resource_version{namespace="ns4",resource_version="654321",secret="secret4"} 1
+				kube_secret_labels{namespace="ns4",secret="secret4"} 1
+`,
+		},
 	}
 	for i, c := range cases {
-		c.Func = generateSecretMetrics
+		c.Func = func(obj interface{}) []*metrics.Metric {
+			return generateSecretMetrics(nil, obj)
+		}
 		if err := c.run(); err != nil {
 			t.Errorf("unexpected collecting result in %vth run:\n%s", i, err)
 		}
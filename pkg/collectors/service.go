@@ -35,7 +35,7 @@ var (
 	descServiceInfo = newMetricFamilyDef(
 		"kube_service_info",
 		"Information about service.",
-		append(descServiceLabelsDefaultLabels, "cluster_ip"),
+		append(descServiceLabelsDefaultLabels, "cluster_ip", "external_name", "load_balancer_ip", "external_traffic_policy"),
 		nil,
 	)
 
@@ -53,6 +53,13 @@ var (
 		nil,
 	)
 
+	descServiceStatusLoadBalancerIngress = newMetricFamilyDef(
+		"kube_service_status_load_balancer_ingress",
+		"Service load balancer ingress status",
+		append(descServiceLabelsDefaultLabels, "ip", "hostname"),
+		nil,
+	)
+
 	descServiceLabels = newMetricFamilyDef(
 		descServiceLabelsName,
 		descServiceLabelsHelp,
@@ -81,7 +88,7 @@ func serviceLabelsDesc(labelKeys []string) *metricFamilyDef {
 	)
 }
 
-func generateServiceMetrics(obj interface{}) []*metrics.Metric {
+func generateServiceMetrics(allowedLabels []string, obj interface{}) []*metrics.Metric {
 	ms := []*metrics.Metric{}
 
 	// TODO: Refactor
@@ -103,12 +110,16 @@ func generateServiceMetrics(obj interface{}) []*metrics.Metric {
 	}
 	addGauge(descServiceSpecType, 1, string(s.Spec.Type))
 
-	addGauge(descServiceInfo, 1, s.Spec.ClusterIP)
+	addGauge(descServiceInfo, 1, s.Spec.ClusterIP, s.Spec.ExternalName, s.Spec.LoadBalancerIP, string(s.Spec.ExternalTrafficPolicy))
 	if !s.CreationTimestamp.IsZero() {
 		addGauge(descServiceCreated, float64(s.CreationTimestamp.Unix()))
 	}
-	labelKeys, labelValues := kubeLabelsToPrometheusLabels(s.Labels)
-	addGauge(serviceLabelsDesc(labelKeys), 1, labelValues...)
+	if labelKeys, labelValues, ok := kubeLabelsToPrometheusLabels(s.Labels, allowedLabels); ok {
+		addGauge(serviceLabelsDesc(labelKeys), 1, labelValues...)
+	}
+	for _, ingress := range s.Status.LoadBalancer.Ingress {
+		addGauge(descServiceStatusLoadBalancerIngress, 1, ingress.IP, ingress.Hostname)
+	}
 
 	return ms
 }
@@ -22,6 +22,7 @@ import (
 
 	"k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/kube-state-metrics/pkg/metrics"
 )
 
 func TestServiceCollector(t *testing.T) {
@@ -36,6 +37,8 @@ func TestServiceCollector(t *testing.T) {
 		# TYPE kube_service_labels gauge
 		# HELP kube_service_spec_type Type about service.
 		# TYPE kube_service_spec_type gauge
+		# HELP kube_service_status_load_balancer_ingress Service load balancer ingress status
+		# TYPE kube_service_status_load_balancer_ingress gauge
 	`
 	cases := []generateMetricsTestCase{
 		{
@@ -55,7 +58,7 @@ func TestServiceCollector(t *testing.T) {
 			},
 			Want: `
 				kube_service_created{namespace="default",service="test-service1"} 1.5e+09
-				kube_service_info{cluster_ip="1.2.3.4",namespace="default",service="test-service1"} 1
+				kube_service_info{cluster_ip="1.2.3.4",external_name="",external_traffic_policy="",load_balancer_ip="",namespace="default",service="test-service1"} 1
 				kube_service_labels{label_app="example1",namespace="default",service="test-service1"} 1
 				kube_service_spec_type{namespace="default",service="test-service1",type="ClusterIP"} 1
 `,
@@ -78,13 +81,14 @@ func TestServiceCollector(t *testing.T) {
 					},
 				},
 				Spec: v1.ServiceSpec{
-					ClusterIP: "1.2.3.5",
-					Type:      v1.ServiceTypeNodePort,
+					ClusterIP:             "1.2.3.5",
+					Type:                  v1.ServiceTypeNodePort,
+					ExternalTrafficPolicy: v1.ServiceExternalTrafficPolicyTypeLocal,
 				},
 			},
 			Want: `
 				kube_service_created{namespace="default",service="test-service2"} 1.5e+09
-				kube_service_info{cluster_ip="1.2.3.5",namespace="default",service="test-service2"} 1
+				kube_service_info{cluster_ip="1.2.3.5",external_name="",external_traffic_policy="Local",load_balancer_ip="",namespace="default",service="test-service2"} 1
 				kube_service_labels{label_app="example2",namespace="default",service="test-service2"} 1
 				kube_service_spec_type{namespace="default",service="test-service2",type="NodePort"} 1
 `,
@@ -100,15 +104,27 @@ func TestServiceCollector(t *testing.T) {
 					},
 				},
 				Spec: v1.ServiceSpec{
-					ClusterIP: "1.2.3.6",
-					Type:      v1.ServiceTypeLoadBalancer,
+					ClusterIP:             "1.2.3.6",
+					Type:                  v1.ServiceTypeLoadBalancer,
+					LoadBalancerIP:        "1.2.3.7",
+					ExternalTrafficPolicy: v1.ServiceExternalTrafficPolicyTypeCluster,
+				},
+				Status: v1.ServiceStatus{
+					LoadBalancer: v1.LoadBalancerStatus{
+						Ingress: []v1.LoadBalancerIngress{
+							{IP: "1.2.3.7"},
+							{Hostname: "lb.example.com"},
+						},
+					},
 				},
 			},
 			Want: `
 				kube_service_created{namespace="default",service="test-service3"} 1.5e+09
-				kube_service_info{cluster_ip="1.2.3.6",namespace="default",service="test-service3"} 1		
+				kube_service_info{cluster_ip="1.2.3.6",external_name="",external_traffic_policy="Cluster",load_balancer_ip="1.2.3.7",namespace="default",service="test-service3"} 1
 				kube_service_labels{label_app="example3",namespace="default",service="test-service3"} 1
 				kube_service_spec_type{namespace="default",service="test-service3",type="LoadBalancer"} 1
+				kube_service_status_load_balancer_ingress{hostname="",ip="1.2.3.7",namespace="default",service="test-service3"} 1
+				kube_service_status_load_balancer_ingress{hostname="lb.example.com",ip="",namespace="default",service="test-service3"} 1
 `,
 		},
 		{
@@ -122,19 +138,22 @@ func TestServiceCollector(t *testing.T) {
 					},
 				},
 				Spec: v1.ServiceSpec{
-					Type: v1.ServiceTypeExternalName,
+					Type:         v1.ServiceTypeExternalName,
+					ExternalName: "db.example.com",
 				},
 			},
 			Want: `	
 				kube_service_created{namespace="default",service="test-service4"} 1.5e+09		
-				kube_service_info{cluster_ip="",namespace="default",service="test-service4"} 1
+				kube_service_info{cluster_ip="",external_name="db.example.com",external_traffic_policy="",load_balancer_ip="",namespace="default",service="test-service4"} 1
 				kube_service_labels{label_app="example4",namespace="default",service="test-service4"} 1
 				kube_service_spec_type{namespace="default",service="test-service4",type="ExternalName"} 1
 			`,
 		},
 	}
 	for i, c := range cases {
-		c.Func = generateServiceMetrics
+		c.Func = func(obj interface{}) []*metrics.Metric {
+			return generateServiceMetrics(nil, obj)
+		}
 		if err := c.run(); err != nil {
 			t.Errorf("unexpected collecting result in %vth run:\n%s", i, err)
 		}
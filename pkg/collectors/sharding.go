@@ -0,0 +1,48 @@
+/*
+Copyright 2018 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package collectors
+
+import (
+	"hash/fnv"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/kube-state-metrics/pkg/metrics"
+)
+
+// shardForUID returns the shard the object with the given UID belongs to.
+func shardForUID(uid types.UID, totalShards int) int {
+	h := fnv.New64a()
+	h.Write([]byte(uid))
+	return int(h.Sum64() % uint64(totalShards))
+}
+
+// shardedGenerateFunc wraps the given metric generation function to only
+// generate metrics for objects belonging to the given shard.
+func shardedGenerateFunc(f func(interface{}) []*metrics.Metric, shard, totalShards int) func(interface{}) []*metrics.Metric {
+	if totalShards <= 1 {
+		return f
+	}
+
+	return func(obj interface{}) []*metrics.Metric {
+		o, err := meta.Accessor(obj)
+		if err != nil || shardForUID(o.GetUID(), totalShards) != shard {
+			return nil
+		}
+		return f(obj)
+	}
+}
@@ -0,0 +1,78 @@
+/*
+Copyright 2018 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package collectors
+
+import (
+	"testing"
+
+	"k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/kube-state-metrics/pkg/metrics"
+)
+
+func TestShardForUID(t *testing.T) {
+	tests := []struct {
+		UID         types.UID
+		TotalShards int
+		Want        int
+	}{
+		{UID: "f6d1ab5e-9d1b-11e8-98d0-529269fb1459", TotalShards: 1, Want: 0},
+		{UID: "f6d1ab5e-9d1b-11e8-98d0-529269fb1459", TotalShards: 2, Want: 1},
+		{UID: "f6d1ab5e-9d1b-11e8-98d0-529269fb1459", TotalShards: 3, Want: 0},
+		{UID: "1c5d9f3b-9d1c-11e8-98d0-529269fb1459", TotalShards: 3, Want: 2},
+		{UID: "2d6e0a4c-9d1c-11e8-98d0-529269fb1459", TotalShards: 3, Want: 1},
+	}
+
+	for _, test := range tests {
+		// Repeat to make sure the mapping is stable.
+		for i := 0; i < 3; i++ {
+			if got := shardForUID(test.UID, test.TotalShards); got != test.Want {
+				t.Errorf("expected UID %s to map to shard %d of %d, got %d", test.UID, test.Want, test.TotalShards, got)
+			}
+		}
+	}
+}
+
+func TestShardedGenerateFunc(t *testing.T) {
+	generate := func(obj interface{}) []*metrics.Metric {
+		m := metrics.Metric("kube_configmap_info 1\n")
+		return []*metrics.Metric{&m}
+	}
+
+	cm := &v1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "configmap1",
+			UID:  "f6d1ab5e-9d1b-11e8-98d0-529269fb1459",
+		},
+	}
+
+	totalShards := 3
+	count := 0
+	for shard := 0; shard < totalShards; shard++ {
+		ms := shardedGenerateFunc(generate, shard, totalShards)(cm)
+		if len(ms) > 0 {
+			count++
+			if shard != shardForUID(cm.UID, totalShards) {
+				t.Errorf("expected metrics only for shard %d, got metrics for shard %d", shardForUID(cm.UID, totalShards), shard)
+			}
+		}
+	}
+	if count != 1 {
+		t.Errorf("expected object to be exposed by exactly one shard, got %d", count)
+	}
+}
@@ -98,6 +98,12 @@ var (
 		append(descStatefulSetLabelsDefaultLabels, "revision"),
 		nil,
 	)
+	descStatefulSetSpecUpdateStrategyRollingUpdatePartition = newMetricFamilyDef(
+		"kube_statefulset_spec_update_strategy_rollingupdate_partition",
+		"Ordinal at which the StatefulSet is partitioned for rolling updates. Only Pods with an ordinal greater or equal are updated.",
+		descStatefulSetLabelsDefaultLabels,
+		nil,
+	)
 )
 
 func createStatefulSetListWatch(kubeClient clientset.Interface, ns string) cache.ListWatch {
@@ -120,7 +126,7 @@ func statefulSetLabelsDesc(labelKeys []string) *metricFamilyDef {
 	)
 }
 
-func generateStatefulSetMetrics(obj interface{}) []*metrics.Metric {
+func generateStatefulSetMetrics(allowedLabels []string, obj interface{}) []*metrics.Metric {
 	ms := []*metrics.Metric{}
 
 	// TODO: Refactor
@@ -152,10 +158,15 @@ func generateStatefulSetMetrics(obj interface{}) []*metrics.Metric {
 	}
 	addGauge(descStatefulSetMetadataGeneration, float64(s.ObjectMeta.Generation))
 
-	labelKeys, labelValues := kubeLabelsToPrometheusLabels(s.Labels)
-	addGauge(statefulSetLabelsDesc(labelKeys), 1, labelValues...)
+	if labelKeys, labelValues, ok := kubeLabelsToPrometheusLabels(s.Labels, allowedLabels); ok {
+		addGauge(statefulSetLabelsDesc(labelKeys), 1, labelValues...)
+	}
 
 	addGauge(descStatefulSetCurrentRevision, 1, s.Status.CurrentRevision)
 	addGauge(descStatefulSetUpdateRevision, 1, s.Status.UpdateRevision)
+
+	if ru := s.Spec.UpdateStrategy.RollingUpdate; ru != nil && ru.Partition != nil {
+		addGauge(descStatefulSetSpecUpdateStrategyRollingUpdatePartition, float64(*ru.Partition))
+	}
 	return ms
 }
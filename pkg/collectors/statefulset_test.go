@@ -22,6 +22,7 @@ import (
 
 	"k8s.io/api/apps/v1beta1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/kube-state-metrics/pkg/metrics"
 )
 
 var (
@@ -31,6 +32,8 @@ var (
 
 	statefulSet1ObservedGeneration int64 = 1
 	statefulSet2ObservedGeneration int64 = 2
+
+	statefulSet4Partition int32 = 7
 )
 
 func TestStatefuleSetCollector(t *testing.T) {
@@ -59,6 +62,8 @@ func TestStatefuleSetCollector(t *testing.T) {
  		# TYPE kube_statefulset_metadata_generation gauge
 		# HELP kube_statefulset_labels Kubernetes labels converted to Prometheus labels.
 		# TYPE kube_statefulset_labels gauge
+		# HELP kube_statefulset_spec_update_strategy_rollingupdate_partition Ordinal at which the StatefulSet is partitioned for rolling updates. Only Pods with an ordinal greater or equal are updated.
+		# TYPE kube_statefulset_spec_update_strategy_rollingupdate_partition gauge
  	`
 	cases := []generateMetricsTestCase{
 		{
@@ -203,9 +208,52 @@ func TestStatefuleSetCollector(t *testing.T) {
 				"kube_statefulset_status_current_revision",
 			},
 		},
+		{
+			// A canary rollout updating only the pods with ordinals 7 and 8,
+			// which run the update revision.
+			Obj: &v1beta1.StatefulSet{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "statefulset4",
+					Namespace: "ns4",
+				},
+				Spec: v1beta1.StatefulSetSpec{
+					Replicas:    &statefulSet3Replicas,
+					ServiceName: "statefulset4service",
+					UpdateStrategy: v1beta1.StatefulSetUpdateStrategy{
+						Type: v1beta1.RollingUpdateStatefulSetStrategyType,
+						RollingUpdate: &v1beta1.RollingUpdateStatefulSetStrategy{
+							Partition: &statefulSet4Partition,
+						},
+					},
+				},
+				Status: v1beta1.StatefulSetStatus{
+					Replicas:        9,
+					CurrentReplicas: 7,
+					UpdatedReplicas: 2,
+					UpdateRevision:  "ur4",
+					CurrentRevision: "cr4",
+				},
+			},
+			Want: `
+				kube_statefulset_spec_update_strategy_rollingupdate_partition{namespace="ns4",statefulset="statefulset4"} 7
+				kube_statefulset_status_current_revision{namespace="ns4",revision="cr4",statefulset="statefulset4"} 1
+				kube_statefulset_status_replicas_current{namespace="ns4",statefulset="statefulset4"} 7
+				kube_statefulset_status_replicas_updated{namespace="ns4",statefulset="statefulset4"} 2
+				kube_statefulset_status_update_revision{namespace="ns4",revision="ur4",statefulset="statefulset4"} 1
+			`,
+			MetricNames: []string{
+				"kube_statefulset_spec_update_strategy_rollingupdate_partition",
+				"kube_statefulset_status_current_revision",
+				"kube_statefulset_status_replicas_current",
+				"kube_statefulset_status_replicas_updated",
+				"kube_statefulset_status_update_revision",
+			},
+		},
 	}
 	for i, c := range cases {
-		c.Func = generateStatefulSetMetrics
+		c.Func = func(obj interface{}) []*metrics.Metric {
+			return generateStatefulSetMetrics(nil, obj)
+		}
 		if err := c.run(); err != nil {
 			t.Errorf("unexpected collecting result in %vth run:\n%s", i, err)
 		}
@@ -0,0 +1,97 @@
+/*
+Copyright 2018 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package collectors
+
+import (
+	"testing"
+	"time"
+
+	admissionregistration "k8s.io/api/admissionregistration/v1beta1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestValidatingWebhookConfigurationCollector(t *testing.T) {
+	// Fixed metadata on type and help text. We prepend this to every expected
+	// output so we only have to modify a single place when doing adjustments.
+	const metadata = `
+		# HELP kube_validatingwebhookconfiguration_info Information about the ValidatingWebhookConfiguration.
+		# TYPE kube_validatingwebhookconfiguration_info gauge
+		# HELP kube_validatingwebhookconfiguration_created Unix creation timestamp.
+		# TYPE kube_validatingwebhookconfiguration_created gauge
+		# HELP kube_validatingwebhookconfiguration_webhook_clientconfig_service Service used by the apiserver to connect to a validating webhook.
+		# TYPE kube_validatingwebhookconfiguration_webhook_clientconfig_service gauge
+	`
+	url := "https://webhook.example.com/validating"
+	cases := []generateMetricsTestCase{
+		{
+			Obj: &admissionregistration.ValidatingWebhookConfiguration{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: "validatingwebhookconfiguration1",
+				},
+			},
+			Want: `
+				kube_validatingwebhookconfiguration_info{validatingwebhookconfiguration="validatingwebhookconfiguration1"} 1
+`,
+		},
+		{
+			Obj: &admissionregistration.ValidatingWebhookConfiguration{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:              "validatingwebhookconfiguration2",
+					CreationTimestamp: metav1.Time{Time: time.Unix(1500000000, 0)},
+				},
+				Webhooks: []admissionregistration.Webhook{
+					{
+						Name: "webhook1.example.com",
+						ClientConfig: admissionregistration.WebhookClientConfig{
+							Service: &admissionregistration.ServiceReference{
+								Namespace: "ns1",
+								Name:      "service1",
+							},
+						},
+					},
+					{
+						Name: "webhook2.example.com",
+						ClientConfig: admissionregistration.WebhookClientConfig{
+							Service: &admissionregistration.ServiceReference{
+								Namespace: "ns2",
+								Name:      "service2",
+							},
+						},
+					},
+					{
+						Name: "webhook3.example.com",
+						ClientConfig: admissionregistration.WebhookClientConfig{
+							URL: &url,
+						},
+					},
+				},
+			},
+			Want: `
+				kube_validatingwebhookconfiguration_info{validatingwebhookconfiguration="validatingwebhookconfiguration2"} 1
+				kube_validatingwebhookconfiguration_created{validatingwebhookconfiguration="validatingwebhookconfiguration2"} 1.5e+09
+				kube_validatingwebhookconfiguration_webhook_clientconfig_service{namespace="ns1",service="service1",validatingwebhookconfiguration="validatingwebhookconfiguration2",webhook="webhook1.example.com"} 1
+				kube_validatingwebhookconfiguration_webhook_clientconfig_service{namespace="ns2",service="service2",validatingwebhookconfiguration="validatingwebhookconfiguration2",webhook="webhook2.example.com"} 1
+`,
+		},
+	}
+	for i, c := range cases {
+		c.Func = generateValidatingWebhookConfigurationMetrics
+		if err := c.run(); err != nil {
+			t.Errorf("unexpected collecting result in %vth run:\n%s", i, err)
+		}
+	}
+}
@@ -0,0 +1,144 @@
+/*
+Copyright 2018 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package collectors
+
+import (
+	"k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/kube-state-metrics/pkg/constant"
+	"k8s.io/kube-state-metrics/pkg/customresource"
+	"k8s.io/kube-state-metrics/pkg/log"
+	"k8s.io/kube-state-metrics/pkg/metrics"
+)
+
+var (
+	descVerticalPodAutoscalerLabelsDefaultLabels = []string{"namespace", "verticalpodautoscaler", "target_api_version", "target_kind", "target_name"}
+
+	descVerticalPodAutoscalerSpecUpdatePolicyUpdateMode = newMetricFamilyDef(
+		"kube_verticalpodautoscaler_spec_updatepolicy_updatemode",
+		"Update mode of the VerticalPodAutoscaler.",
+		append(descVerticalPodAutoscalerLabelsDefaultLabels, "update_mode"),
+		nil,
+	)
+
+	descVerticalPodAutoscalerStatusRecommendationContainerRecommendationsTarget = newMetricFamilyDef(
+		"kube_verticalpodautoscaler_status_recommendation_containerrecommendations_target",
+		"Target resources the VerticalPodAutoscaler recommends for the container.",
+		append(descVerticalPodAutoscalerLabelsDefaultLabels, "container", "resource", "unit"),
+		nil,
+	)
+
+	// verticalPodAutoscalerUpdateModes are the update modes of a
+	// VerticalPodAutoscaler, see k8s.io/autoscaler/vertical-pod-autoscaler.
+	verticalPodAutoscalerUpdateModes = []string{"Off", "Initial", "Recreate", "Auto"}
+
+	// verticalPodAutoscalerResource is the autoscaling.k8s.io/v1
+	// VerticalPodAutoscaler custom resource.
+	verticalPodAutoscalerResource = customresource.Resource{
+		Group:      "autoscaling.k8s.io",
+		Version:    "v1",
+		Resource:   "verticalpodautoscalers",
+		Namespaced: true,
+	}
+)
+
+// verticalPodAutoscaler holds the fields of an autoscaling.k8s.io/v1
+// VerticalPodAutoscaler exposed as metrics.
+type verticalPodAutoscaler struct {
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+	Spec              verticalPodAutoscalerSpec   `json:"spec,omitempty"`
+	Status            verticalPodAutoscalerStatus `json:"status,omitempty"`
+}
+
+type verticalPodAutoscalerSpec struct {
+	TargetRef    *verticalPodAutoscalerTargetRef    `json:"targetRef,omitempty"`
+	UpdatePolicy *verticalPodAutoscalerUpdatePolicy `json:"updatePolicy,omitempty"`
+}
+
+type verticalPodAutoscalerTargetRef struct {
+	APIVersion string `json:"apiVersion"`
+	Kind       string `json:"kind"`
+	Name       string `json:"name"`
+}
+
+type verticalPodAutoscalerUpdatePolicy struct {
+	UpdateMode *string `json:"updateMode,omitempty"`
+}
+
+type verticalPodAutoscalerStatus struct {
+	Recommendation *verticalPodAutoscalerRecommendation `json:"recommendation,omitempty"`
+}
+
+type verticalPodAutoscalerRecommendation struct {
+	ContainerRecommendations []verticalPodAutoscalerContainerRecommendation `json:"containerRecommendations,omitempty"`
+}
+
+type verticalPodAutoscalerContainerRecommendation struct {
+	ContainerName string          `json:"containerName"`
+	Target        v1.ResourceList `json:"target"`
+}
+
+func generateVerticalPodAutoscalerMetrics(obj interface{}) []*metrics.Metric {
+	ms := []*metrics.Metric{}
+
+	vpa := verticalPodAutoscaler{}
+	if err := runtime.DefaultUnstructuredConverter.FromUnstructured(obj.(*unstructured.Unstructured).Object, &vpa); err != nil {
+		log.Errorf("Failed to convert vertical pod autoscaler: %v", err)
+		return ms
+	}
+
+	var apiVersion, kind, name string
+	if ref := vpa.Spec.TargetRef; ref != nil {
+		apiVersion, kind, name = ref.APIVersion, ref.Kind, ref.Name
+	}
+
+	addGauge := func(desc *metricFamilyDef, v float64, lv ...string) {
+		lv = append([]string{vpa.Namespace, vpa.Name, apiVersion, kind, name}, lv...)
+
+		m, err := metrics.NewMetric(desc.Name, desc.LabelKeys, lv, v)
+		if err != nil {
+			panic(err)
+		}
+
+		ms = append(ms, m)
+	}
+
+	if p := vpa.Spec.UpdatePolicy; p != nil && p.UpdateMode != nil {
+		for _, mode := range verticalPodAutoscalerUpdateModes {
+			addGauge(descVerticalPodAutoscalerSpecUpdatePolicyUpdateMode, boolFloat64(*p.UpdateMode == mode), mode)
+		}
+	}
+
+	if r := vpa.Status.Recommendation; r != nil {
+		for _, c := range r.ContainerRecommendations {
+			for resourceName, val := range c.Target {
+				switch resourceName {
+				case v1.ResourceCPU:
+					addGauge(descVerticalPodAutoscalerStatusRecommendationContainerRecommendationsTarget, float64(val.MilliValue())/1000,
+						c.ContainerName, sanitizeLabelName(string(resourceName)), string(constant.UnitCore))
+				case v1.ResourceMemory:
+					addGauge(descVerticalPodAutoscalerStatusRecommendationContainerRecommendationsTarget, float64(val.Value()),
+						c.ContainerName, sanitizeLabelName(string(resourceName)), string(constant.UnitByte))
+				}
+			}
+		}
+	}
+
+	return ms
+}
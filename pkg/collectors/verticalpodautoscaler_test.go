@@ -0,0 +1,114 @@
+/*
+Copyright 2018 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package collectors
+
+import (
+	"testing"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func TestVerticalPodAutoscalerCollector(t *testing.T) {
+	// Fixed metadata on type and help text. We prepend this to every expected
+	// output so we only have to modify a single place when doing adjustments.
+	const metadata = `
+		# HELP kube_verticalpodautoscaler_spec_updatepolicy_updatemode Update mode of the VerticalPodAutoscaler.
+		# TYPE kube_verticalpodautoscaler_spec_updatepolicy_updatemode gauge
+		# HELP kube_verticalpodautoscaler_status_recommendation_containerrecommendations_target Target resources the VerticalPodAutoscaler recommends for the container.
+		# TYPE kube_verticalpodautoscaler_status_recommendation_containerrecommendations_target gauge
+	`
+	cases := []generateMetricsTestCase{
+		{
+			Obj: &unstructured.Unstructured{Object: map[string]interface{}{
+				"apiVersion": "autoscaling.k8s.io/v1",
+				"kind":       "VerticalPodAutoscaler",
+				"metadata": map[string]interface{}{
+					"name":      "vpa1",
+					"namespace": "ns1",
+				},
+				"spec": map[string]interface{}{
+					"targetRef": map[string]interface{}{
+						"apiVersion": "apps/v1",
+						"kind":       "Deployment",
+						"name":       "deployment1",
+					},
+					"updatePolicy": map[string]interface{}{
+						"updateMode": "Recreate",
+					},
+				},
+				"status": map[string]interface{}{
+					"recommendation": map[string]interface{}{
+						"containerRecommendations": []interface{}{
+							map[string]interface{}{
+								"containerName": "container1",
+								"target": map[string]interface{}{
+									"cpu":    "250m",
+									"memory": "256Mi",
+								},
+								"upperBound": map[string]interface{}{
+									"cpu":    "1",
+									"memory": "1Gi",
+								},
+							},
+							map[string]interface{}{
+								"containerName": "container2",
+								"target": map[string]interface{}{
+									"cpu": "2",
+								},
+							},
+						},
+					},
+				},
+			}},
+			Want: `
+				kube_verticalpodautoscaler_spec_updatepolicy_updatemode{namespace="ns1",target_api_version="apps/v1",target_kind="Deployment",target_name="deployment1",update_mode="Auto",verticalpodautoscaler="vpa1"} 0
+				kube_verticalpodautoscaler_spec_updatepolicy_updatemode{namespace="ns1",target_api_version="apps/v1",target_kind="Deployment",target_name="deployment1",update_mode="Initial",verticalpodautoscaler="vpa1"} 0
+				kube_verticalpodautoscaler_spec_updatepolicy_updatemode{namespace="ns1",target_api_version="apps/v1",target_kind="Deployment",target_name="deployment1",update_mode="Off",verticalpodautoscaler="vpa1"} 0
+				kube_verticalpodautoscaler_spec_updatepolicy_updatemode{namespace="ns1",target_api_version="apps/v1",target_kind="Deployment",target_name="deployment1",update_mode="Recreate",verticalpodautoscaler="vpa1"} 1
+				kube_verticalpodautoscaler_status_recommendation_containerrecommendations_target{container="container1",namespace="ns1",resource="cpu",target_api_version="apps/v1",target_kind="Deployment",target_name="deployment1",unit="core",verticalpodautoscaler="vpa1"} 0.25
+				kube_verticalpodautoscaler_status_recommendation_containerrecommendations_target{container="container1",namespace="ns1",resource="memory",target_api_version="apps/v1",target_kind="Deployment",target_name="deployment1",unit="byte",verticalpodautoscaler="vpa1"} 2.68435456e+08
+				kube_verticalpodautoscaler_status_recommendation_containerrecommendations_target{container="container2",namespace="ns1",resource="cpu",target_api_version="apps/v1",target_kind="Deployment",target_name="deployment1",unit="core",verticalpodautoscaler="vpa1"} 2
+`,
+		},
+		{
+			// A new VerticalPodAutoscaler without update policy, which has
+			// not recommended anything yet.
+			Obj: &unstructured.Unstructured{Object: map[string]interface{}{
+				"apiVersion": "autoscaling.k8s.io/v1",
+				"kind":       "VerticalPodAutoscaler",
+				"metadata": map[string]interface{}{
+					"name":      "vpa2",
+					"namespace": "ns2",
+				},
+				"spec": map[string]interface{}{
+					"targetRef": map[string]interface{}{
+						"apiVersion": "apps/v1",
+						"kind":       "StatefulSet",
+						"name":       "statefulset2",
+					},
+				},
+			}},
+			Want: ``,
+		},
+	}
+	for i, c := range cases {
+		c.Func = generateVerticalPodAutoscalerMetrics
+		if err := c.run(); err != nil {
+			t.Errorf("unexpected collecting result in %vth run:\n%s", i, err)
+		}
+	}
+}
@@ -0,0 +1,96 @@
+/*
+Copyright 2018 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package collectors
+
+import (
+	"k8s.io/kube-state-metrics/pkg/metrics"
+
+	storage "k8s.io/api/storage/v1beta1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/watch"
+	clientset "k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/cache"
+)
+
+var (
+	descVolumeAttachmentLabelsDefaultLabels = []string{"volumeattachment"}
+
+	descVolumeAttachmentInfo = newMetricFamilyDef(
+		"kube_volumeattachment_info",
+		"Information about volumeattachment.",
+		append(descVolumeAttachmentLabelsDefaultLabels, "attacher", "node", "pv"),
+		nil,
+	)
+
+	descVolumeAttachmentCreated = newMetricFamilyDef(
+		"kube_volumeattachment_created",
+		"Unix creation timestamp",
+		descVolumeAttachmentLabelsDefaultLabels,
+		nil,
+	)
+
+	descVolumeAttachmentStatusAttached = newMetricFamilyDef(
+		"kube_volumeattachment_status_attached",
+		"Whether the volume is attached to the node.",
+		descVolumeAttachmentLabelsDefaultLabels,
+		nil,
+	)
+)
+
+func createVolumeAttachmentListWatch(kubeClient clientset.Interface, ns string) cache.ListWatch {
+	return cache.ListWatch{
+		ListFunc: func(opts metav1.ListOptions) (runtime.Object, error) {
+			return kubeClient.StorageV1beta1().VolumeAttachments().List(opts)
+		},
+		WatchFunc: func(opts metav1.ListOptions) (watch.Interface, error) {
+			return kubeClient.StorageV1beta1().VolumeAttachments().Watch(opts)
+		},
+	}
+}
+
+func generateVolumeAttachmentMetrics(obj interface{}) []*metrics.Metric {
+	ms := []*metrics.Metric{}
+
+	va := obj.(*storage.VolumeAttachment)
+
+	addGauge := func(desc *metricFamilyDef, v float64, lv ...string) {
+		lv = append([]string{va.Name}, lv...)
+
+		m, err := metrics.NewMetric(desc.Name, desc.LabelKeys, lv, v)
+		if err != nil {
+			panic(err)
+		}
+
+		ms = append(ms, m)
+	}
+
+	// Inline volumes are attached without persistent volume.
+	pv := "<none>"
+	if va.Spec.Source.PersistentVolumeName != nil {
+		pv = *va.Spec.Source.PersistentVolumeName
+	}
+	addGauge(descVolumeAttachmentInfo, 1, va.Spec.Attacher, va.Spec.NodeName, pv)
+
+	if !va.CreationTimestamp.IsZero() {
+		addGauge(descVolumeAttachmentCreated, float64(va.CreationTimestamp.Unix()))
+	}
+
+	addGauge(descVolumeAttachmentStatusAttached, boolFloat64(va.Status.Attached))
+
+	return ms
+}
@@ -0,0 +1,107 @@
+/*
+Copyright 2018 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package collectors
+
+import (
+	"testing"
+	"time"
+
+	storage "k8s.io/api/storage/v1beta1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestVolumeAttachmentCollector(t *testing.T) {
+	// Fixed metadata on type and help text. We prepend this to every expected
+	// output so we only have to modify a single place when doing adjustments.
+	const metadata = `
+		# HELP kube_volumeattachment_info Information about volumeattachment.
+		# TYPE kube_volumeattachment_info gauge
+		# HELP kube_volumeattachment_created Unix creation timestamp
+		# TYPE kube_volumeattachment_created gauge
+		# HELP kube_volumeattachment_status_attached Whether the volume is attached to the node.
+		# TYPE kube_volumeattachment_status_attached gauge
+	`
+	pv1, pv2 := "pv1", "pv2"
+	cases := []generateMetricsTestCase{
+		{
+			Obj: &storage.VolumeAttachment{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:              "csi-attached",
+					CreationTimestamp: metav1.Time{Time: time.Unix(1500000000, 0)},
+				},
+				Spec: storage.VolumeAttachmentSpec{
+					Attacher: "ebs.csi.aws.com",
+					NodeName: "node1",
+					Source:   storage.VolumeAttachmentSource{PersistentVolumeName: &pv1},
+				},
+				Status: storage.VolumeAttachmentStatus{
+					Attached:           true,
+					AttachmentMetadata: map[string]string{"devicePath": "/dev/xvdba"},
+				},
+			},
+			Want: `
+				kube_volumeattachment_created{volumeattachment="csi-attached"} 1.5e+09
+				kube_volumeattachment_info{attacher="ebs.csi.aws.com",node="node1",pv="pv1",volumeattachment="csi-attached"} 1
+				kube_volumeattachment_status_attached{volumeattachment="csi-attached"} 1
+`,
+		},
+		{
+			// Deleted attachment whose volume is being detached.
+			Obj: &storage.VolumeAttachment{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:              "csi-detaching",
+					DeletionTimestamp: &metav1.Time{Time: time.Unix(1500000300, 0)},
+				},
+				Spec: storage.VolumeAttachmentSpec{
+					Attacher: "ebs.csi.aws.com",
+					NodeName: "node2",
+					Source:   storage.VolumeAttachmentSource{PersistentVolumeName: &pv2},
+				},
+				Status: storage.VolumeAttachmentStatus{
+					Attached:    false,
+					DetachError: &storage.VolumeError{Message: "volume is still in use"},
+				},
+			},
+			Want: `
+				kube_volumeattachment_info{attacher="ebs.csi.aws.com",node="node2",pv="pv2",volumeattachment="csi-detaching"} 1
+				kube_volumeattachment_status_attached{volumeattachment="csi-detaching"} 0
+`,
+		},
+		{
+			// Inline volume without persistent volume, not attached yet.
+			Obj: &storage.VolumeAttachment{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: "csi-inline",
+				},
+				Spec: storage.VolumeAttachmentSpec{
+					Attacher: "pd.csi.storage.gke.io",
+					NodeName: "node3",
+				},
+			},
+			Want: `
+				kube_volumeattachment_info{attacher="pd.csi.storage.gke.io",node="node3",pv="<none>",volumeattachment="csi-inline"} 1
+				kube_volumeattachment_status_attached{volumeattachment="csi-inline"} 0
+`,
+		},
+	}
+	for i, c := range cases {
+		c.Func = generateVolumeAttachmentMetrics
+		if err := c.run(); err != nil {
+			t.Errorf("unexpected collecting result in %vth run:\n%s", i, err)
+		}
+	}
+}
@@ -0,0 +1,148 @@
+/*
+Copyright 2018 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package customresource
+
+import (
+	"encoding/json"
+	"io"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/conversion/queryparams"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/runtime/serializer"
+	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/cache"
+)
+
+// Client lists and watches the objects of a single resource as
+// *unstructured.Unstructured.
+type Client struct {
+	rest     rest.Interface
+	resource Resource
+}
+
+// NewClient returns a Client for the given resource talking to the API server
+// described by config.
+func NewClient(config *rest.Config, r Resource) (*Client, error) {
+	conf := rest.CopyConfig(config)
+	conf.ContentConfig = contentConfig()
+	conf.GroupVersion = &schema.GroupVersion{Group: r.Group, Version: r.Version}
+	conf.APIPath = "/apis"
+	if r.Group == "" {
+		conf.APIPath = "/api"
+	}
+
+	c, err := rest.RESTClientFor(conf)
+	if err != nil {
+		return nil, err
+	}
+	return &Client{rest: c, resource: r}, nil
+}
+
+// ListWatch returns a ListWatch for the objects of the resource in the given
+// namespace. The namespace is ignored for cluster-scoped resources.
+func (c *Client) ListWatch(ns string) cache.ListWatch {
+	return cache.ListWatch{
+		ListFunc: func(opts metav1.ListOptions) (runtime.Object, error) {
+			list := &unstructured.UnstructuredList{}
+			req, err := c.request(ns, opts)
+			if err != nil {
+				return nil, err
+			}
+			if err := req.Do().Into(list); err != nil {
+				return nil, err
+			}
+			return list, nil
+		},
+		WatchFunc: func(opts metav1.ListOptions) (watch.Interface, error) {
+			opts.Watch = true
+			req, err := c.request(ns, opts)
+			if err != nil {
+				return nil, err
+			}
+			return req.Watch()
+		},
+	}
+}
+
+func (c *Client) request(ns string, opts metav1.ListOptions) (*rest.Request, error) {
+	// The list options can not be converted to the version of an arbitrary
+	// resource, hence they are encoded as is instead of using
+	// VersionedParams.
+	params, err := queryparams.Convert(&opts)
+	if err != nil {
+		return nil, err
+	}
+
+	req := c.rest.Get().
+		NamespaceIfScoped(ns, c.resource.Namespaced && ns != metav1.NamespaceAll).
+		Resource(c.resource.Resource)
+	for k, vs := range params {
+		for _, v := range vs {
+			req = req.Param(k, v)
+		}
+	}
+	return req, nil
+}
+
+// contentConfig returns a ContentConfig decoding JSON into unstructured
+// objects. Watch events are still decoded by the regular JSON serializer,
+// only the embedded objects are unstructured.
+func contentConfig() rest.ContentConfig {
+	var jsonInfo runtime.SerializerInfo
+	for _, info := range scheme.Codecs.SupportedMediaTypes() {
+		if info.MediaType == runtime.ContentTypeJSON {
+			jsonInfo = info
+			break
+		}
+	}
+
+	jsonInfo.Serializer = unstructuredCodec{}
+	jsonInfo.PrettySerializer = nil
+	return rest.ContentConfig{
+		AcceptContentTypes:   runtime.ContentTypeJSON,
+		ContentType:          runtime.ContentTypeJSON,
+		NegotiatedSerializer: serializer.NegotiatedSerializerWrapper(jsonInfo),
+	}
+}
+
+type unstructuredCodec struct{}
+
+func (unstructuredCodec) Decode(data []byte, gvk *schema.GroupVersionKind, obj runtime.Object) (runtime.Object, *schema.GroupVersionKind, error) {
+	obj, gvk, err := unstructured.UnstructuredJSONScheme.Decode(data, gvk, obj)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// Decode failure responses as Status to surface the API server's error
+	// message.
+	if _, ok := obj.(*metav1.Status); !ok && gvk.Kind == "Status" {
+		obj = &metav1.Status{}
+		if err := json.Unmarshal(data, obj); err != nil {
+			return nil, nil, err
+		}
+	}
+	return obj, gvk, nil
+}
+
+func (unstructuredCodec) Encode(obj runtime.Object, w io.Writer) error {
+	return unstructured.UnstructuredJSONScheme.Encode(obj, w)
+}
@@ -0,0 +1,99 @@
+/*
+Copyright 2018 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package customresource
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/client-go/rest"
+)
+
+const widget = `{"apiVersion":"example.com/v1","kind":"Widget","metadata":{"namespace":"ns1","name":"widget1"},"spec":{"replicas":3}}`
+
+// newFakeAPIServer returns a server answering list and watch requests for
+// widgets in namespace ns1 with a single widget.
+func newFakeAPIServer(t *testing.T) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/apis/example.com/v1/namespaces/ns1/widgets" {
+			t.Errorf("unexpected request path %q", r.URL.Path)
+			http.NotFound(w, r)
+			return
+		}
+		if got := r.URL.Query().Get("labelSelector"); got != "app=foo" {
+			t.Errorf("expected label selector %q, got %q", "app=foo", got)
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		if r.URL.Query().Get("watch") == "true" {
+			fmt.Fprintf(w, `{"type":"ADDED","object":%s}`+"\n", widget)
+			return
+		}
+		fmt.Fprintf(w, `{"apiVersion":"example.com/v1","kind":"WidgetList","metadata":{"resourceVersion":"1"},"items":[%s]}`, widget)
+	}))
+}
+
+func TestClientListWatch(t *testing.T) {
+	server := newFakeAPIServer(t)
+	defer server.Close()
+
+	client, err := NewClient(&rest.Config{Host: server.URL}, Resource{
+		Group:      "example.com",
+		Version:    "v1",
+		Resource:   "widgets",
+		Namespaced: true,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	lw := client.ListWatch("ns1")
+	opts := metav1.ListOptions{LabelSelector: "app=foo"}
+
+	obj, err := lw.List(opts)
+	if err != nil {
+		t.Fatalf("failed to list: %v", err)
+	}
+	list, ok := obj.(*unstructured.UnstructuredList)
+	if !ok {
+		t.Fatalf("expected *unstructured.UnstructuredList, got %T", obj)
+	}
+	if len(list.Items) != 1 || list.Items[0].GetName() != "widget1" {
+		t.Fatalf("expected widget1 to be listed, got %v", list.Items)
+	}
+
+	w, err := lw.Watch(opts)
+	if err != nil {
+		t.Fatalf("failed to watch: %v", err)
+	}
+	defer w.Stop()
+	e := <-w.ResultChan()
+	if e.Type != watch.Added {
+		t.Fatalf("expected %s event, got %s", watch.Added, e.Type)
+	}
+	u, ok := e.Object.(*unstructured.Unstructured)
+	if !ok {
+		t.Fatalf("expected *unstructured.Unstructured, got %T", e.Object)
+	}
+	if u.GetNamespace() != "ns1" || u.GetName() != "widget1" {
+		t.Fatalf("expected ns1/widget1, got %s/%s", u.GetNamespace(), u.GetName())
+	}
+}
@@ -0,0 +1,158 @@
+/*
+Copyright 2018 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package customresource exposes metrics of arbitrary, e.g. custom, resources
+// as described by a configuration file.
+package customresource
+
+import (
+	"fmt"
+	"io/ioutil"
+	"regexp"
+	"sort"
+
+	"github.com/ghodss/yaml"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/util/jsonpath"
+)
+
+var (
+	metricNameRE = regexp.MustCompile(`^[a-zA-Z_:][a-zA-Z0-9_:]*$`)
+	labelNameRE  = regexp.MustCompile(`^[a-zA-Z_][a-zA-Z0-9_]*$`)
+)
+
+// Config describes the resources to expose metrics of.
+type Config struct {
+	Resources []Resource `json:"resources"`
+}
+
+// Resource describes a single resource, identified by its group, version and
+// plural name, and the metric families exposed for each of its objects.
+type Resource struct {
+	Group      string   `json:"group"`
+	Version    string   `json:"version"`
+	Resource   string   `json:"resource"`
+	Namespaced bool     `json:"namespaced"`
+	Metrics    []Metric `json:"metrics"`
+}
+
+// Metric describes a gauge metric family. Value and the values of Labels are
+// JSONPath expressions evaluated against each object, e.g. '.spec.replicas'.
+// Every metric is labeled with the namespace and name of its object in
+// addition to the configured labels. An empty Value results in a constant
+// value of 1. Help only documents the metric in the config, as no HELP lines
+// are exposed.
+type Metric struct {
+	Name   string            `json:"name"`
+	Help   string            `json:"help"`
+	Value  string            `json:"value"`
+	Labels map[string]string `json:"labels"`
+
+	// labelNames are the sorted names of Labels, set on validation.
+	labelNames []string
+}
+
+// sortedLabelNames returns the sorted names of the labels of m.
+func (m Metric) sortedLabelNames() []string {
+	if m.labelNames != nil {
+		return m.labelNames
+	}
+	names := make([]string, 0, len(m.Labels))
+	for l := range m.Labels {
+		names = append(names, l)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// GroupVersionResource returns the group, version and resource of r.
+func (r Resource) GroupVersionResource() schema.GroupVersionResource {
+	return schema.GroupVersionResource{Group: r.Group, Version: r.Version, Resource: r.Resource}
+}
+
+// LoadConfig reads and validates the configuration file at the given path.
+func LoadConfig(path string) (*Config, error) {
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return ParseConfig(b)
+}
+
+// ParseConfig parses and validates the given YAML or JSON configuration.
+func ParseConfig(b []byte) (*Config, error) {
+	c := &Config{}
+	if err := yaml.Unmarshal(b, c); err != nil {
+		return nil, fmt.Errorf("failed to parse custom resource config: %v", err)
+	}
+	if err := c.validate(); err != nil {
+		return nil, fmt.Errorf("invalid custom resource config: %v", err)
+	}
+	return c, nil
+}
+
+func (c *Config) validate() error {
+	seen := map[schema.GroupVersionResource]bool{}
+	for i := range c.Resources {
+		r := &c.Resources[i]
+		gvr := r.GroupVersionResource()
+		if r.Version == "" || r.Resource == "" {
+			return fmt.Errorf("resource %q has to specify version and resource", gvr)
+		}
+		if seen[gvr] {
+			return fmt.Errorf("resource %q is configured more than once", gvr)
+		}
+		seen[gvr] = true
+
+		if len(r.Metrics) == 0 {
+			return fmt.Errorf("resource %q has no metrics", gvr)
+		}
+		for j := range r.Metrics {
+			m := &r.Metrics[j]
+			if !metricNameRE.MatchString(m.Name) {
+				return fmt.Errorf("resource %q: invalid metric name %q", gvr, m.Name)
+			}
+			if m.Value != "" {
+				if _, err := parseJSONPath(m.Value); err != nil {
+					return fmt.Errorf("metric %q: invalid value: %v", m.Name, err)
+				}
+			}
+			for l, p := range m.Labels {
+				if !labelNameRE.MatchString(l) || l == "namespace" || l == "name" {
+					return fmt.Errorf("metric %q: invalid label name %q", m.Name, l)
+				}
+				if _, err := parseJSONPath(p); err != nil {
+					return fmt.Errorf("metric %q: invalid label %q: %v", m.Name, l, err)
+				}
+			}
+			m.labelNames = m.sortedLabelNames()
+		}
+	}
+	return nil
+}
+
+// parseJSONPath parses a JSONPath expression, which may be given with or
+// without enclosing braces.
+func parseJSONPath(expr string) (*jsonpath.JSONPath, error) {
+	if len(expr) == 0 || expr[0] != '{' {
+		expr = "{" + expr + "}"
+	}
+	j := jsonpath.New("").AllowMissingKeys(true)
+	if err := j.Parse(expr); err != nil {
+		return nil, err
+	}
+	return j, nil
+}
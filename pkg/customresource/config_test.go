@@ -0,0 +1,138 @@
+/*
+Copyright 2018 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package customresource
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseConfig(t *testing.T) {
+	tests := []struct {
+		Desc        string
+		Config      string
+		WantedError bool
+	}{
+		{
+			Desc: "valid config",
+			Config: `
+resources:
+- group: example.com
+  version: v1
+  resource: widgets
+  namespaced: true
+  metrics:
+  - name: widget_replicas
+    help: Desired replicas of the widget.
+    value: .spec.replicas
+    labels:
+      phase: .status.phase
+  - name: widget_info
+    help: Information about the widget.
+`,
+			WantedError: false,
+		},
+		{
+			Desc:        "malformed yaml",
+			Config:      "resources: [",
+			WantedError: true,
+		},
+		{
+			Desc: "missing resource",
+			Config: `
+resources:
+- group: example.com
+  version: v1
+  metrics:
+  - name: widget_info
+`,
+			WantedError: true,
+		},
+		{
+			Desc: "duplicate resource",
+			Config: `
+resources:
+- {group: example.com, version: v1, resource: widgets, metrics: [{name: widget_info}]}
+- {group: example.com, version: v1, resource: widgets, metrics: [{name: widget_created}]}
+`,
+			WantedError: true,
+		},
+		{
+			Desc: "no metrics",
+			Config: `
+resources:
+- {group: example.com, version: v1, resource: widgets}
+`,
+			WantedError: true,
+		},
+		{
+			Desc: "invalid metric name",
+			Config: `
+resources:
+- {group: example.com, version: v1, resource: widgets, metrics: [{name: widget-info}]}
+`,
+			WantedError: true,
+		},
+		{
+			Desc: "invalid value expression",
+			Config: `
+resources:
+- {group: example.com, version: v1, resource: widgets, metrics: [{name: widget_replicas, value: ".spec[replicas"}]}
+`,
+			WantedError: true,
+		},
+		{
+			Desc: "reserved label name",
+			Config: `
+resources:
+- {group: example.com, version: v1, resource: widgets, metrics: [{name: widget_info, labels: {namespace: .metadata.namespace}}]}
+`,
+			WantedError: true,
+		},
+	}
+
+	for _, test := range tests {
+		_, err := ParseConfig([]byte(test.Config))
+		if !test.WantedError && err != nil {
+			t.Errorf("Test error for Desc: %s. Unexpected error: %v", test.Desc, err)
+		}
+		if test.WantedError && err == nil {
+			t.Errorf("Test error for Desc: %s. Expected error, got none.", test.Desc)
+		}
+	}
+}
+
+func TestParseConfigSortsLabelNames(t *testing.T) {
+	c, err := ParseConfig([]byte(`
+resources:
+- group: example.com
+  version: v1
+  resource: widgets
+  metrics:
+  - name: widget_info
+    labels:
+      phase: .status.phase
+      color: .spec.color
+      size: .spec.size
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := c.Resources[0].Metrics[0].labelNames, []string{"color", "phase", "size"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("expected label names %v, got %v", want, got)
+	}
+}
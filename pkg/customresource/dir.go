@@ -0,0 +1,127 @@
+/*
+Copyright 2018 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package customresource
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"time"
+
+	"k8s.io/kube-state-metrics/pkg/log"
+)
+
+// DirWatcher watches a directory of config files, i.e. files ending in .yaml,
+// .yml or .json, and reports added, modified and removed files. The directory
+// is polled, as the vendored dependencies provide no file system
+// notifications.
+type DirWatcher struct {
+	dir      string
+	onChange func(path string, c *Config)
+	files    map[string]fileState
+}
+
+type fileState struct {
+	modTime time.Time
+	size    int64
+}
+
+// NewDirWatcher returns a DirWatcher for the given directory. onChange is
+// called with the parsed config whenever a file is added or modified, and
+// with a nil config when a file is removed. Files failing to parse are logged
+// and skipped, keeping the config of their previous version in place.
+func NewDirWatcher(dir string, onChange func(path string, c *Config)) *DirWatcher {
+	return &DirWatcher{
+		dir:      dir,
+		onChange: onChange,
+		files:    map[string]fileState{},
+	}
+}
+
+// Run syncs the directory every interval until ctx is done.
+func (w *DirWatcher) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := w.Sync(); err != nil {
+				log.Errorf("Failed to sync custom resource config directory %s: %v", w.dir, err)
+			}
+		}
+	}
+}
+
+// Sync compares the directory with its state at the previous call and calls
+// onChange for every added, modified and removed config file.
+func (w *DirWatcher) Sync() error {
+	infos, err := ioutil.ReadDir(w.dir)
+	if err != nil {
+		return err
+	}
+
+	seen := map[string]bool{}
+	for _, info := range infos {
+		if !isConfigFile(info.Name()) {
+			continue
+		}
+		path := filepath.Join(w.dir, info.Name())
+		// Follow symlinks, as the files of a mounted ConfigMap are symlinks
+		// whose targets are replaced on update.
+		info, err := os.Stat(path)
+		if err != nil || info.IsDir() {
+			continue
+		}
+		seen[path] = true
+
+		state := fileState{modTime: info.ModTime(), size: info.Size()}
+		if old, ok := w.files[path]; ok && old == state {
+			continue
+		}
+		w.files[path] = state
+
+		c, err := LoadConfig(path)
+		if err != nil {
+			log.Errorf("Failed to load custom resource config %s: %v", path, err)
+			continue
+		}
+		w.onChange(path, c)
+	}
+
+	for path := range w.files {
+		if !seen[path] {
+			delete(w.files, path)
+			w.onChange(path, nil)
+		}
+	}
+
+	return nil
+}
+
+func isConfigFile(name string) bool {
+	switch filepath.Ext(name) {
+	case ".yaml", ".yml", ".json":
+		// Skip hidden files, e.g. the timestamped directories and symlinks
+		// of mounted ConfigMaps.
+		return name[0] != '.'
+	}
+	return false
+}
@@ -0,0 +1,133 @@
+/*
+Copyright 2018 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package customresource
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+const (
+	widgetsConfig = `
+resources:
+- {group: example.com, version: v1, resource: widgets, metrics: [{name: widget_info}]}
+`
+	gadgetsConfig = `
+resources:
+- {group: example.com, version: v1, resource: gadgets, metrics: [{name: gadget_info}]}
+`
+)
+
+func TestDirWatcherSync(t *testing.T) {
+	dir, err := ioutil.TempDir("", "customresource")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	// loaded maps each file to the resources of its last reported config.
+	loaded := map[string][]string{}
+	w := NewDirWatcher(dir, func(path string, c *Config) {
+		if c == nil {
+			delete(loaded, filepath.Base(path))
+			return
+		}
+		resources := []string{}
+		for _, r := range c.Resources {
+			resources = append(resources, r.Resource)
+		}
+		loaded[filepath.Base(path)] = resources
+	})
+
+	mtime := time.Now()
+	writeFile := func(name, content string) {
+		path := filepath.Join(dir, name)
+		if err := ioutil.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+		// Make sure every write is detected regardless of the
+		// resolution of the file system timestamps.
+		mtime = mtime.Add(time.Second)
+		if err := os.Chtimes(path, mtime, mtime); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tests := []struct {
+		Desc   string
+		Change func()
+		Want   map[string][]string
+	}{
+		{
+			Desc:   "empty directory",
+			Change: func() {},
+			Want:   map[string][]string{},
+		},
+		{
+			Desc: "file added",
+			Change: func() {
+				writeFile("widgets.yaml", widgetsConfig)
+				writeFile("README.md", "not a config file")
+			},
+			Want: map[string][]string{"widgets.yaml": {"widgets"}},
+		},
+		{
+			Desc: "second file added",
+			Change: func() {
+				writeFile("gadgets.yml", gadgetsConfig)
+			},
+			Want: map[string][]string{"widgets.yaml": {"widgets"}, "gadgets.yml": {"gadgets"}},
+		},
+		{
+			Desc: "file modified",
+			Change: func() {
+				writeFile("widgets.yaml", gadgetsConfig)
+			},
+			Want: map[string][]string{"widgets.yaml": {"gadgets"}, "gadgets.yml": {"gadgets"}},
+		},
+		{
+			Desc: "invalid modification keeps previous config",
+			Change: func() {
+				writeFile("widgets.yaml", "resources: [")
+			},
+			Want: map[string][]string{"widgets.yaml": {"gadgets"}, "gadgets.yml": {"gadgets"}},
+		},
+		{
+			Desc: "file deleted",
+			Change: func() {
+				if err := os.Remove(filepath.Join(dir, "gadgets.yml")); err != nil {
+					t.Fatal(err)
+				}
+			},
+			Want: map[string][]string{"widgets.yaml": {"gadgets"}},
+		},
+	}
+
+	for _, test := range tests {
+		test.Change()
+		if err := w.Sync(); err != nil {
+			t.Fatalf("Test error for Desc: %s. Unexpected error: %v", test.Desc, err)
+		}
+		if !reflect.DeepEqual(loaded, test.Want) {
+			t.Errorf("Test error for Desc: %s. Want: %v, got: %v", test.Desc, test.Want, loaded)
+		}
+	}
+}
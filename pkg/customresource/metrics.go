@@ -0,0 +1,145 @@
+/*
+Copyright 2018 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package customresource
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/kube-state-metrics/pkg/log"
+	"k8s.io/kube-state-metrics/pkg/metrics"
+)
+
+// GenerateFunc returns a function generating the configured metrics of the
+// given resource for an *unstructured.Unstructured object. Metrics whose
+// value can not be found or converted to a number are skipped.
+func GenerateFunc(r Resource) func(interface{}) []*metrics.Metric {
+	labelNames := make([][]string, len(r.Metrics))
+	for i, m := range r.Metrics {
+		labelNames[i] = m.sortedLabelNames()
+	}
+
+	return func(obj interface{}) []*metrics.Metric {
+		u := obj.(*unstructured.Unstructured)
+		ms := []*metrics.Metric{}
+
+		for i, m := range r.Metrics {
+			v, ok, err := evalValue(m.Value, u.Object)
+			if err != nil {
+				log.Warningf("metric %s of %s/%s: %v", m.Name, u.GetNamespace(), u.GetName(), err)
+				continue
+			}
+			if !ok {
+				continue
+			}
+
+			labelKeys := []string{"namespace", "name"}
+			labelValues := []string{u.GetNamespace(), u.GetName()}
+			for _, l := range labelNames[i] {
+				lv, err := evalLabel(m.Labels[l], u.Object)
+				if err != nil {
+					log.Warningf("label %s of metric %s of %s/%s: %v", l, m.Name, u.GetNamespace(), u.GetName(), err)
+				}
+				labelKeys = append(labelKeys, l)
+				labelValues = append(labelValues, lv)
+			}
+
+			metric, err := metrics.NewMetric(m.Name, labelKeys, labelValues, v)
+			if err != nil {
+				panic(err)
+			}
+			ms = append(ms, metric)
+		}
+
+		return ms
+	}
+}
+
+// evalValue evaluates the given JSONPath expression against data and converts
+// the first result to a float64. The returned bool is false if the
+// expression yields no result.
+func evalValue(expr string, data map[string]interface{}) (float64, bool, error) {
+	if expr == "" {
+		return 1, true, nil
+	}
+
+	v, ok, err := evalFirst(expr, data)
+	if err != nil || !ok {
+		return 0, false, err
+	}
+
+	switch t := v.(type) {
+	case int64:
+		return float64(t), true, nil
+	case float64:
+		return t, true, nil
+	case bool:
+		if t {
+			return 1, true, nil
+		}
+		return 0, true, nil
+	case string:
+		f, err := strconv.ParseFloat(t, 64)
+		if err != nil {
+			return 0, false, fmt.Errorf("value %q of %s is not a number", t, expr)
+		}
+		return f, true, nil
+	default:
+		return 0, false, fmt.Errorf("value of %s has unsupported type %T", expr, v)
+	}
+}
+
+// evalLabel evaluates the given JSONPath expression against data and formats
+// the first result as a label value. It returns an empty string if the
+// expression yields no result.
+func evalLabel(expr string, data map[string]interface{}) (string, error) {
+	v, ok, err := evalFirst(expr, data)
+	if err != nil || !ok {
+		return "", err
+	}
+	if s, ok := v.(string); ok {
+		return s, nil
+	}
+	return fmt.Sprint(v), nil
+}
+
+func evalFirst(expr string, data map[string]interface{}) (interface{}, bool, error) {
+	// JSONPath instances keep state while evaluating, hence they are not
+	// shared between concurrent reflectors.
+	j, err := parseJSONPath(expr)
+	if err != nil {
+		return nil, false, err
+	}
+	results, err := j.FindResults(data)
+	if err != nil {
+		return nil, false, err
+	}
+	if len(results) == 0 || len(results[0]) == 0 {
+		return nil, false, nil
+	}
+
+	v := results[0][0]
+	if v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			return nil, false, nil
+		}
+		v = v.Elem()
+	}
+	return v.Interface(), true, nil
+}
@@ -0,0 +1,86 @@
+/*
+Copyright 2018 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package customresource
+
+import (
+	"reflect"
+	"testing"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func TestGenerateFunc(t *testing.T) {
+	r := Resource{
+		Group:      "example.com",
+		Version:    "v1",
+		Resource:   "widgets",
+		Namespaced: true,
+		Metrics: []Metric{
+			{Name: "widget_info"},
+			{Name: "widget_replicas", Value: ".spec.replicas", Labels: map[string]string{"phase": ".status.phase"}},
+			{Name: "widget_ratio", Value: "{.status.ratio}"},
+			{Name: "widget_ready", Value: ".status.ready"},
+			{Name: "widget_size", Value: ".spec.size"},
+			{Name: "widget_missing", Value: ".status.missing"},
+			{Name: "widget_invalid", Value: ".status.phase"},
+		},
+	}
+
+	tests := []struct {
+		Desc string
+		Obj  map[string]interface{}
+		Want []string
+	}{
+		{
+			Desc: "all fields set",
+			Obj: map[string]interface{}{
+				"metadata": map[string]interface{}{"namespace": "ns1", "name": "widget1"},
+				"spec":     map[string]interface{}{"replicas": int64(3), "size": "1.5"},
+				"status":   map[string]interface{}{"phase": "Running", "ratio": 0.25, "ready": true},
+			},
+			Want: []string{
+				`widget_info{name="widget1",namespace="ns1"} 1` + "\n",
+				`widget_replicas{name="widget1",namespace="ns1",phase="Running"} 3` + "\n",
+				`widget_ratio{name="widget1",namespace="ns1"} 0.25` + "\n",
+				`widget_ready{name="widget1",namespace="ns1"} 1` + "\n",
+				`widget_size{name="widget1",namespace="ns1"} 1.5` + "\n",
+			},
+		},
+		{
+			Desc: "missing fields",
+			Obj: map[string]interface{}{
+				"metadata": map[string]interface{}{"namespace": "ns1", "name": "widget2"},
+				"spec":     map[string]interface{}{"replicas": int64(0)},
+			},
+			Want: []string{
+				`widget_info{name="widget2",namespace="ns1"} 1` + "\n",
+				`widget_replicas{name="widget2",namespace="ns1",phase=""} 0` + "\n",
+			},
+		},
+	}
+
+	f := GenerateFunc(r)
+	for _, test := range tests {
+		got := []string{}
+		for _, m := range f(&unstructured.Unstructured{Object: test.Obj}) {
+			got = append(got, string(*m))
+		}
+		if !reflect.DeepEqual(got, test.Want) {
+			t.Errorf("Test error for Desc: %s. Want:\n%q\nGot:\n%q", test.Desc, test.Want, got)
+		}
+	}
+}
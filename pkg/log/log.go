@@ -0,0 +1,137 @@
+/*
+Copyright 2018 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package log is the logging abstraction of kube-state-metrics. It logs via
+// glog by default, or as JSON lines once SetJSONOutput is called.
+package log
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/golang/glog"
+)
+
+const (
+	levelInfo    = "info"
+	levelWarning = "warning"
+	levelError   = "error"
+	levelFatal   = "fatal"
+)
+
+var (
+	mtx sync.Mutex
+	// jsonOutput is the writer JSON lines are logged to. If nil, logging
+	// goes through glog.
+	jsonOutput io.Writer
+	now        = time.Now
+	exit       = os.Exit
+)
+
+// entry is a single JSON log line.
+type entry struct {
+	Level   string `json:"level"`
+	Time    string `json:"ts"`
+	Message string `json:"msg"`
+}
+
+// SetJSONOutput makes all subsequent logging write JSON lines with level,
+// timestamp and message fields to w. A nil w switches back to glog.
+func SetJSONOutput(w io.Writer) {
+	mtx.Lock()
+	defer mtx.Unlock()
+	jsonOutput = w
+}
+
+// Info logs at info level, formatting the arguments like fmt.Sprint.
+func Info(args ...interface{}) {
+	logMessage(levelInfo, fmt.Sprint(args...))
+}
+
+// Infof logs at info level, formatting the arguments like fmt.Sprintf.
+func Infof(format string, args ...interface{}) {
+	logMessage(levelInfo, fmt.Sprintf(format, args...))
+}
+
+// Warningf logs at warning level, formatting the arguments like
+// fmt.Sprintf.
+func Warningf(format string, args ...interface{}) {
+	logMessage(levelWarning, fmt.Sprintf(format, args...))
+}
+
+// Error logs at error level, formatting the arguments like fmt.Sprint.
+func Error(args ...interface{}) {
+	logMessage(levelError, fmt.Sprint(args...))
+}
+
+// Errorf logs at error level, formatting the arguments like fmt.Sprintf.
+func Errorf(format string, args ...interface{}) {
+	logMessage(levelError, fmt.Sprintf(format, args...))
+}
+
+// Fatal logs at fatal level, formatting the arguments like fmt.Sprint, and
+// exits.
+func Fatal(args ...interface{}) {
+	logMessage(levelFatal, fmt.Sprint(args...))
+}
+
+// Fatalf logs at fatal level, formatting the arguments like fmt.Sprintf,
+// and exits.
+func Fatalf(format string, args ...interface{}) {
+	logMessage(levelFatal, fmt.Sprintf(format, args...))
+}
+
+func logMessage(level, msg string) {
+	mtx.Lock()
+	w := jsonOutput
+	mtx.Unlock()
+
+	if w == nil {
+		// Skip logMessage and the exported function calling it, so that glog
+		// reports the file and line of the caller.
+		const depth = 2
+		switch level {
+		case levelInfo:
+			glog.InfoDepth(depth, msg)
+		case levelWarning:
+			glog.WarningDepth(depth, msg)
+		case levelError:
+			glog.ErrorDepth(depth, msg)
+		case levelFatal:
+			glog.FatalDepth(depth, msg)
+		}
+		return
+	}
+
+	line, err := json.Marshal(entry{
+		Level:   level,
+		Time:    now().UTC().Format(time.RFC3339Nano),
+		Message: msg,
+	})
+	if err == nil {
+		mtx.Lock()
+		w.Write(append(line, '\n'))
+		mtx.Unlock()
+	}
+	if level == levelFatal {
+		// Like glog, exit with 255 on fatal errors.
+		exit(255)
+	}
+}
@@ -0,0 +1,67 @@
+/*
+Copyright 2018 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package log
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestJSONOutput(t *testing.T) {
+	buf := &bytes.Buffer{}
+	SetJSONOutput(buf)
+	defer SetJSONOutput(nil)
+
+	now = func() time.Time { return time.Unix(1500000000, 0) }
+	defer func() { now = time.Now }()
+	exitCode := -1
+	exit = func(code int) { exitCode = code }
+	defer func() { exit = os.Exit }()
+
+	Infof("Active collectors: %s", "pods,nodes")
+	Warningf("No REST config given, not watching %s", "leases")
+	Error("error encoding and sending metric family: ", "broken pipe")
+	Fatalf("Error: %s", "invalid \"quoted\" value")
+
+	want := []entry{
+		{Level: "info", Time: "2017-07-14T02:40:00Z", Message: "Active collectors: pods,nodes"},
+		{Level: "warning", Time: "2017-07-14T02:40:00Z", Message: "No REST config given, not watching leases"},
+		{Level: "error", Time: "2017-07-14T02:40:00Z", Message: "error encoding and sending metric family: broken pipe"},
+		{Level: "fatal", Time: "2017-07-14T02:40:00Z", Message: "Error: invalid \"quoted\" value"},
+	}
+
+	lines := strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n")
+	got := []entry{}
+	for _, line := range lines {
+		e := entry{}
+		if err := json.Unmarshal([]byte(line), &e); err != nil {
+			t.Fatalf("expected JSON line, got %q: %v", line, err)
+		}
+		got = append(got, e)
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("expected log entries %+v, got %+v", want, got)
+	}
+	if exitCode != 255 {
+		t.Errorf("expected exit code 255 on fatal, got %d", exitCode)
+	}
+}
@@ -21,9 +21,7 @@ import (
 	"fmt"
 	"sort"
 	"strings"
-
-	"github.com/prometheus/client_golang/prometheus"
-	dto "github.com/prometheus/client_model/go"
+	"time"
 
 	"k8s.io/kube-state-metrics/pkg/options"
 )
@@ -81,63 +79,119 @@ func escapeString(v string) string {
 	return escapeWithDoubleQuote.Replace(v)
 }
 
+// Name returns the name of the metric, i.e. everything in front of the label
+// set or, in case there are no labels, in front of the value.
+func (m *Metric) Name() string {
+	s := string(*m)
+	if i := strings.IndexAny(s, "{ "); i != -1 {
+		return s[:i]
+	}
+	return s
+}
+
 // MetricFamilyDesc represents the HELP and TYPE string above a metric family list
 type MetricFamilyDesc string
 
-type gathererFunc func() ([]*dto.MetricFamily, error)
-
-func (f gathererFunc) Gather() ([]*dto.MetricFamily, error) {
-	return f()
-}
-
-// FilteredGatherer wraps a prometheus.Gatherer to filter metrics based on a
-// white or blacklist. Whitelist and blacklist are mutually exclusive.
-// TODO: Bring white and blacklisting back
-func FilteredGatherer(r prometheus.Gatherer, whitelist options.MetricSet, blacklist options.MetricSet) prometheus.Gatherer {
+// FilteredGenerateFunc wraps a function generating metrics for a Kubernetes
+// object to filter the generated metrics based on a white or blacklist.
+// Whitelist and blacklist are mutually exclusive.
+func FilteredGenerateFunc(f func(interface{}) []*Metric, whitelist *options.MetricMatcher, blacklist *options.MetricMatcher) func(interface{}) []*Metric {
 	whitelistEnabled := !whitelist.IsEmpty()
 	blacklistEnabled := !blacklist.IsEmpty()
 
 	if whitelistEnabled {
-		return gathererFunc(func() ([]*dto.MetricFamily, error) {
-			metricFamilies, err := r.Gather()
-			if err != nil {
-				return nil, err
+		return func(obj interface{}) []*Metric {
+			ms := []*Metric{}
+			for _, m := range f(obj) {
+				if whitelist.Matches(m.Name()) {
+					ms = append(ms, m)
+				}
 			}
+			return ms
+		}
+	}
 
-			newMetricFamilies := []*dto.MetricFamily{}
-			for _, metricFamily := range metricFamilies {
-				// deferencing this string may be a performance bottleneck
-				name := *metricFamily.Name
-				_, onWhitelist := whitelist[name]
-				if onWhitelist {
-					newMetricFamilies = append(newMetricFamilies, metricFamily)
+	if blacklistEnabled {
+		return func(obj interface{}) []*Metric {
+			ms := []*Metric{}
+			for _, m := range f(obj) {
+				if blacklist.Matches(m.Name()) {
+					continue
 				}
+				ms = append(ms, m)
 			}
+			return ms
+		}
+	}
 
-			return newMetricFamilies, nil
-		})
+	return f
+}
+
+// PrefixedGenerateFunc wraps a function generating metrics for a Kubernetes
+// object to prepend the given prefix to the name of every generated metric.
+func PrefixedGenerateFunc(f func(interface{}) []*Metric, prefix string) func(interface{}) []*Metric {
+	if prefix == "" {
+		return f
 	}
 
-	if blacklistEnabled {
-		return gathererFunc(func() ([]*dto.MetricFamily, error) {
-			metricFamilies, err := r.Gather()
-			if err != nil {
-				return nil, err
-			}
+	return func(obj interface{}) []*Metric {
+		ms := f(obj)
+		for i, m := range ms {
+			prefixed := Metric(prefix + string(*m))
+			ms[i] = &prefixed
+		}
+		return ms
+	}
+}
 
-			newMetricFamilies := []*dto.MetricFamily{}
-			for _, metricFamily := range metricFamilies {
-				name := *metricFamily.Name
-				_, onBlacklist := blacklist[name]
-				if onBlacklist {
+// LabeledGenerateFunc wraps a function generating metrics for a Kubernetes
+// object to add a label of the given name and value, e.g. the cluster of the
+// object, to every generated metric. Metrics already having a label of the
+// name keep their own value, as duplicate label names are invalid.
+func LabeledGenerateFunc(f func(interface{}) []*Metric, name, value string) func(interface{}) []*Metric {
+	label := fmt.Sprintf(`%s="%s"`, name, escapeString(value))
+	return func(obj interface{}) []*Metric {
+		ms := f(obj)
+		for i, m := range ms {
+			s := string(*m)
+			// The metric name ends at the label set or, if there are no
+			// labels, at the value.
+			var labeled Metric
+			if j := strings.IndexAny(s, "{ "); s[j] == '{' {
+				if hasLabel(s[j:], name) {
 					continue
 				}
-				newMetricFamilies = append(newMetricFamilies, metricFamily)
+				labeled = Metric(s[:j+1] + label + "," + s[j+1:])
+			} else {
+				labeled = Metric(s[:j] + "{" + label + "}" + s[j:])
 			}
-
-			return newMetricFamilies, nil
-		})
+			ms[i] = &labeled
+		}
+		return ms
 	}
+}
 
-	return r
+// hasLabel reports whether the label set of a metric, starting with its
+// opening brace, has a label of the given name. Label values cannot contain
+// unescaped quotes, so a quote following the name marks a label name.
+func hasLabel(labels, name string) bool {
+	return strings.Contains(labels, "{"+name+`="`) || strings.Contains(labels, ","+name+`="`)
+}
+
+// TimestampedGenerateFunc wraps a function generating metrics for a Kubernetes
+// object to append the time the object was observed, as returned by now, to
+// every generated metric. As the metrics of an object are only regenerated on
+// changes of the object, the timestamps of unchanged objects grow old, and
+// Prometheus does not mark metrics with explicit timestamps as stale when
+// they disappear.
+func TimestampedGenerateFunc(f func(interface{}) []*Metric, now func() time.Time) func(interface{}) []*Metric {
+	return func(obj interface{}) []*Metric {
+		ms := f(obj)
+		ts := fmt.Sprintf(" %d\n", now().UnixNano()/int64(time.Millisecond))
+		for i, m := range ms {
+			timestamped := Metric(strings.TrimSuffix(string(*m), "\n") + ts)
+			ms[i] = &timestamped
+		}
+		return ms
+	}
 }
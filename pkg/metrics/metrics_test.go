@@ -2,136 +2,210 @@ package metrics
 
 import (
 	"testing"
+	"time"
 
-	"github.com/prometheus/client_golang/prometheus"
 	"k8s.io/kube-state-metrics/pkg/options"
 )
 
-func TestFiltererdGatherer(t *testing.T) {
-	r := prometheus.NewRegistry()
-	c1 := prometheus.NewCounter(
-		prometheus.CounterOpts{
-			Name: "test1",
-			Help: "test1 help",
-		},
-	)
-	c2 := prometheus.NewCounter(
-		prometheus.CounterOpts{
-			Name: "test2",
-			Help: "test2 help",
-		},
-	)
-	c1.Inc()
-	c1.Inc()
-	c2.Inc()
-	r.MustRegister(c1)
-	r.MustRegister(c2)
-
-	res, err := FilteredGatherer(r, nil, nil).Gather()
+func mustMatcher(t *testing.T, value string, regex bool) *options.MetricMatcher {
+	ms := options.MetricSet{}
+	ms.Set(value)
+	m, err := ms.Matcher(regex)
 	if err != nil {
 		t.Fatal(err)
 	}
+	return m
+}
 
-	found1 := false
-	found2 := false
-	for _, mf := range res {
-		if *mf.Name == "test1" {
-			found1 = true
-		}
-		if *mf.Name == "test2" {
-			found2 = true
-		}
+func generateTestMetrics(obj interface{}) []*Metric {
+	m1, err := NewMetric("test1", []string{"label"}, []string{"value"}, 1)
+	if err != nil {
+		panic(err)
+	}
+	m2, err := NewMetric("test2", nil, nil, 2)
+	if err != nil {
+		panic(err)
 	}
+	return []*Metric{m1, m2}
+}
 
-	if !found1 || !found2 {
+func metricNames(ms []*Metric) map[string]bool {
+	names := map[string]bool{}
+	for _, m := range ms {
+		names[m.Name()] = true
+	}
+	return names
+}
+
+func TestFilteredGenerateFunc(t *testing.T) {
+	names := metricNames(FilteredGenerateFunc(generateTestMetrics, nil, nil)(nil))
+
+	if !names["test1"] || !names["test2"] {
 		t.Fatal("No results expected to be filtered, but results were filtered.")
 	}
 }
 
-func TestFiltererdGathererWhitelist(t *testing.T) {
-	r := prometheus.NewRegistry()
-	c1 := prometheus.NewCounter(
-		prometheus.CounterOpts{
-			Name: "test1",
-			Help: "test1 help",
+func TestFilteredGenerateFuncWhitelist(t *testing.T) {
+	whitelist := mustMatcher(t, "test1", false)
+
+	names := metricNames(FilteredGenerateFunc(generateTestMetrics, whitelist, nil)(nil))
+
+	if !names["test1"] || names["test2"] {
+		t.Fatalf("Expected `test2` to be filtered and `test1` not. `test1`: %t ; `test2`: %t.", names["test1"], names["test2"])
+	}
+}
+
+func TestFilteredGenerateFuncBlacklist(t *testing.T) {
+	blacklist := mustMatcher(t, "test1", false)
+
+	names := metricNames(FilteredGenerateFunc(generateTestMetrics, nil, blacklist)(nil))
+
+	if names["test1"] || !names["test2"] {
+		t.Fatalf("Expected `test1` to be filtered and `test2` not. `test1`: %t ; `test2`: %t.", names["test1"], names["test2"])
+	}
+}
+
+func TestFilteredGenerateFuncPatterns(t *testing.T) {
+	tests := []struct {
+		Desc      string
+		Whitelist string
+		Regex     bool
+		Want1     bool
+		Want2     bool
+	}{
+		{
+			Desc:      "glob matching all",
+			Whitelist: "test*",
+			Want1:     true,
+			Want2:     true,
+		},
+		{
+			Desc:      "glob is anchored",
+			Whitelist: "est*",
+			Want1:     false,
+			Want2:     false,
 		},
-	)
-	c2 := prometheus.NewCounter(
-		prometheus.CounterOpts{
-			Name: "test2",
-			Help: "test2 help",
+		{
+			Desc:      "regex matching one",
+			Whitelist: "test[1]",
+			Regex:     true,
+			Want1:     true,
+			Want2:     false,
 		},
-	)
-	c1.Inc()
-	c1.Inc()
-	c2.Inc()
-	r.MustRegister(c1)
-	r.MustRegister(c2)
+		{
+			Desc:      "regex is anchored",
+			Whitelist: "test",
+			Regex:     true,
+			Want1:     false,
+			Want2:     false,
+		},
+	}
 
-	whitelist := options.MetricSet{}
-	whitelist.Set("test1")
+	for _, test := range tests {
+		whitelist := mustMatcher(t, test.Whitelist, test.Regex)
+		names := metricNames(FilteredGenerateFunc(generateTestMetrics, whitelist, nil)(nil))
 
-	res, err := FilteredGatherer(r, whitelist, nil).Gather()
-	if err != nil {
-		t.Fatal(err)
+		if names["test1"] != test.Want1 || names["test2"] != test.Want2 {
+			t.Errorf("Test error for Desc: %s. Want `test1`: %t, `test2`: %t. Got `test1`: %t, `test2`: %t.", test.Desc, test.Want1, test.Want2, names["test1"], names["test2"])
+		}
 	}
+}
 
-	found1 := false
-	found2 := false
-	for _, mf := range res {
-		if *mf.Name == "test1" {
-			found1 = true
+func TestPrefixedGenerateFunc(t *testing.T) {
+	unprefixed := generateTestMetrics(nil)
+	ms := PrefixedGenerateFunc(generateTestMetrics, "")(nil)
+	for i := range ms {
+		if *ms[i] != *unprefixed[i] {
+			t.Fatalf("expected empty prefix to leave metric unchanged, got %q instead of %q", *ms[i], *unprefixed[i])
 		}
-		if *mf.Name == "test2" {
-			found2 = true
+	}
+
+	ms = PrefixedGenerateFunc(generateTestMetrics, "cluster_a_")(nil)
+	if len(ms) != len(unprefixed) {
+		t.Fatalf("expected %d metrics, got %d", len(unprefixed), len(ms))
+	}
+	for i := range ms {
+		if want := "cluster_a_" + string(*unprefixed[i]); string(*ms[i]) != want {
+			t.Fatalf("expected metric %q, got %q", want, *ms[i])
 		}
 	}
+}
 
-	if !found1 || found2 {
-		t.Fatalf("Expected `test2` to be filtered and `test1` not. `test1`: %t ; `test2`: %t.", found1, found2)
+func TestPrefixedGenerateFuncWhitelist(t *testing.T) {
+	f := PrefixedGenerateFunc(generateTestMetrics, "cluster_a_")
+	names := metricNames(FilteredGenerateFunc(f, mustMatcher(t, "cluster_a_test1", false), nil)(nil))
+
+	if len(names) != 1 || !names["cluster_a_test1"] {
+		t.Fatalf("expected only cluster_a_test1 to be whitelisted, got %v", names)
 	}
 }
 
-func TestFiltererdGathererBlacklist(t *testing.T) {
-	r := prometheus.NewRegistry()
-	c1 := prometheus.NewCounter(
-		prometheus.CounterOpts{
-			Name: "test1",
-			Help: "test1 help",
-		},
-	)
-	c2 := prometheus.NewCounter(
-		prometheus.CounterOpts{
-			Name: "test2",
-			Help: "test2 help",
-		},
-	)
-	c1.Inc()
-	c1.Inc()
-	c2.Inc()
-	r.MustRegister(c1)
-	r.MustRegister(c2)
+func TestLabeledGenerateFunc(t *testing.T) {
+	ms := LabeledGenerateFunc(generateTestMetrics, "cluster", `prod "eu"`)(nil)
 
-	blacklist := options.MetricSet{}
-	blacklist.Set("test1")
+	want := []string{
+		"test1{cluster=\"prod \\\"eu\\\"\",label=\"value\"} 1\n",
+		"test2{cluster=\"prod \\\"eu\\\"\"} 2\n",
+	}
+	if len(ms) != len(want) {
+		t.Fatalf("expected %d metrics, got %d", len(want), len(ms))
+	}
+	for i := range ms {
+		if string(*ms[i]) != want[i] {
+			t.Fatalf("expected metric %q, got %q", want[i], *ms[i])
+		}
+	}
 
-	res, err := FilteredGatherer(r, nil, blacklist).Gather()
-	if err != nil {
-		t.Fatal(err)
+	// Metrics already having the label keep their own value.
+	ms = LabeledGenerateFunc(generateTestMetrics, "label", "other")(nil)
+	want = []string{
+		"test1{label=\"value\"} 1\n",
+		"test2{label=\"other\"} 2\n",
+	}
+	for i := range ms {
+		if string(*ms[i]) != want[i] {
+			t.Fatalf("expected metric %q, got %q", want[i], *ms[i])
+		}
 	}
 
-	found1 := false
-	found2 := false
-	for _, mf := range res {
-		if *mf.Name == "test1" {
-			found1 = true
+	// Label values resembling the label are no labels.
+	quoted := func(interface{}) []*Metric {
+		m, err := NewMetric("test3", []string{"other"}, []string{`x,label="y`}, 3)
+		if err != nil {
+			panic(err)
 		}
-		if *mf.Name == "test2" {
-			found2 = true
+		return []*Metric{m}
+	}
+	ms = LabeledGenerateFunc(quoted, "label", "other")(nil)
+	if w := "test3{label=\"other\",other=\"x,label=\\\"y\"} 3\n"; string(*ms[0]) != w {
+		t.Fatalf("expected metric %q, got %q", w, *ms[0])
+	}
+
+	names := metricNames(LabeledGenerateFunc(generateTestMetrics, "cluster", "prod")(nil))
+	if len(names) != 2 || !names["test1"] || !names["test2"] {
+		t.Fatalf("expected metric names to be unchanged, got %v", names)
+	}
+}
+
+func TestTimestampedGenerateFunc(t *testing.T) {
+	now := func() time.Time { return time.Unix(1500000000, 123456789) }
+	ms := TimestampedGenerateFunc(generateTestMetrics, now)(nil)
+
+	want := []string{
+		"test1{label=\"value\"} 1 1500000000123\n",
+		"test2 2 1500000000123\n",
+	}
+	if len(ms) != len(want) {
+		t.Fatalf("expected %d metrics, got %d", len(want), len(ms))
+	}
+	for i := range ms {
+		if string(*ms[i]) != want[i] {
+			t.Fatalf("expected metric %q, got %q", want[i], *ms[i])
 		}
 	}
 
-	if found1 || !found2 {
-		t.Fatalf("Expected `test1` to be filtered and `test2` not. `test1`: %t ; `test2`: %t.", found1, found2)
+	names := metricNames(FilteredGenerateFunc(TimestampedGenerateFunc(generateTestMetrics, now), mustMatcher(t, "test1", false), nil)(nil))
+	if len(names) != 1 || !names["test1"] {
+		t.Fatalf("expected only test1 to be whitelisted, got %v", names)
 	}
 }
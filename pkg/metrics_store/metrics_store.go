@@ -1,28 +1,51 @@
 package metricsstore
 
 import (
+	"strings"
 	"sync"
 
 	"k8s.io/kube-state-metrics/pkg/metrics"
 
 	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
 )
 
 // MetricsStore implements the k8s.io/kubernetes/client-go/tools/cache.Store
 // interface. Instead of storing entire Kubernetes objects, it stores metrics
-// generated based on them.
+// generated based on them. Objects for which the generate function returns
+// nil, e.g. objects of other shards, are not stored at all.
+//
+// The metrics of an object are only regenerated if its UID or resource version
+// changed, so that resyncs and relists of unchanged objects are cheap.
 type MetricsStore struct {
-	mutex   sync.RWMutex
-	metrics map[string][]*metrics.Metric
+	mutex sync.RWMutex
+	// metrics is keyed by the namespace and name of the objects, see key.
+	metrics map[string]entry
 
 	generateMetricsFunc func(interface{}) []*metrics.Metric
 }
 
+// entry holds the metrics generated for an object and the version of the
+// object they were generated for.
+type entry struct {
+	uid             types.UID
+	resourceVersion string
+	metrics         []*metrics.Metric
+}
+
+// generatedFor returns whether the metrics of the entry were generated for the
+// current version of o. Objects without resource version, e.g. of fake
+// clients, are always regenerated.
+func (e entry) generatedFor(o metav1.Object) bool {
+	return e.resourceVersion != "" && e.resourceVersion == o.GetResourceVersion() && e.uid == o.GetUID()
+}
+
 // NewMetricsStore returns a new MetricsStore
 func NewMetricsStore(generateFunc func(interface{}) []*metrics.Metric) *MetricsStore {
 	return &MetricsStore{
 		generateMetricsFunc: generateFunc,
-		metrics:             map[string][]*metrics.Metric{},
+		metrics:             map[string]entry{},
 	}
 }
 
@@ -35,16 +58,32 @@ func (s *MetricsStore) Add(obj interface{}) error {
 		return err
 	}
 
+	s.mutex.RLock()
+	e, ok := s.metrics[key(o)]
+	s.mutex.RUnlock()
+	if ok && e.generatedFor(o) {
+		return nil
+	}
+
+	s.set(obj, o)
+	return nil
+}
+
+// set generates and stores the metrics of obj, whose metadata is o.
+func (s *MetricsStore) set(obj interface{}, o metav1.Object) {
+	ms := s.generateMetricsFunc(obj)
+
 	s.mutex.Lock()
 	defer s.mutex.Unlock()
 
-	s.metrics[o.GetName()] = s.generateMetricsFunc(obj)
-
-	return nil
+	if ms == nil {
+		delete(s.metrics, key(o))
+		return
+	}
+	s.metrics[key(o)] = entry{uid: o.GetUID(), resourceVersion: o.GetResourceVersion(), metrics: ms}
 }
 
 func (s *MetricsStore) Update(obj interface{}) error {
-	// For now, just call Add, in the future one could check if the resource version changed?
 	return s.Add(obj)
 }
 
@@ -58,7 +97,7 @@ func (s *MetricsStore) Delete(obj interface{}) error {
 	s.mutex.Lock()
 	defer s.mutex.Unlock()
 
-	delete(s.metrics, o.GetName())
+	delete(s.metrics, key(o))
 
 	return nil
 }
@@ -67,8 +106,16 @@ func (s *MetricsStore) List() []interface{} {
 	return nil
 }
 
+// ListKeys returns the keys of the stored objects, see key.
 func (s *MetricsStore) ListKeys() []string {
-	return nil
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	keys := make([]string, 0, len(s.metrics))
+	for k := range s.metrics {
+		keys = append(keys, k)
+	}
+	return keys
 }
 
 func (s *MetricsStore) Get(obj interface{}) (item interface{}, exists bool, err error) {
@@ -82,20 +129,59 @@ func (s *MetricsStore) GetByKey(key string) (item interface{}, exists bool, err
 // Replace will delete the contents of the store, using instead the
 // given list. Store takes ownership of the list, you should not reference
 // it after calling this function.
-// TODO: Comment necessary?
-// TODO: What is 'name' for?
-func (s *MetricsStore) Replace(list []interface{}, name string) error {
-	s.mutex.Lock()
-	s.metrics = map[string][]*metrics.Metric{}
-	s.mutex.Unlock()
+func (s *MetricsStore) Replace(list []interface{}, resourceVersion string) error {
+	return s.ReplaceNamespace(metav1.NamespaceAll, list, resourceVersion)
+}
+
+// ReplaceNamespace is like Replace, but only deletes the objects of the given
+// namespace and of cluster-scoped resources, keeping the objects of other
+// namespaces. This allows the reflectors of several namespaces to share a
+// store.
+//
+// The replacement is built without holding the lock and swapped in at once, so
+// that concurrent scrapes see either the previous or the new objects, but no
+// partial list.
+func (s *MetricsStore) ReplaceNamespace(namespace string, list []interface{}, resourceVersion string) error {
+	replaced := func(k string) bool {
+		ns := keyNamespace(k)
+		return namespace == metav1.NamespaceAll || ns == "" || ns == namespace
+	}
 
-	for _, o := range list {
-		err := s.Add(o)
+	s.mutex.RLock()
+	previous := map[string]entry{}
+	for k, e := range s.metrics {
+		if replaced(k) {
+			previous[k] = e
+		}
+	}
+	s.mutex.RUnlock()
+
+	current := map[string]entry{}
+	for _, obj := range list {
+		o, err := meta.Accessor(obj)
 		if err != nil {
 			return err
 		}
+		// Keep the metrics of objects unchanged since the previous list.
+		if e, ok := previous[key(o)]; ok && e.generatedFor(o) {
+			current[key(o)] = e
+			continue
+		}
+		if ms := s.generateMetricsFunc(obj); ms != nil {
+			current[key(o)] = entry{uid: o.GetUID(), resourceVersion: o.GetResourceVersion(), metrics: ms}
+		}
 	}
 
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	for k := range s.metrics {
+		if replaced(k) {
+			delete(s.metrics, k)
+		}
+	}
+	for k, e := range current {
+		s.metrics[k] = e
+	}
 	return nil
 }
 
@@ -109,9 +195,46 @@ func (s *MetricsStore) GetAll() []*metrics.Metric {
 	s.mutex.RLock()
 	defer s.mutex.RUnlock()
 
-	for _, metrics := range s.metrics {
-		m = append(m, metrics...)
+	for _, e := range s.metrics {
+		m = append(m, e.metrics...)
 	}
 
 	return m
 }
+
+// CountByNamespace returns the number of stored objects per namespace.
+// Objects of cluster-scoped resources are not counted.
+func (s *MetricsStore) CountByNamespace() map[string]int {
+	counts := map[string]int{}
+
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	for k := range s.metrics {
+		if ns := keyNamespace(k); ns != "" {
+			counts[ns]++
+		}
+	}
+
+	return counts
+}
+
+// key returns the key of the given object in the store, i.e. <namespace>/<name>
+// or just <name> for objects of cluster-scoped resources. Keying by name only
+// would let objects of the same name in different namespaces overwrite each
+// other.
+func key(o metav1.Object) string {
+	if ns := o.GetNamespace(); ns != "" {
+		return ns + "/" + o.GetName()
+	}
+	return o.GetName()
+}
+
+// keyNamespace returns the namespace of the given key, see key. It is empty
+// for objects of cluster-scoped resources.
+func keyNamespace(k string) string {
+	if i := strings.Index(k, "/"); i != -1 {
+		return k[:i]
+	}
+	return ""
+}
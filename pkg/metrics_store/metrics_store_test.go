@@ -0,0 +1,161 @@
+package metricsstore
+
+import (
+	"reflect"
+	"sort"
+	"testing"
+
+	"k8s.io/kube-state-metrics/pkg/metrics"
+
+	"k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+func countingGenerateFunc(count *int) func(interface{}) []*metrics.Metric {
+	return func(obj interface{}) []*metrics.Metric {
+		*count++
+		cm := obj.(*v1.ConfigMap)
+		m, err := metrics.NewMetric("kube_configmap_info", []string{"namespace", "configmap"}, []string{cm.Namespace, cm.Name}, 1)
+		if err != nil {
+			panic(err)
+		}
+		return []*metrics.Metric{m}
+	}
+}
+
+func configMap(name, uid, resourceVersion string) *v1.ConfigMap {
+	return &v1.ConfigMap{ObjectMeta: metav1.ObjectMeta{
+		Namespace:       "default",
+		Name:            name,
+		UID:             types.UID(uid),
+		ResourceVersion: resourceVersion,
+	}}
+}
+
+func TestMetricsStoreRegeneratesChangedObjects(t *testing.T) {
+	tests := []struct {
+		Desc       string
+		Update     func(s *MetricsStore) error
+		WantedRuns int
+	}{
+		{
+			Desc:       "unchanged resource version",
+			Update:     func(s *MetricsStore) error { return s.Update(configMap("cm", "uid1", "1")) },
+			WantedRuns: 1,
+		},
+		{
+			Desc:       "changed resource version",
+			Update:     func(s *MetricsStore) error { return s.Update(configMap("cm", "uid1", "2")) },
+			WantedRuns: 2,
+		},
+		{
+			Desc:       "recreated object",
+			Update:     func(s *MetricsStore) error { return s.Update(configMap("cm", "uid2", "1")) },
+			WantedRuns: 2,
+		},
+		{
+			Desc:       "no resource version",
+			Update:     func(s *MetricsStore) error { return s.Update(configMap("cm", "uid1", "")) },
+			WantedRuns: 2,
+		},
+		{
+			Desc:       "relist of unchanged object",
+			Update:     func(s *MetricsStore) error { return s.Replace([]interface{}{configMap("cm", "uid1", "1")}, "") },
+			WantedRuns: 1,
+		},
+		{
+			Desc: "deleted and added again",
+			Update: func(s *MetricsStore) error {
+				if err := s.Delete(configMap("cm", "uid1", "1")); err != nil {
+					return err
+				}
+				return s.Add(configMap("cm", "uid1", "1"))
+			},
+			WantedRuns: 2,
+		},
+	}
+
+	for _, test := range tests {
+		runs := 0
+		s := NewMetricsStore(countingGenerateFunc(&runs))
+		if err := s.Add(configMap("cm", "uid1", "1")); err != nil {
+			t.Fatal(err)
+		}
+		if err := test.Update(s); err != nil {
+			t.Fatal(err)
+		}
+		if runs != test.WantedRuns {
+			t.Errorf("Test error for Desc: %s. Wanted %d generate runs, got %d", test.Desc, test.WantedRuns, runs)
+		}
+		if len(s.GetAll()) != 1 {
+			t.Errorf("Test error for Desc: %s. Wanted 1 metric, got %d", test.Desc, len(s.GetAll()))
+		}
+	}
+}
+
+func TestMetricsStoreReplaceRemovesObjects(t *testing.T) {
+	runs := 0
+	s := NewMetricsStore(countingGenerateFunc(&runs))
+	s.Replace([]interface{}{configMap("cm1", "uid1", "1"), configMap("cm2", "uid2", "1")}, "")
+	s.Replace([]interface{}{configMap("cm1", "uid1", "1")}, "")
+
+	if ms := s.GetAll(); len(ms) != 1 {
+		t.Errorf("expected the metric of the remaining object only, got %d metrics", len(ms))
+	}
+}
+
+func TestMetricsStoreReplaceNamespace(t *testing.T) {
+	runs := 0
+	s := NewMetricsStore(countingGenerateFunc(&runs))
+	cm := func(namespace, name string) *v1.ConfigMap {
+		return &v1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Namespace: namespace, Name: name}}
+	}
+	s.ReplaceNamespace("ns1", []interface{}{cm("ns1", "a"), cm("ns1", "b")}, "")
+	s.ReplaceNamespace("ns2", []interface{}{cm("ns2", "a")}, "")
+	s.ReplaceNamespace("ns1", []interface{}{cm("ns1", "a")}, "")
+
+	keys := s.ListKeys()
+	sort.Strings(keys)
+	if want := []string{"ns1/a", "ns2/a"}; !reflect.DeepEqual(keys, want) {
+		t.Errorf("expected keys %v after relisting ns1, got %v", want, keys)
+	}
+
+	s.Replace([]interface{}{cm("ns2", "b")}, "")
+	if keys, want := s.ListKeys(), []string{"ns2/b"}; !reflect.DeepEqual(keys, want) {
+		t.Errorf("expected keys %v after replacing all namespaces, got %v", want, keys)
+	}
+}
+
+func TestMetricsStoreReplaceAtomically(t *testing.T) {
+	runs := 0
+	generate := countingGenerateFunc(&runs)
+	generating, release := make(chan struct{}), make(chan struct{})
+	s := NewMetricsStore(func(obj interface{}) []*metrics.Metric {
+		// Block generating the metrics of the new object until the test has
+		// scraped the store.
+		if obj.(*v1.ConfigMap).Name == "cm3" {
+			close(generating)
+			<-release
+		}
+		return generate(obj)
+	})
+	s.Replace([]interface{}{configMap("cm1", "uid1", "1"), configMap("cm2", "uid2", "1")}, "")
+
+	done := make(chan error)
+	go func() {
+		done <- s.Replace([]interface{}{configMap("cm3", "uid3", "1"), configMap("cm1", "uid1", "1"), configMap("cm2", "uid2", "2")}, "")
+	}()
+
+	<-generating
+	if ms := s.GetAll(); len(ms) != 2 {
+		t.Errorf("expected the metrics of the previous list during the relist, got %v", ms)
+	}
+	close(release)
+	if err := <-done; err != nil {
+		t.Fatal(err)
+	}
+	if ms := s.GetAll(); len(ms) != 3 {
+		t.Errorf("expected the metrics of the new list after the relist, got %v", ms)
+	}
+}
@@ -23,25 +23,68 @@ import (
 var (
 	DefaultNamespaces = NamespaceList{metav1.NamespaceAll}
 	DefaultCollectors = CollectorSet{
+		"daemonsets":                      struct{}{},
+		"deployments":                     struct{}{},
+		"limitranges":                     struct{}{},
+		"nodes":                           struct{}{},
+		"pods":                            struct{}{},
+		"poddisruptionbudgets":            struct{}{},
+		"replicasets":                     struct{}{},
+		"replicationcontrollers":          struct{}{},
+		"resourcequotas":                  struct{}{},
+		"services":                        struct{}{},
+		"jobs":                            struct{}{},
+		"cronjobs":                        struct{}{},
+		"statefulsets":                    struct{}{},
+		"persistentvolumes":               struct{}{},
+		"persistentvolumeclaims":          struct{}{},
+		"namespaces":                      struct{}{},
+		"horizontalpodautoscalers":        struct{}{},
+		"endpoints":                       struct{}{},
+		"configmaps":                      struct{}{},
+		"mutatingwebhookconfigurations":   struct{}{},
+		"validatingwebhookconfigurations": struct{}{},
+		"priorityclasses":                 struct{}{},
+		"networkpolicies":                 struct{}{},
+		"apiservices":                     struct{}{},
+	}
+	// OptInCollectors are the collectors which are not enabled by default,
+	// but may be enabled with --collectors.
+	OptInCollectors = CollectorSet{
+		"clusterrolebindings":    struct{}{},
+		"clusterroles":           struct{}{},
+		"endpointslices":         struct{}{},
+		"leases":                 struct{}{},
+		"runtimeclasses":         struct{}{},
+		"secrets":                struct{}{},
+		"verticalpodautoscalers": struct{}{},
+		"volumeattachments":      struct{}{},
+	}
+	// AnnotationAllowlistCollectors are the collectors supporting
+	// --annotations-allowlist.
+	AnnotationAllowlistCollectors = CollectorSet{
+		"deployments": struct{}{},
+		"pods":        struct{}{},
+	}
+	// LabelsAllowlistCollectors are the collectors supporting
+	// --labels-allowlist, i.e. the ones exposing a kube_<resource>_labels
+	// metric.
+	LabelsAllowlistCollectors = CollectorSet{
+		"apiservices":              struct{}{},
+		"cronjobs":                 struct{}{},
 		"daemonsets":               struct{}{},
 		"deployments":              struct{}{},
-		"limitranges":              struct{}{},
+		"endpoints":                struct{}{},
+		"horizontalpodautoscalers": struct{}{},
+		"jobs":                     struct{}{},
+		"namespaces":               struct{}{},
+		"networkpolicies":          struct{}{},
 		"nodes":                    struct{}{},
+		"persistentvolumeclaims":   struct{}{},
+		"persistentvolumes":        struct{}{},
 		"pods":                     struct{}{},
-		"poddisruptionbudgets":     struct{}{},
-		"replicasets":              struct{}{},
-		"replicationcontrollers":   struct{}{},
-		"resourcequotas":           struct{}{},
+		"secrets":                  struct{}{},
 		"services":                 struct{}{},
-		"jobs":                     struct{}{},
-		"cronjobs":                 struct{}{},
 		"statefulsets":             struct{}{},
-		"persistentvolumes":        struct{}{},
-		"persistentvolumeclaims":   struct{}{},
-		"namespaces":               struct{}{},
-		"horizontalpodautoscalers": struct{}{},
-		"endpoints":                struct{}{},
-		"secrets":                  struct{}{},
-		"configmaps":               struct{}{},
 	}
 )
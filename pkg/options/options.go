@@ -20,34 +20,115 @@ import (
 	"flag"
 	"fmt"
 	"os"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/spf13/pflag"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/client-go/rest"
 )
 
+const (
+	// OutputFormatText exposes metrics in the Prometheus text format.
+	OutputFormatText = "text"
+	// OutputFormatJSON exposes metrics as a JSON array of metric families.
+	OutputFormatJSON = "json"
+	// OutputFormatOpenMetrics exposes metrics in the OpenMetrics text format.
+	OutputFormatOpenMetrics = "openmetrics"
+
+	// LogFormatText logs via glog.
+	LogFormatText = "text"
+	// LogFormatJSON logs JSON lines with level, timestamp and message.
+	LogFormatJSON = "json"
+
+	// ResourceScopeAll enables collectors of all resources.
+	ResourceScopeAll = "all"
+	// ResourceScopeCluster only enables collectors of cluster-scoped resources.
+	ResourceScopeCluster = "cluster"
+	// ResourceScopeNamespaced only enables collectors of namespaced resources.
+	ResourceScopeNamespaced = "namespaced"
+)
+
+var metricPrefixRE = regexp.MustCompile(`^[a-zA-Z_:][a-zA-Z0-9_:]*$`)
+
 type Options struct {
 	Apiserver                            string
+	APIServerQPS                         float32
+	APIServerBurst                       int
+	APIServerTimeout                     time.Duration
 	Kubeconfig                           string
+	KubeconfigContexts                   []string
+	ImpersonateUser                      string
+	ImpersonateGroups                    []string
+	ImpersonateUID                       string
 	Help                                 bool
 	Port                                 int
 	Host                                 string
 	TelemetryPort                        int
 	TelemetryHost                        string
+	MetricsPath                          string
+	MetricsPathMap                       MetricsPathMap
+	TelemetryPath                        string
+	DisableGolangTelemetry               bool
 	Collectors                           CollectorSet
 	Namespaces                           NamespaceList
+	NamespacesDenylist                   NamespaceList
 	MetricBlacklist                      MetricSet
 	MetricWhitelist                      MetricSet
+	MetricNameRegex                      bool
 	Version                              bool
 	DisablePodNonGenericResourceMetrics  bool
 	DisableNodeNonGenericResourceMetrics bool
+	UseInfoMetrics                       bool
+	EnableNamespaceAnnotations           bool
+	AnnotationsAllowlist                 AnnotationAllowlist
+	LabelsAllowlist                      LabelsAllowlist
+	ShutdownGracePeriod                  time.Duration
+	InitialSyncTimeout                   time.Duration
+	ResyncPeriod                         time.Duration
+	TLSCertFile                          string
+	TLSPrivateKeyFile                    string
+	TLSClientCAFile                      string
+	SocketMode                           FileMode
+	OutputFormat                         string
+	LogFormat                            string
+	Shard                                int
+	TotalShards                          int
+	PodName                              string
+	PodNamespace                         string
+	MetricPrefix                         string
+	MetricLabels                         MetricLabels
+	EnableMetricTimestamps               bool
+	DisableCreatedMetrics                bool
+	ScrapeTimeout                        time.Duration
+	MaxConcurrentScrapes                 int
+	LabelSelector                        string
+	FieldSelector                        string
+	ResourceName                         string
+	ResourceScope                        string
+	CustomResourceConfig                 string
+	CustomResourceConfigDir              string
 
 	flags *pflag.FlagSet
 }
 
 func NewOptions() *Options {
 	return &Options{
-		Collectors:      CollectorSet{},
-		MetricWhitelist: MetricSet{},
-		MetricBlacklist: MetricSet{},
+		Collectors:           CollectorSet{},
+		MetricWhitelist:      MetricSet{},
+		MetricBlacklist:      MetricSet{},
+		AnnotationsAllowlist: AnnotationAllowlist{},
+		LabelsAllowlist:      LabelsAllowlist{},
+		MetricLabels:         MetricLabels{},
+		MetricsPath:          "/metrics",
+		MetricsPathMap:       MetricsPathMap{},
+		TelemetryPath:        "/metrics",
+		SocketMode:           0660,
+		TotalShards:          1,
+		ResourceScope:        ResourceScopeAll,
 	}
 }
 
@@ -65,26 +146,223 @@ func (o *Options) AddFlags() {
 	}
 
 	o.flags.StringVar(&o.Apiserver, "apiserver", "", `The URL of the apiserver to use as a master`)
+	o.flags.Float32Var(&o.APIServerQPS, "apiserver-qps", 0, fmt.Sprintf("Maximum queries per second to the apiserver. 0 uses the client default of %v.", rest.DefaultQPS))
+	o.flags.IntVar(&o.APIServerBurst, "apiserver-burst", 0, fmt.Sprintf("Maximum burst of queries to the apiserver above --apiserver-qps. 0 uses the client default of %d.", rest.DefaultBurst))
+	o.flags.DurationVar(&o.APIServerTimeout, "apiserver-timeout", 0, "Timeout of requests to the apiserver. It applies to watches as well, which are hence restarted at the latest after this duration. 0 disables the timeout.")
 	o.flags.StringVar(&o.Kubeconfig, "kubeconfig", "", "Absolute path to the kubeconfig file")
+	o.flags.StringVar(&o.ImpersonateUser, "as", "", "User to impersonate for all requests to the apiserver, e.g. to test the RBAC rules of a constrained service account.")
+	o.flags.StringArrayVar(&o.ImpersonateGroups, "as-group", nil, "Group to impersonate for all requests to the apiserver. Can be repeated to impersonate several groups. Requires --as.")
+	o.flags.StringVar(&o.ImpersonateUID, "as-uid", "", "UID to impersonate for all requests to the apiserver. Requires --as.")
+	o.flags.StringArrayVar(&o.KubeconfigContexts, "kubeconfig-context", nil, "Context of the kubeconfig file to watch the cluster of. Can be repeated to watch several clusters, adding a cluster label with the context name to every metric. Defaults to the current context without cluster label.")
 	o.flags.BoolVarP(&o.Help, "help", "h", false, "Print Help text")
-	o.flags.IntVar(&o.Port, "port", 80, `Port to expose metrics on.`)
-	o.flags.StringVar(&o.Host, "host", "0.0.0.0", `Host to expose metrics on.`)
-	o.flags.IntVar(&o.TelemetryPort, "telemetry-port", 81, `Port to expose kube-state-metrics self metrics on.`)
-	o.flags.StringVar(&o.TelemetryHost, "telemetry-host", "0.0.0.0", `Host to expose kube-state-metrics self metrics on.`)
-	o.flags.Var(&o.Collectors, "collectors", fmt.Sprintf("Comma-separated list of collectors to be enabled. Defaults to %q", &DefaultCollectors))
+	o.flags.IntVar(&o.Port, "port", 80, `Port to expose metrics on. Use 0 to listen on a random free port, the resolved port is logged on startup.`)
+	o.flags.StringVar(&o.Host, "host", "0.0.0.0", `Host to expose metrics on, e.g. :: to listen on all IPv4 and IPv6 addresses. Use unix:///path/to/socket to listen on a Unix domain socket instead, ignoring --port.`)
+	o.flags.IntVar(&o.TelemetryPort, "telemetry-port", 81, `Port to expose kube-state-metrics self metrics on. Use 0 to listen on a random free port, the resolved port is logged on startup.`)
+	o.flags.StringVar(&o.TelemetryHost, "telemetry-host", "0.0.0.0", `Host to expose kube-state-metrics self metrics on, e.g. :: to listen on all IPv4 and IPv6 addresses. Use unix:///path/to/socket to listen on a Unix domain socket instead, ignoring --telemetry-port.`)
+	o.flags.StringVar(&o.MetricsPath, "metrics-path", o.MetricsPath, "Path to expose metrics on, e.g. when served behind a reverse proxy.")
+	o.flags.Var(&o.MetricsPathMap, "metrics-path-map", "Comma-separated list of collectors and the path to expose their metrics on instead of --metrics-path, e.g. deployments=/workloads,nodes=/infra, to scrape them at different intervals. Custom resource collectors are named <resource>.<group>. Collectors not listed are exposed on --metrics-path.")
+	o.flags.StringVar(&o.TelemetryPath, "telemetry-path", o.TelemetryPath, "Path to expose kube-state-metrics self metrics on.")
+	o.flags.BoolVar(&o.DisableGolangTelemetry, "disable-golang-telemetry", false, "Do not expose the go_* and process_* metrics of kube-state-metrics itself on the telemetry port.")
+	o.flags.Var(&o.SocketMode, "socket-mode", "File mode in octal notation of the Unix domain sockets created for --host and --telemetry-host.")
+	o.flags.Var(&o.Collectors, "collectors", fmt.Sprintf("Comma-separated list of collectors to be enabled. Defaults to %q. Opt-in collectors: %q", &DefaultCollectors, &OptInCollectors))
 	o.flags.Var(&o.Namespaces, "namespace", fmt.Sprintf("Comma-separated list of namespaces to be enabled. Defaults to %q", &DefaultNamespaces))
+	o.flags.Var(&o.NamespacesDenylist, "namespaces-denylist", "Comma-separated list of namespaces not to expose metrics of, neither of their objects nor of the namespaces themselves, watching all other namespaces. Mutually exclusive with --namespace.")
+	o.flags.StringVar(&o.LabelSelector, "label-selector", "", "Label selector restricting the watched objects of all collectors, e.g. 'app in (foo,bar),tier!=db'. Defaults to all objects.")
+	o.flags.StringVar(&o.FieldSelector, "field-selector", "", "Field selector restricting the watched objects of the pod and node collectors, e.g. 'spec.nodeName!=master'. Only field selectors supported by the API server for the respective resource work, e.g. spec.nodeName and status.phase for pods.")
+	o.flags.StringVar(&o.ResourceName, "resource-name", "", "Name of the single object to watch, e.g. to troubleshoot one deployment. Requires exactly one collector in --collectors and exactly one namespace in --namespace, which is ignored for cluster-scoped resources.")
+	o.flags.StringVar(&o.ResourceScope, "resource-scope", o.ResourceScope, fmt.Sprintf("Scope of the resources to enable the collectors of, either %q, %q for cluster-scoped resources only, e.g. nodes and persistentvolumes, or %q for namespaced resources only. Applies to custom resources as well.", ResourceScopeAll, ResourceScopeCluster, ResourceScopeNamespaced))
+	o.flags.StringVar(&o.CustomResourceConfig, "custom-resource-config", "", "YAML file describing the custom resources to expose metrics of. See the custom resource documentation for the format.")
+	o.flags.StringVar(&o.CustomResourceConfigDir, "custom-resource-config-dir", "", "Directory of custom resource config files (*.yaml, *.yml, *.json) in the format of --custom-resource-config. The directory is watched for changes, adding, updating and removing the collectors of the respective files at runtime.")
 	o.flags.Var(&o.MetricWhitelist, "metric-whitelist", "Comma-separated list of metrics to be exposed. The whitelist and blacklist are mutually exclusive.")
 	o.flags.Var(&o.MetricBlacklist, "metric-blacklist", "Comma-separated list of metrics not to be enabled. The whitelist and blacklist are mutually exclusive.")
+	o.flags.BoolVarP(&o.MetricNameRegex, "metric-name-regex", "", false, "Treat the entries of the metric whitelist and blacklist as regular expressions matching the whole metric name, instead of literal names with optional '*' wildcards. Commas within parentheses, braces or brackets don't separate entries.")
+	o.flags.StringVar(&o.MetricPrefix, "metric-prefix", "", "Prefix prepended to the name of every exposed metric, e.g. cluster_a_. The metric whitelist and blacklist match against the prefixed names.")
+	o.flags.Var(&o.MetricLabels, "metric-labels", "Comma-separated list of constant labels added to every exposed metric, e.g. region=us-east-1,env=prod. Metrics already having a label of the same name, e.g. namespace, keep their own value.")
+	o.flags.BoolVar(&o.EnableMetricTimestamps, "enable-metric-timestamps", false, "Expose every metric with the time its object was last observed as timestamp. Note that Prometheus does not mark series with explicit timestamps as stale when they disappear, and that the timestamps of objects not changing grow old.")
+	o.flags.BoolVar(&o.DisableCreatedMetrics, "disable-created-metrics", false, "Do not expose the kube_<resource>_created creation timestamp metrics of any collector, including custom resources.")
 	o.flags.BoolVarP(&o.Version, "version", "", false, "kube-state-metrics build version information")
 	o.flags.BoolVarP(&o.DisablePodNonGenericResourceMetrics, "disable-pod-non-generic-resource-metrics", "", false, "Disable pod non generic resource request and limit metrics")
 	o.flags.BoolVarP(&o.DisableNodeNonGenericResourceMetrics, "disable-node-non-generic-resource-metrics", "", false, "Disable node non generic resource request and limit metrics")
+	o.flags.BoolVar(&o.EnableNamespaceAnnotations, "enable-namespace-annotations", false, "Expose the annotations of namespaces as kube_namespace_annotations. Annotations may hold large values, e.g. kubectl's last-applied-configuration.")
+	o.flags.Var(&o.AnnotationsAllowlist, "annotations-allowlist", fmt.Sprintf("Annotation keys to expose as kube_<resource>_annotations, e.g. pods=annotation1,annotation2,deployments=annotation3. Annotations of other keys and collectors are not exposed. Supported collectors: %q", &AnnotationAllowlistCollectors))
+	o.flags.Var(&o.LabelsAllowlist, "labels-allowlist", fmt.Sprintf("Label keys to expose as kube_<resource>_labels, e.g. pods=label1,label2,deployments=label3. If set, the labels metrics of collectors not listed or listed without label keys are not generated. Defaults to exposing all labels. Supported collectors: %q", &LabelsAllowlistCollectors))
+	o.flags.BoolVar(&o.UseInfoMetrics, "use-info-metrics", false, "Experimental: Only expose descriptive attributes like the node of a pod on the respective *_info metric instead of also on the numeric per-container resource metrics, to be joined on the object labels.")
+	o.flags.StringVar(&o.TLSCertFile, "tls-cert-file", "", "File containing the x509 certificate to serve metrics and self metrics over HTTPS. Requires --tls-private-key-file.")
+	o.flags.StringVar(&o.TLSPrivateKeyFile, "tls-private-key-file", "", "File containing the x509 private key matching --tls-cert-file.")
+	o.flags.StringVar(&o.TLSClientCAFile, "tls-client-ca-file", "", "File containing the certificate authorities to verify client certificates against. Enables mutual TLS.")
+	o.flags.IntVar(&o.Shard, "shard", 0, "The shard index of this instance, counting from 0. Only objects belonging to this shard are exposed. Requires --total-shards. If unset, the shard is derived from the ordinal of --pod-name.")
+	o.flags.IntVar(&o.TotalShards, "total-shards", 1, "The total number of shards. Each object is assigned to a shard based on a hash of its UID. If greater than 1, requires --shard or --pod-name.")
+	o.flags.StringVar(&o.PodName, "pod-name", "", "Name of the pod running kube-state-metrics, usually populated from the downward API. When running as a StatefulSet, the ordinal of the pod is used as the shard if --shard is unset.")
+	o.flags.StringVar(&o.PodNamespace, "pod-namespace", "", "Namespace of the pod running kube-state-metrics, usually populated from the downward API.")
+	o.flags.StringVar(&o.OutputFormat, "output-format", OutputFormatText, fmt.Sprintf("Format to expose metrics in, either %q, %q or %q. Clients requesting application/openmetrics-text or application/json via the Accept header always get OpenMetrics or JSON respectively.", OutputFormatText, OutputFormatJSON, OutputFormatOpenMetrics))
+	o.flags.StringVar(&o.LogFormat, "log-format", LogFormatText, fmt.Sprintf("Format of the log output, either %q for the glog format or %q for JSON lines with level, timestamp and message fields on stderr. The glog flags only apply to the %q format. Log lines of the vendored client-go keep the glog format in either case.", LogFormatText, LogFormatJSON, LogFormatText))
+	o.flags.DurationVar(&o.ScrapeTimeout, "scrape-timeout", 0, "Maximum duration of collecting the metrics for a single scrape, after which the scrape fails with 503 Service Unavailable. 0 disables the timeout.")
+	o.flags.IntVar(&o.MaxConcurrentScrapes, "max-concurrent-scrapes", 0, "Maximum number of scrapes of the metrics endpoint served concurrently. Further scrapes fail with 429 Too Many Requests. 0 means no limit.")
+	o.flags.DurationVar(&o.ShutdownGracePeriod, "shutdown-grace-period", 10*time.Second, "Time to wait for in-flight scrapes to complete on SIGTERM before shutting down.")
+	o.flags.DurationVar(&o.InitialSyncTimeout, "initial-sync-timeout", 0, "Maximum duration for all collectors to complete the initial list of their objects, after which kube-state-metrics exits with code 3, e.g. on missing RBAC permissions. 0 waits forever.")
+	o.flags.DurationVar(&o.ResyncPeriod, "resync-period", 0, "Period after which all objects are relisted from the API server, recovering from missed watch events at the cost of additional API server load. 0 disables periodic relists.")
 }
 
 func (o *Options) Parse() error {
 	err := o.flags.Parse(os.Args)
-	return err
+	if err != nil {
+		return err
+	}
+
+	if len(o.NamespacesDenylist) > 0 && len(o.Namespaces) > 0 && !o.Namespaces.IsAllNamespaces() {
+		return fmt.Errorf("--namespace and --namespaces-denylist are mutually exclusive")
+	}
+
+	if len(o.ImpersonateGroups) > 0 && o.ImpersonateUser == "" {
+		return fmt.Errorf("--as-group requires --as")
+	}
+	if o.ImpersonateUID != "" && o.ImpersonateUser == "" {
+		return fmt.Errorf("--as-uid requires --as")
+	}
+
+	if len(o.KubeconfigContexts) > 0 && o.Apiserver != "" {
+		return fmt.Errorf("--apiserver and --kubeconfig-context are mutually exclusive")
+	}
+	if _, ok := o.MetricLabels["cluster"]; ok && len(o.KubeconfigContexts) > 0 {
+		return fmt.Errorf("--metric-labels must not set the cluster label added by --kubeconfig-context")
+	}
+	seenContexts := map[string]bool{}
+	for _, c := range o.KubeconfigContexts {
+		if c == "" {
+			return fmt.Errorf("--kubeconfig-context must not be empty")
+		}
+		if seenContexts[c] {
+			return fmt.Errorf("duplicate --kubeconfig-context %q", c)
+		}
+		seenContexts[c] = true
+	}
+
+	if _, err := labels.Parse(o.LabelSelector); err != nil {
+		return fmt.Errorf("invalid label selector: %v", err)
+	}
+
+	if _, err := fields.ParseSelector(o.FieldSelector); err != nil {
+		return fmt.Errorf("invalid field selector: %v", err)
+	}
+
+	if o.ResourceName != "" {
+		if len(o.Collectors) != 1 {
+			return fmt.Errorf("--resource-name requires exactly one collector, got %d", len(o.Collectors))
+		}
+		if len(o.Namespaces) != 1 || o.Namespaces.IsAllNamespaces() {
+			return fmt.Errorf("--resource-name requires exactly one namespace")
+		}
+	}
+
+	if _, err := o.MetricWhitelist.Matcher(o.MetricNameRegex); err != nil {
+		return fmt.Errorf("invalid metric whitelist: %v", err)
+	}
+	if _, err := o.MetricBlacklist.Matcher(o.MetricNameRegex); err != nil {
+		return fmt.Errorf("invalid metric blacklist: %v", err)
+	}
+
+	if !validPath(o.MetricsPath) {
+		return fmt.Errorf("--metrics-path must be an absolute path other than /, got %q", o.MetricsPath)
+	}
+	for col, path := range o.MetricsPathMap {
+		if !validPath(path) {
+			return fmt.Errorf("--metrics-path-map path of collector %s must be an absolute path other than /, got %q", col, path)
+		}
+	}
+	if !validPath(o.TelemetryPath) {
+		return fmt.Errorf("--telemetry-path must be an absolute path other than /, got %q", o.TelemetryPath)
+	}
+
+	if o.MetricPrefix != "" && !metricPrefixRE.MatchString(o.MetricPrefix) {
+		return fmt.Errorf("invalid metric prefix %q", o.MetricPrefix)
+	}
+
+	if (o.TLSCertFile == "") != (o.TLSPrivateKeyFile == "") {
+		return fmt.Errorf("--tls-cert-file and --tls-private-key-file have to be set together")
+	}
+	if o.TLSClientCAFile != "" && o.TLSCertFile == "" {
+		return fmt.Errorf("--tls-client-ca-file requires --tls-cert-file and --tls-private-key-file")
+	}
+
+	if o.TotalShards > 1 && !o.flags.Changed("shard") {
+		// Otherwise every instance would silently expose shard 0.
+		if o.PodName == "" {
+			return fmt.Errorf("--total-shards greater than 1 requires --shard or --pod-name")
+		}
+		shard, err := shardFromPodName(o.PodName)
+		if err != nil {
+			return fmt.Errorf("failed to derive shard from --pod-name: %v", err)
+		}
+		o.Shard = shard
+	}
+
+	if o.TotalShards < 1 {
+		return fmt.Errorf("--total-shards has to be at least 1, got %d", o.TotalShards)
+	}
+	if o.Shard < 0 || o.Shard >= o.TotalShards {
+		return fmt.Errorf("--shard has to be between 0 and %d, got %d", o.TotalShards-1, o.Shard)
+	}
+
+	if o.APIServerQPS < 0 {
+		return fmt.Errorf("--apiserver-qps must not be negative, got %v", o.APIServerQPS)
+	}
+	if o.APIServerBurst < 0 {
+		return fmt.Errorf("--apiserver-burst must not be negative, got %d", o.APIServerBurst)
+	}
+	if o.APIServerTimeout < 0 {
+		return fmt.Errorf("--apiserver-timeout must not be negative, got %v", o.APIServerTimeout)
+	}
+
+	if o.ScrapeTimeout < 0 {
+		return fmt.Errorf("--scrape-timeout must not be negative, got %v", o.ScrapeTimeout)
+	}
+
+	if o.InitialSyncTimeout < 0 {
+		return fmt.Errorf("--initial-sync-timeout must not be negative, got %v", o.InitialSyncTimeout)
+	}
+
+	if o.ResyncPeriod < 0 {
+		return fmt.Errorf("--resync-period must not be negative, got %v", o.ResyncPeriod)
+	}
+
+	if o.MaxConcurrentScrapes < 0 {
+		return fmt.Errorf("--max-concurrent-scrapes must not be negative, got %d", o.MaxConcurrentScrapes)
+	}
+
+	if o.OutputFormat != OutputFormatText && o.OutputFormat != OutputFormatJSON && o.OutputFormat != OutputFormatOpenMetrics {
+		return fmt.Errorf("invalid output format %q, has to be either %q, %q or %q", o.OutputFormat, OutputFormatText, OutputFormatJSON, OutputFormatOpenMetrics)
+	}
+
+	if o.LogFormat != LogFormatText && o.LogFormat != LogFormatJSON {
+		return fmt.Errorf("invalid log format %q, has to be either %q or %q", o.LogFormat, LogFormatText, LogFormatJSON)
+	}
+
+	if o.ResourceScope != ResourceScopeAll && o.ResourceScope != ResourceScopeCluster && o.ResourceScope != ResourceScopeNamespaced {
+		return fmt.Errorf("invalid resource scope %q, has to be either %q, %q or %q", o.ResourceScope, ResourceScopeAll, ResourceScopeCluster, ResourceScopeNamespaced)
+	}
+
+	return nil
+}
+
+// shardFromPodName returns the ordinal of a StatefulSet pod, which is the
+// numeric suffix of its name, e.g. 2 for "kube-state-metrics-2".
+func shardFromPodName(podName string) (int, error) {
+	i := strings.LastIndex(podName, "-")
+	if i == -1 {
+		return 0, fmt.Errorf("pod name %q has no ordinal suffix", podName)
+	}
+	ordinal, err := strconv.Atoi(podName[i+1:])
+	if err != nil || ordinal < 0 {
+		return 0, fmt.Errorf("pod name %q has no ordinal suffix", podName)
+	}
+	return ordinal, nil
 }
 
 func (o *Options) Usage() {
 	o.flags.Usage()
 }
+
+// validPath returns whether path can be served next to the index page at /.
+func validPath(path string) bool {
+	return strings.HasPrefix(path, "/") && path != "/"
+}
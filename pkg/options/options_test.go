@@ -18,8 +18,10 @@ package options
 
 import (
 	"os"
+	"reflect"
 	"sync"
 	"testing"
+	"time"
 
 	"github.com/spf13/pflag"
 )
@@ -73,3 +75,717 @@ func TestOptionsParse(t *testing.T) {
 		}
 	}
 }
+
+func TestOptionsParseTLS(t *testing.T) {
+	tests := []struct {
+		Desc        string
+		Args        []string
+		WantedError bool
+	}{
+		{
+			Desc:        "no tls flags",
+			Args:        []string{"./kube-state-metrics"},
+			WantedError: false,
+		},
+		{
+			Desc:        "cert and key",
+			Args:        []string{"./kube-state-metrics", "--tls-cert-file=tls.crt", "--tls-private-key-file=tls.key"},
+			WantedError: false,
+		},
+		{
+			Desc:        "cert, key and client ca",
+			Args:        []string{"./kube-state-metrics", "--tls-cert-file=tls.crt", "--tls-private-key-file=tls.key", "--tls-client-ca-file=ca.crt"},
+			WantedError: false,
+		},
+		{
+			Desc:        "cert without key",
+			Args:        []string{"./kube-state-metrics", "--tls-cert-file=tls.crt"},
+			WantedError: true,
+		},
+		{
+			Desc:        "key without cert",
+			Args:        []string{"./kube-state-metrics", "--tls-private-key-file=tls.key"},
+			WantedError: true,
+		},
+		{
+			Desc:        "client ca without cert and key",
+			Args:        []string{"./kube-state-metrics", "--tls-client-ca-file=ca.crt"},
+			WantedError: true,
+		},
+	}
+
+	for _, test := range tests {
+		opts := NewOptions()
+		opts.AddFlags()
+
+		os.Args = test.Args
+
+		err := opts.Parse()
+		if (err != nil) != test.WantedError {
+			t.Errorf("Test error for Desc: %s. Wanted Error: %v, Got Error: %v", test.Desc, test.WantedError, err)
+		}
+	}
+}
+
+func TestOptionsParseOutputFormat(t *testing.T) {
+	tests := []struct {
+		Desc        string
+		Args        []string
+		WantedError bool
+	}{
+		{
+			Desc:        "default output format",
+			Args:        []string{"./kube-state-metrics"},
+			WantedError: false,
+		},
+		{
+			Desc:        "text output format",
+			Args:        []string{"./kube-state-metrics", "--output-format=text"},
+			WantedError: false,
+		},
+		{
+			Desc:        "json output format",
+			Args:        []string{"./kube-state-metrics", "--output-format=json"},
+			WantedError: false,
+		},
+		{
+			Desc:        "openmetrics output format",
+			Args:        []string{"./kube-state-metrics", "--output-format=openmetrics"},
+			WantedError: false,
+		},
+		{
+			Desc:        "unknown output format",
+			Args:        []string{"./kube-state-metrics", "--output-format=yaml"},
+			WantedError: true,
+		},
+	}
+
+	for _, test := range tests {
+		opts := NewOptions()
+		opts.AddFlags()
+
+		os.Args = test.Args
+
+		err := opts.Parse()
+		if (err != nil) != test.WantedError {
+			t.Errorf("Test error for Desc: %s. Wanted Error: %v, Got Error: %v", test.Desc, test.WantedError, err)
+		}
+	}
+}
+
+func TestOptionsParseLogFormat(t *testing.T) {
+	tests := []struct {
+		Desc        string
+		Args        []string
+		WantedError bool
+	}{
+		{
+			Desc:        "default log format",
+			Args:        []string{"./kube-state-metrics"},
+			WantedError: false,
+		},
+		{
+			Desc:        "json log format",
+			Args:        []string{"./kube-state-metrics", "--log-format=json"},
+			WantedError: false,
+		},
+		{
+			Desc:        "unknown log format",
+			Args:        []string{"./kube-state-metrics", "--log-format=logfmt"},
+			WantedError: true,
+		},
+	}
+
+	for _, test := range tests {
+		opts := NewOptions()
+		opts.AddFlags()
+
+		os.Args = test.Args
+
+		err := opts.Parse()
+		if (err != nil) != test.WantedError {
+			t.Errorf("Test error for Desc: %s. Wanted Error: %v, Got Error: %v", test.Desc, test.WantedError, err)
+		}
+	}
+}
+
+func TestOptionsParseResourceScope(t *testing.T) {
+	tests := []struct {
+		Desc        string
+		Args        []string
+		WantedScope string
+		WantedError bool
+	}{
+		{
+			Desc:        "default resource scope",
+			Args:        []string{"./kube-state-metrics"},
+			WantedScope: ResourceScopeAll,
+			WantedError: false,
+		},
+		{
+			Desc:        "cluster resource scope",
+			Args:        []string{"./kube-state-metrics", "--resource-scope=cluster"},
+			WantedScope: ResourceScopeCluster,
+			WantedError: false,
+		},
+		{
+			Desc:        "namespaced resource scope",
+			Args:        []string{"./kube-state-metrics", "--resource-scope=namespaced"},
+			WantedScope: ResourceScopeNamespaced,
+			WantedError: false,
+		},
+		{
+			Desc:        "unknown resource scope",
+			Args:        []string{"./kube-state-metrics", "--resource-scope=namespace"},
+			WantedError: true,
+		},
+	}
+
+	for _, test := range tests {
+		opts := NewOptions()
+		opts.AddFlags()
+
+		os.Args = test.Args
+
+		err := opts.Parse()
+		if (err != nil) != test.WantedError {
+			t.Errorf("Test error for Desc: %s. Wanted Error: %v, Got Error: %v", test.Desc, test.WantedError, err)
+		}
+		if err == nil && opts.ResourceScope != test.WantedScope {
+			t.Errorf("Test error for Desc: %s. Wanted scope %q, got %q", test.Desc, test.WantedScope, opts.ResourceScope)
+		}
+	}
+}
+
+func TestOptionsParseResyncPeriod(t *testing.T) {
+	tests := []struct {
+		Desc         string
+		Args         []string
+		WantedPeriod time.Duration
+		WantedError  bool
+	}{
+		{
+			Desc:         "default resync period",
+			Args:         []string{"./kube-state-metrics"},
+			WantedPeriod: 0,
+			WantedError:  false,
+		},
+		{
+			Desc:         "resync period",
+			Args:         []string{"./kube-state-metrics", "--resync-period=30m"},
+			WantedPeriod: 30 * time.Minute,
+			WantedError:  false,
+		},
+		{
+			Desc:        "negative resync period",
+			Args:        []string{"./kube-state-metrics", "--resync-period=-1m"},
+			WantedError: true,
+		},
+	}
+
+	for _, test := range tests {
+		opts := NewOptions()
+		opts.AddFlags()
+
+		os.Args = test.Args
+
+		err := opts.Parse()
+		if (err != nil) != test.WantedError {
+			t.Errorf("Test error for Desc: %s. Wanted Error: %v, Got Error: %v", test.Desc, test.WantedError, err)
+		}
+		if err == nil && opts.ResyncPeriod != test.WantedPeriod {
+			t.Errorf("Test error for Desc: %s. Wanted resync period %v, got %v", test.Desc, test.WantedPeriod, opts.ResyncPeriod)
+		}
+	}
+}
+
+func TestOptionsParseKubeconfigContexts(t *testing.T) {
+	tests := []struct {
+		Desc           string
+		Args           []string
+		WantedContexts []string
+		WantedError    bool
+	}{
+		{
+			Desc:           "no contexts",
+			Args:           []string{"./kube-state-metrics"},
+			WantedContexts: nil,
+			WantedError:    false,
+		},
+		{
+			Desc:           "multiple contexts",
+			Args:           []string{"./kube-state-metrics", "--kubeconfig-context=a", "--kubeconfig-context=b"},
+			WantedContexts: []string{"a", "b"},
+			WantedError:    false,
+		},
+		{
+			Desc:        "duplicate contexts",
+			Args:        []string{"./kube-state-metrics", "--kubeconfig-context=a", "--kubeconfig-context=a"},
+			WantedError: true,
+		},
+		{
+			Desc:        "empty context",
+			Args:        []string{"./kube-state-metrics", "--kubeconfig-context="},
+			WantedError: true,
+		},
+		{
+			Desc:        "contexts with cluster metric label",
+			Args:        []string{"./kube-state-metrics", "--kubeconfig-context=a", "--metric-labels=cluster=prod"},
+			WantedError: true,
+		},
+		{
+			Desc:        "contexts with apiserver",
+			Args:        []string{"./kube-state-metrics", "--kubeconfig-context=a", "--apiserver=https://localhost:6443"},
+			WantedError: true,
+		},
+	}
+
+	for _, test := range tests {
+		opts := NewOptions()
+		opts.AddFlags()
+
+		os.Args = test.Args
+
+		err := opts.Parse()
+		if (err != nil) != test.WantedError {
+			t.Errorf("Test error for Desc: %s. Wanted Error: %v, Got Error: %v", test.Desc, test.WantedError, err)
+		}
+		if err == nil && !reflect.DeepEqual(opts.KubeconfigContexts, test.WantedContexts) {
+			t.Errorf("Test error for Desc: %s. Wanted contexts: %v, Got contexts: %v", test.Desc, test.WantedContexts, opts.KubeconfigContexts)
+		}
+	}
+}
+
+func TestOptionsParseImpersonation(t *testing.T) {
+	tests := []struct {
+		Desc        string
+		Args        []string
+		WantedUser  string
+		WantedGroup []string
+		WantedUID   string
+		WantedError bool
+	}{
+		{
+			Desc:        "no impersonation",
+			Args:        []string{"./kube-state-metrics"},
+			WantedError: false,
+		},
+		{
+			Desc:        "user and groups",
+			Args:        []string{"./kube-state-metrics", "--as=system:serviceaccount:kube-system:kube-state-metrics", "--as-group=a", "--as-group=b"},
+			WantedUser:  "system:serviceaccount:kube-system:kube-state-metrics",
+			WantedGroup: []string{"a", "b"},
+			WantedError: false,
+		},
+		{
+			Desc:        "user and uid",
+			Args:        []string{"./kube-state-metrics", "--as=jane", "--as-uid=1234"},
+			WantedUser:  "jane",
+			WantedUID:   "1234",
+			WantedError: false,
+		},
+		{
+			Desc:        "groups without user",
+			Args:        []string{"./kube-state-metrics", "--as-group=a"},
+			WantedError: true,
+		},
+		{
+			Desc:        "uid without user",
+			Args:        []string{"./kube-state-metrics", "--as-uid=1234"},
+			WantedError: true,
+		},
+	}
+
+	for _, test := range tests {
+		opts := NewOptions()
+		opts.AddFlags()
+
+		os.Args = test.Args
+
+		err := opts.Parse()
+		if (err != nil) != test.WantedError {
+			t.Errorf("Test error for Desc: %s. Wanted Error: %v, Got Error: %v", test.Desc, test.WantedError, err)
+		}
+		if err == nil && (opts.ImpersonateUser != test.WantedUser || !reflect.DeepEqual(opts.ImpersonateGroups, test.WantedGroup) || opts.ImpersonateUID != test.WantedUID) {
+			t.Errorf("Test error for Desc: %s. Wanted user %q, groups %v and uid %q, got user %q, groups %v and uid %q", test.Desc, test.WantedUser, test.WantedGroup, test.WantedUID, opts.ImpersonateUser, opts.ImpersonateGroups, opts.ImpersonateUID)
+		}
+	}
+}
+
+func TestOptionsParsePaths(t *testing.T) {
+	tests := []struct {
+		Desc                string
+		Args                []string
+		WantedMetricsPath   string
+		WantedTelemetryPath string
+		WantedError         bool
+	}{
+		{
+			Desc:                "default paths",
+			Args:                []string{"./kube-state-metrics"},
+			WantedMetricsPath:   "/metrics",
+			WantedTelemetryPath: "/metrics",
+		},
+		{
+			Desc:                "custom paths",
+			Args:                []string{"./kube-state-metrics", "--metrics-path=/ksm/metrics", "--telemetry-path=/ksm/telemetry"},
+			WantedMetricsPath:   "/ksm/metrics",
+			WantedTelemetryPath: "/ksm/telemetry",
+		},
+		{
+			Desc:        "relative metrics path",
+			Args:        []string{"./kube-state-metrics", "--metrics-path=metrics"},
+			WantedError: true,
+		},
+		{
+			Desc:        "root telemetry path",
+			Args:        []string{"./kube-state-metrics", "--telemetry-path=/"},
+			WantedError: true,
+		},
+	}
+
+	for _, test := range tests {
+		opts := NewOptions()
+		opts.AddFlags()
+
+		os.Args = test.Args
+
+		err := opts.Parse()
+		if (err != nil) != test.WantedError {
+			t.Errorf("Test error for Desc: %s. Wanted Error: %v, Got Error: %v", test.Desc, test.WantedError, err)
+		}
+		if err == nil && (opts.MetricsPath != test.WantedMetricsPath || opts.TelemetryPath != test.WantedTelemetryPath) {
+			t.Errorf("Test error for Desc: %s. Wanted paths: %s and %s, Got paths: %s and %s", test.Desc, test.WantedMetricsPath, test.WantedTelemetryPath, opts.MetricsPath, opts.TelemetryPath)
+		}
+	}
+}
+
+func TestOptionsParseSharding(t *testing.T) {
+	tests := []struct {
+		Desc        string
+		Args        []string
+		WantedShard int
+		WantedError bool
+	}{
+		{
+			Desc:        "no sharding",
+			Args:        []string{"./kube-state-metrics"},
+			WantedError: false,
+		},
+		{
+			Desc:        "last of three shards",
+			Args:        []string{"./kube-state-metrics", "--shard=2", "--total-shards=3"},
+			WantedShard: 2,
+			WantedError: false,
+		},
+		{
+			Desc:        "shard out of range",
+			Args:        []string{"./kube-state-metrics", "--shard=3", "--total-shards=3"},
+			WantedError: true,
+		},
+		{
+			Desc:        "negative shard",
+			Args:        []string{"./kube-state-metrics", "--shard=-1", "--total-shards=3"},
+			WantedError: true,
+		},
+		{
+			Desc:        "zero total shards",
+			Args:        []string{"./kube-state-metrics", "--total-shards=0"},
+			WantedError: true,
+		},
+		{
+			Desc:        "shard from pod name",
+			Args:        []string{"./kube-state-metrics", "--total-shards=3", "--pod-name=kube-state-metrics-2"},
+			WantedShard: 2,
+			WantedError: false,
+		},
+		{
+			Desc:        "explicit shard overrides pod name",
+			Args:        []string{"./kube-state-metrics", "--shard=1", "--total-shards=3", "--pod-name=kube-state-metrics-2"},
+			WantedShard: 1,
+			WantedError: false,
+		},
+		{
+			Desc:        "several shards without shard or pod name",
+			Args:        []string{"./kube-state-metrics", "--total-shards=3"},
+			WantedError: true,
+		},
+		{
+			Desc:        "pod name ordinal out of range",
+			Args:        []string{"./kube-state-metrics", "--total-shards=3", "--pod-name=kube-state-metrics-3"},
+			WantedError: true,
+		},
+		{
+			Desc:        "pod name without ordinal",
+			Args:        []string{"./kube-state-metrics", "--total-shards=3", "--pod-name=kube-state-metrics-7d9f8b6c4-x2k9p"},
+			WantedError: true,
+		},
+	}
+
+	for _, test := range tests {
+		opts := NewOptions()
+		opts.AddFlags()
+
+		os.Args = test.Args
+
+		err := opts.Parse()
+		if (err != nil) != test.WantedError {
+			t.Errorf("Test error for Desc: %s. Wanted Error: %v, Got Error: %v", test.Desc, test.WantedError, err)
+		}
+		if err == nil && opts.Shard != test.WantedShard {
+			t.Errorf("Test error for Desc: %s. Wanted Shard: %d, Got Shard: %d", test.Desc, test.WantedShard, opts.Shard)
+		}
+	}
+}
+
+func TestOptionsParseMetricPrefix(t *testing.T) {
+	tests := []struct {
+		Desc        string
+		Args        []string
+		WantedError bool
+	}{
+		{
+			Desc:        "no metric prefix",
+			Args:        []string{"./kube-state-metrics"},
+			WantedError: false,
+		},
+		{
+			Desc:        "valid metric prefix",
+			Args:        []string{"./kube-state-metrics", "--metric-prefix=cluster_a_"},
+			WantedError: false,
+		},
+		{
+			Desc:        "metric prefix with invalid characters",
+			Args:        []string{"./kube-state-metrics", "--metric-prefix=cluster-a-"},
+			WantedError: true,
+		},
+		{
+			Desc:        "metric prefix starting with a digit",
+			Args:        []string{"./kube-state-metrics", "--metric-prefix=1_"},
+			WantedError: true,
+		},
+	}
+
+	for _, test := range tests {
+		opts := NewOptions()
+		opts.AddFlags()
+
+		os.Args = test.Args
+
+		err := opts.Parse()
+		if (err != nil) != test.WantedError {
+			t.Errorf("Test error for Desc: %s. Wanted Error: %v, Got Error: %v", test.Desc, test.WantedError, err)
+		}
+	}
+}
+
+func TestOptionsParseLabelSelector(t *testing.T) {
+	tests := []struct {
+		Desc        string
+		Args        []string
+		WantedError bool
+	}{
+		{
+			Desc:        "no label selector",
+			Args:        []string{"./kube-state-metrics"},
+			WantedError: false,
+		},
+		{
+			Desc:        "set based label selector",
+			Args:        []string{"./kube-state-metrics", "--label-selector=app in (foo,bar),tier!=db"},
+			WantedError: false,
+		},
+		{
+			Desc:        "invalid label selector",
+			Args:        []string{"./kube-state-metrics", "--label-selector=app in foo"},
+			WantedError: true,
+		},
+	}
+
+	for _, test := range tests {
+		opts := NewOptions()
+		opts.AddFlags()
+
+		os.Args = test.Args
+
+		err := opts.Parse()
+		if (err != nil) != test.WantedError {
+			t.Errorf("Test error for Desc: %s. Wanted Error: %v, Got Error: %v", test.Desc, test.WantedError, err)
+		}
+	}
+}
+
+func TestOptionsParseFieldSelector(t *testing.T) {
+	tests := []struct {
+		Desc        string
+		Args        []string
+		WantedError bool
+	}{
+		{
+			Desc:        "no field selector",
+			Args:        []string{"./kube-state-metrics"},
+			WantedError: false,
+		},
+		{
+			Desc:        "valid field selector",
+			Args:        []string{"./kube-state-metrics", "--field-selector=spec.nodeName!=master,status.phase=Running"},
+			WantedError: false,
+		},
+		{
+			Desc:        "invalid field selector",
+			Args:        []string{"./kube-state-metrics", "--field-selector=spec.nodeName"},
+			WantedError: true,
+		},
+	}
+
+	for _, test := range tests {
+		opts := NewOptions()
+		opts.AddFlags()
+
+		os.Args = test.Args
+
+		err := opts.Parse()
+		if (err != nil) != test.WantedError {
+			t.Errorf("Test error for Desc: %s. Wanted Error: %v, Got Error: %v", test.Desc, test.WantedError, err)
+		}
+	}
+}
+
+func TestOptionsParseResourceName(t *testing.T) {
+	tests := []struct {
+		Desc        string
+		Args        []string
+		WantedError bool
+	}{
+		{
+			Desc:        "resource name with one collector and namespace",
+			Args:        []string{"./kube-state-metrics", "--resource-name=kube-dns", "--collectors=deployments", "--namespace=kube-system"},
+			WantedError: false,
+		},
+		{
+			Desc:        "resource name without collectors",
+			Args:        []string{"./kube-state-metrics", "--resource-name=kube-dns", "--namespace=kube-system"},
+			WantedError: true,
+		},
+		{
+			Desc:        "resource name with several collectors",
+			Args:        []string{"./kube-state-metrics", "--resource-name=kube-dns", "--collectors=deployments,pods", "--namespace=kube-system"},
+			WantedError: true,
+		},
+		{
+			Desc:        "resource name without namespace",
+			Args:        []string{"./kube-state-metrics", "--resource-name=kube-dns", "--collectors=deployments"},
+			WantedError: true,
+		},
+		{
+			Desc:        "resource name with several namespaces",
+			Args:        []string{"./kube-state-metrics", "--resource-name=kube-dns", "--collectors=deployments", "--namespace=default,kube-system"},
+			WantedError: true,
+		},
+	}
+
+	for _, test := range tests {
+		opts := NewOptions()
+		opts.AddFlags()
+
+		os.Args = test.Args
+
+		err := opts.Parse()
+		if (err != nil) != test.WantedError {
+			t.Errorf("Test error for Desc: %s. Wanted Error: %v, Got Error: %v", test.Desc, test.WantedError, err)
+		}
+	}
+}
+
+func TestOptionsParseNamespacesDenylist(t *testing.T) {
+	tests := []struct {
+		Desc        string
+		Args        []string
+		WantedError bool
+	}{
+		{
+			Desc:        "denylist only",
+			Args:        []string{"./kube-state-metrics", "--namespaces-denylist=kube-system,kube-public"},
+			WantedError: false,
+		},
+		{
+			Desc:        "denylist with all namespaces",
+			Args:        []string{"./kube-state-metrics", "--namespace=", "--namespaces-denylist=kube-system"},
+			WantedError: false,
+		},
+		{
+			Desc:        "denylist with allow-list",
+			Args:        []string{"./kube-state-metrics", "--namespace=default", "--namespaces-denylist=kube-system"},
+			WantedError: true,
+		},
+	}
+
+	for _, test := range tests {
+		opts := NewOptions()
+		opts.AddFlags()
+
+		os.Args = test.Args
+
+		err := opts.Parse()
+		if (err != nil) != test.WantedError {
+			t.Errorf("Test error for Desc: %s. Wanted Error: %v, Got Error: %v", test.Desc, test.WantedError, err)
+		}
+	}
+}
+
+func TestOptionsParseAPIServerClient(t *testing.T) {
+	tests := []struct {
+		Desc        string
+		Args        []string
+		WantedQPS   float32
+		WantedBurst int
+		WantedError bool
+	}{
+		{
+			Desc:        "client defaults",
+			Args:        []string{"./kube-state-metrics"},
+			WantedQPS:   0,
+			WantedBurst: 0,
+		},
+		{
+			Desc:        "qps, burst and timeout",
+			Args:        []string{"./kube-state-metrics", "--apiserver-qps=50.5", "--apiserver-burst=100", "--apiserver-timeout=30s"},
+			WantedQPS:   50.5,
+			WantedBurst: 100,
+		},
+		{
+			Desc:        "negative qps",
+			Args:        []string{"./kube-state-metrics", "--apiserver-qps=-1"},
+			WantedError: true,
+		},
+		{
+			Desc:        "negative burst",
+			Args:        []string{"./kube-state-metrics", "--apiserver-burst=-1"},
+			WantedError: true,
+		},
+		{
+			Desc:        "negative timeout",
+			Args:        []string{"./kube-state-metrics", "--apiserver-timeout=-1s"},
+			WantedError: true,
+		},
+	}
+
+	for _, test := range tests {
+		opts := NewOptions()
+		opts.AddFlags()
+
+		os.Args = test.Args
+
+		err := opts.Parse()
+		if (err != nil) != test.WantedError {
+			t.Errorf("Test error for Desc: %s. Wanted Error: %v, Got Error: %v", test.Desc, test.WantedError, err)
+			continue
+		}
+		if err != nil {
+			continue
+		}
+		if opts.APIServerQPS != test.WantedQPS || opts.APIServerBurst != test.WantedBurst {
+			t.Errorf("Test error for Desc: %s. Wanted QPS %v and burst %d, got QPS %v and burst %d", test.Desc, test.WantedQPS, test.WantedBurst, opts.APIServerQPS, opts.APIServerBurst)
+		}
+	}
+}
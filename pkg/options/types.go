@@ -17,7 +17,10 @@ limitations under the License.
 package options
 
 import (
+	"os"
+	"regexp"
 	"sort"
+	"strconv"
 	"strings"
 
 	"fmt"
@@ -34,9 +37,12 @@ func (ms *MetricSet) String() string {
 	return strings.Join(ss, ",")
 }
 
+// Set adds the comma-separated metrics in value to the set. Commas within
+// parentheses, braces or brackets don't separate metrics, so that regular
+// expressions like kube_pod_(a|b){1,2} stay intact.
 func (ms *MetricSet) Set(value string) error {
 	s := *ms
-	metrics := strings.Split(value, ",")
+	metrics := splitTopLevel(value)
 	for _, metric := range metrics {
 		metric = strings.TrimSpace(metric)
 		if len(metric) != 0 {
@@ -46,6 +52,35 @@ func (ms *MetricSet) Set(value string) error {
 	return nil
 }
 
+// splitTopLevel splits value at the commas not nested in parentheses, braces
+// or brackets. Escaped characters and the content of bracket expressions
+// don't change the nesting.
+func splitTopLevel(value string) []string {
+	parts := []string{}
+	depth, start := 0, 0
+	inBrackets := false
+	for i := 0; i < len(value); i++ {
+		switch c := value[i]; {
+		case c == '\\':
+			i++
+		case inBrackets:
+			if c == ']' {
+				inBrackets = false
+			}
+		case c == '[':
+			inBrackets = true
+		case c == '(' || c == '{':
+			depth++
+		case (c == ')' || c == '}') && depth > 0:
+			depth--
+		case c == ',' && depth == 0:
+			parts = append(parts, value[start:i])
+			start = i + 1
+		}
+	}
+	return append(parts, value[start:])
+}
+
 func (ms MetricSet) asSlice() []string {
 	metrics := []string{}
 	for metric := range ms {
@@ -62,6 +97,60 @@ func (ms *MetricSet) Type() string {
 	return "string"
 }
 
+// Matcher compiles the entries of the MetricSet into a MetricMatcher. If regex
+// is true, every entry is treated as a regular expression which has to match
+// the whole metric name. Otherwise entries are matched literally, except for
+// '*' matching any sequence of characters.
+func (ms MetricSet) Matcher(regex bool) (*MetricMatcher, error) {
+	m := &MetricMatcher{names: map[string]struct{}{}}
+	for metric := range ms {
+		pattern := metric
+		if !regex {
+			if !strings.Contains(metric, "*") {
+				m.names[metric] = struct{}{}
+				continue
+			}
+			pattern = strings.Replace(regexp.QuoteMeta(metric), `\*`, ".*", -1)
+		}
+		r, err := regexp.Compile("^(?:" + pattern + ")$")
+		if err != nil {
+			return nil, fmt.Errorf("invalid metric name pattern \"%s\": %v", metric, err)
+		}
+		m.regexps = append(m.regexps, r)
+	}
+	return m, nil
+}
+
+// MetricMatcher matches metric names against the compiled entries of a
+// MetricSet.
+type MetricMatcher struct {
+	names   map[string]struct{}
+	regexps []*regexp.Regexp
+}
+
+// IsEmpty returns true if the MetricMatcher has no entries. A nil
+// MetricMatcher is empty.
+func (m *MetricMatcher) IsEmpty() bool {
+	return m == nil || (len(m.names) == 0 && len(m.regexps) == 0)
+}
+
+// Matches returns true if the given metric name matches any of the entries of
+// the MetricMatcher.
+func (m *MetricMatcher) Matches(name string) bool {
+	if m == nil {
+		return false
+	}
+	if _, ok := m.names[name]; ok {
+		return true
+	}
+	for _, r := range m.regexps {
+		if r.MatchString(name) {
+			return true
+		}
+	}
+	return false
+}
+
 type CollectorSet map[string]struct{}
 
 func (c *CollectorSet) String() string {
@@ -77,8 +166,7 @@ func (c *CollectorSet) Set(value string) error {
 	for _, col := range cols {
 		col = strings.TrimSpace(col)
 		if len(col) != 0 {
-			_, ok := DefaultCollectors[col]
-			if !ok {
+			if !collectorExists(col) {
 				return fmt.Errorf("collector \"%s\" does not exist", col)
 			}
 			s[col] = struct{}{}
@@ -87,6 +175,13 @@ func (c *CollectorSet) Set(value string) error {
 	return nil
 }
 
+// collectorExists reports whether col is a default or opt-in collector.
+func collectorExists(col string) bool {
+	_, isDefault := DefaultCollectors[col]
+	_, isOptIn := OptInCollectors[col]
+	return isDefault || isOptIn
+}
+
 func (c CollectorSet) asSlice() []string {
 	cols := []string{}
 	for col := range c {
@@ -103,6 +198,182 @@ func (c *CollectorSet) Type() string {
 	return "string"
 }
 
+// AnnotationAllowlist maps collectors to the annotation keys to expose of
+// their objects, e.g. pods=annotation1,annotation2,deployments=annotation3.
+type AnnotationAllowlist map[string][]string
+
+func (a *AnnotationAllowlist) String() string {
+	return keyAllowlistString(*a)
+}
+
+func (a *AnnotationAllowlist) Set(value string) error {
+	return setKeyAllowlist(*a, value, AnnotationAllowlistCollectors, "annotation")
+}
+
+func (a *AnnotationAllowlist) Type() string {
+	return "string"
+}
+
+// LabelsAllowlist maps collectors to the label keys to expose of their
+// objects, e.g. pods=label1,label2,deployments=label3.
+type LabelsAllowlist map[string][]string
+
+func (l *LabelsAllowlist) String() string {
+	return keyAllowlistString(*l)
+}
+
+func (l *LabelsAllowlist) Set(value string) error {
+	return setKeyAllowlist(*l, value, LabelsAllowlistCollectors, "label")
+}
+
+func (l *LabelsAllowlist) Type() string {
+	return "string"
+}
+
+func keyAllowlistString(s map[string][]string) string {
+	cols := []string{}
+	for col := range s {
+		cols = append(cols, col)
+	}
+	sort.Strings(cols)
+	entries := []string{}
+	for _, col := range cols {
+		entries = append(entries, col+"="+strings.Join(s[col], ","))
+	}
+	return strings.Join(entries, ",")
+}
+
+// setKeyAllowlist adds the keys of value, formatted as
+// collector1=key1,key2,collector2=key3, to s. Every collector has to be one
+// of supported.
+func setKeyAllowlist(s map[string][]string, value string, supported CollectorSet, kind string) error {
+	col := ""
+	for _, entry := range strings.Split(value, ",") {
+		entry = strings.TrimSpace(entry)
+		if i := strings.Index(entry, "="); i != -1 {
+			col = strings.TrimSpace(entry[:i])
+			entry = strings.TrimSpace(entry[i+1:])
+			if _, ok := supported[col]; !ok {
+				return fmt.Errorf("collector \"%s\" does not support the %s allowlist", col, kind)
+			}
+			if _, ok := s[col]; !ok {
+				s[col] = []string{}
+			}
+		}
+		if len(entry) == 0 {
+			continue
+		}
+		if col == "" {
+			return fmt.Errorf("%s \"%s\" has no collector, expected collector=%s1,%s2", kind, entry, kind, kind)
+		}
+		s[col] = append(s[col], entry)
+	}
+	return nil
+}
+
+// MetricLabels are constant labels added to every metric, e.g.
+// region=us-east-1,env=prod.
+type MetricLabels map[string]string
+
+var labelNameRE = regexp.MustCompile("^[a-zA-Z_][a-zA-Z0-9_]*$")
+
+func (l *MetricLabels) String() string {
+	keys := []string{}
+	for k := range *l {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	pairs := []string{}
+	for _, k := range keys {
+		pairs = append(pairs, k+"="+(*l)[k])
+	}
+	return strings.Join(pairs, ",")
+}
+
+func (l *MetricLabels) Set(value string) error {
+	for _, pair := range strings.Split(value, ",") {
+		pair = strings.TrimSpace(pair)
+		if len(pair) == 0 {
+			continue
+		}
+		i := strings.Index(pair, "=")
+		if i == -1 {
+			return fmt.Errorf("metric label \"%s\" has no value, expected key=value", pair)
+		}
+		k := strings.TrimSpace(pair[:i])
+		if !labelNameRE.MatchString(k) || strings.HasPrefix(k, "__") {
+			return fmt.Errorf("invalid metric label name \"%s\"", k)
+		}
+		(*l)[k] = strings.TrimSpace(pair[i+1:])
+	}
+	return nil
+}
+
+func (l *MetricLabels) Type() string {
+	return "string"
+}
+
+// MetricsPathMap maps collectors to the path their metrics are exposed on
+// instead of the metrics path, e.g. deployments=/workloads,nodes=/infra.
+// Custom resource collectors are named <resource>.<group>, e.g.
+// foos.example.com=/custom.
+type MetricsPathMap map[string]string
+
+func (m *MetricsPathMap) String() string {
+	keys := []string{}
+	for k := range *m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	pairs := []string{}
+	for _, k := range keys {
+		pairs = append(pairs, k+"="+(*m)[k])
+	}
+	return strings.Join(pairs, ",")
+}
+
+func (m *MetricsPathMap) Set(value string) error {
+	for _, pair := range strings.Split(value, ",") {
+		pair = strings.TrimSpace(pair)
+		if len(pair) == 0 {
+			continue
+		}
+		i := strings.Index(pair, "=")
+		if i == -1 {
+			return fmt.Errorf("collector \"%s\" has no path, expected collector=path", pair)
+		}
+		col := strings.TrimSpace(pair[:i])
+		if col == "" {
+			return fmt.Errorf("path \"%s\" has no collector, expected collector=path", pair[i+1:])
+		}
+		// The custom resources are not known before their config is loaded,
+		// so every name with a group is accepted.
+		if !collectorExists(col) && !strings.Contains(col, ".") {
+			return fmt.Errorf("collector \"%s\" does not exist", col)
+		}
+		(*m)[col] = strings.TrimSpace(pair[i+1:])
+	}
+	return nil
+}
+
+func (m *MetricsPathMap) Type() string {
+	return "string"
+}
+
+// Paths returns the sorted distinct paths of the map.
+func (m MetricsPathMap) Paths() []string {
+	seen := map[string]bool{}
+	paths := []string{}
+	for _, p := range m {
+		if !seen[p] {
+			seen[p] = true
+			paths = append(paths, p)
+		}
+	}
+	sort.Strings(paths)
+	return paths
+}
+
 type NamespaceList []string
 
 func (n *NamespaceList) String() string {
@@ -127,3 +398,23 @@ func (n *NamespaceList) Set(value string) error {
 func (n *NamespaceList) Type() string {
 	return "string"
 }
+
+// FileMode is an os.FileMode in octal notation, e.g. 0660.
+type FileMode os.FileMode
+
+func (m *FileMode) String() string {
+	return fmt.Sprintf("%#o", uint32(*m))
+}
+
+func (m *FileMode) Set(value string) error {
+	mode, err := strconv.ParseUint(value, 8, 32)
+	if err != nil {
+		return fmt.Errorf("invalid file mode \"%s\": %v", value, err)
+	}
+	*m = FileMode(mode)
+	return nil
+}
+
+func (m *FileMode) Type() string {
+	return "string"
+}
@@ -45,6 +45,16 @@ func TestCollectorSetSet(t *testing.T) {
 			}),
 			WantedError: false,
 		},
+		{
+			Desc:  "opt-in collectors",
+			Value: "pods,secrets,leases",
+			Wanted: CollectorSet(map[string]struct{}{
+				"pods":    {},
+				"secrets": {},
+				"leases":  {},
+			}),
+			WantedError: false,
+		},
 		{
 			Desc:        "none exist collectors",
 			Value:       "none-exists",
@@ -61,3 +71,332 @@ func TestCollectorSetSet(t *testing.T) {
 		}
 	}
 }
+
+func TestMetricSetSet(t *testing.T) {
+	tests := []struct {
+		Desc   string
+		Value  string
+		Wanted MetricSet
+	}{
+		{
+			Desc:   "empty metrics",
+			Value:  "",
+			Wanted: MetricSet{},
+		},
+		{
+			Desc:  "normal metrics",
+			Value: "kube_pod_info, kube_node_info",
+			Wanted: MetricSet{
+				"kube_pod_info":  {},
+				"kube_node_info": {},
+			},
+		},
+		{
+			Desc:  "regexes with nested commas",
+			Value: "kube_pod_(a|b){1,2},kube_node_[,_]info,kube_(x\\(|y){1,}",
+			Wanted: MetricSet{
+				"kube_pod_(a|b){1,2}": {},
+				"kube_node_[,_]info":  {},
+				"kube_(x\\(|y){1,}":   {},
+			},
+		},
+	}
+
+	for _, test := range tests {
+		ms := MetricSet{}
+		if err := ms.Set(test.Value); err != nil {
+			t.Errorf("Test error for Desc: %s. Got Error: %v", test.Desc, err)
+			continue
+		}
+		if !reflect.DeepEqual(ms, test.Wanted) {
+			t.Errorf("Test error for Desc: %s. Want: %+v. Got: %+v.", test.Desc, test.Wanted, ms)
+		}
+	}
+}
+
+func TestMetricSetMatcher(t *testing.T) {
+	tests := []struct {
+		Desc        string
+		Value       string
+		Regex       bool
+		Name        string
+		Wanted      bool
+		WantedError bool
+	}{
+		{
+			Desc:   "exact name",
+			Value:  "kube_pod_info",
+			Name:   "kube_pod_info",
+			Wanted: true,
+		},
+		{
+			Desc:   "exact name not matching prefix",
+			Value:  "kube_pod",
+			Name:   "kube_pod_info",
+			Wanted: false,
+		},
+		{
+			Desc:   "glob",
+			Value:  "kube_pod_container_*",
+			Name:   "kube_pod_container_info",
+			Wanted: true,
+		},
+		{
+			Desc:   "glob dot is literal",
+			Value:  "kube.pod*",
+			Name:   "kube_pod_info",
+			Wanted: false,
+		},
+		{
+			Desc:   "regex",
+			Value:  "kube_.*_status_.*",
+			Regex:  true,
+			Name:   "kube_pod_status_phase",
+			Wanted: true,
+		},
+		{
+			Desc:   "regex with nested comma",
+			Value:  "kube_pod_(a|b){1,2}",
+			Regex:  true,
+			Name:   "kube_pod_ab",
+			Wanted: true,
+		},
+		{
+			Desc:        "invalid regex",
+			Value:       "kube_(pod",
+			Regex:       true,
+			WantedError: true,
+		},
+	}
+
+	for _, test := range tests {
+		ms := MetricSet{}
+		ms.Set(test.Value)
+		m, err := ms.Matcher(test.Regex)
+		if (err != nil) != test.WantedError {
+			t.Errorf("Test error for Desc: %s. Wanted Error: %v, Got Error: %v", test.Desc, test.WantedError, err)
+			continue
+		}
+		if err != nil {
+			continue
+		}
+		if got := m.Matches(test.Name); got != test.Wanted {
+			t.Errorf("Test error for Desc: %s. Want: %t. Got: %t.", test.Desc, test.Wanted, got)
+		}
+	}
+}
+
+func TestAnnotationAllowlistSet(t *testing.T) {
+	tests := []struct {
+		Desc        string
+		Value       string
+		Wanted      AnnotationAllowlist
+		WantedError bool
+	}{
+		{
+			Desc:   "empty allowlist",
+			Value:  "",
+			Wanted: AnnotationAllowlist{},
+		},
+		{
+			Desc:  "single collector",
+			Value: "pods=prometheus.io/scrape, example.com/team",
+			Wanted: AnnotationAllowlist{
+				"pods": {"prometheus.io/scrape", "example.com/team"},
+			},
+		},
+		{
+			Desc:  "multiple collectors",
+			Value: "pods=example.com/team,deployments=deployment.kubernetes.io/revision,example.com/team",
+			Wanted: AnnotationAllowlist{
+				"pods":        {"example.com/team"},
+				"deployments": {"deployment.kubernetes.io/revision", "example.com/team"},
+			},
+		},
+		{
+			Desc:  "collector without annotations",
+			Value: "pods=",
+			Wanted: AnnotationAllowlist{
+				"pods": {},
+			},
+		},
+		{
+			Desc:        "annotation without collector",
+			Value:       "example.com/team",
+			Wanted:      AnnotationAllowlist{},
+			WantedError: true,
+		},
+		{
+			Desc:        "unsupported collector",
+			Value:       "services=example.com/team",
+			Wanted:      AnnotationAllowlist{},
+			WantedError: true,
+		},
+	}
+
+	for _, test := range tests {
+		a := &AnnotationAllowlist{}
+		gotError := a.Set(test.Value)
+		if (gotError != nil) != test.WantedError || !reflect.DeepEqual(*a, test.Wanted) {
+			t.Errorf("Test error for Desc: %s. Want: %+v. Got: %+v. Wanted Error: %v, Got Error: %v", test.Desc, test.Wanted, *a, test.WantedError, gotError)
+		}
+	}
+}
+
+func TestLabelsAllowlistSet(t *testing.T) {
+	tests := []struct {
+		Desc        string
+		Value       string
+		Wanted      LabelsAllowlist
+		WantedError bool
+	}{
+		{
+			Desc:  "multiple collectors",
+			Value: "pods=app,tier,nodes=kubernetes.io/hostname,namespaces=",
+			Wanted: LabelsAllowlist{
+				"pods":       {"app", "tier"},
+				"nodes":      {"kubernetes.io/hostname"},
+				"namespaces": {},
+			},
+		},
+		{
+			Desc:        "collector without labels metric",
+			Value:       "configmaps=app",
+			Wanted:      LabelsAllowlist{},
+			WantedError: true,
+		},
+	}
+
+	for _, test := range tests {
+		l := &LabelsAllowlist{}
+		gotError := l.Set(test.Value)
+		if (gotError != nil) != test.WantedError || !reflect.DeepEqual(*l, test.Wanted) {
+			t.Errorf("Test error for Desc: %s. Want: %+v. Got: %+v. Wanted Error: %v, Got Error: %v", test.Desc, test.Wanted, *l, test.WantedError, gotError)
+		}
+	}
+}
+
+func TestMetricLabelsSet(t *testing.T) {
+	tests := []struct {
+		Desc        string
+		Value       string
+		Wanted      MetricLabels
+		WantedError bool
+	}{
+		{
+			Desc:   "multiple labels",
+			Value:  "region=us-east-1, env=prod",
+			Wanted: MetricLabels{"region": "us-east-1", "env": "prod"},
+		},
+		{
+			Desc:   "empty value",
+			Value:  "env=",
+			Wanted: MetricLabels{"env": ""},
+		},
+		{
+			Desc:        "missing value",
+			Value:       "region",
+			Wanted:      MetricLabels{},
+			WantedError: true,
+		},
+		{
+			Desc:        "invalid label name",
+			Value:       "kubernetes.io/region=us-east-1",
+			Wanted:      MetricLabels{},
+			WantedError: true,
+		},
+		{
+			Desc:        "reserved label name",
+			Value:       "__name__=foo",
+			Wanted:      MetricLabels{},
+			WantedError: true,
+		},
+	}
+
+	for _, test := range tests {
+		l := &MetricLabels{}
+		gotError := l.Set(test.Value)
+		if (gotError != nil) != test.WantedError || !reflect.DeepEqual(*l, test.Wanted) {
+			t.Errorf("Test error for Desc: %s. Want: %+v. Got: %+v. Wanted Error: %v, Got Error: %v", test.Desc, test.Wanted, *l, test.WantedError, gotError)
+		}
+	}
+}
+
+func TestMetricsPathMapSet(t *testing.T) {
+	tests := []struct {
+		Desc        string
+		Value       string
+		Wanted      MetricsPathMap
+		WantedError bool
+	}{
+		{
+			Desc:   "multiple collectors",
+			Value:  "deployments=/workloads, statefulsets=/workloads,nodes=/infra",
+			Wanted: MetricsPathMap{"deployments": "/workloads", "statefulsets": "/workloads", "nodes": "/infra"},
+		},
+		{
+			Desc:   "opt-in and custom resource collectors",
+			Value:  "secrets=/secrets,foos.example.com=/custom",
+			Wanted: MetricsPathMap{"secrets": "/secrets", "foos.example.com": "/custom"},
+		},
+		{
+			Desc:        "unknown collectors",
+			Value:       "deployment=/workloads,node=/infra",
+			Wanted:      MetricsPathMap{},
+			WantedError: true,
+		},
+		{
+			Desc:        "missing path",
+			Value:       "deployments",
+			Wanted:      MetricsPathMap{},
+			WantedError: true,
+		},
+		{
+			Desc:        "missing collector",
+			Value:       "=/workloads",
+			Wanted:      MetricsPathMap{},
+			WantedError: true,
+		},
+	}
+
+	for _, test := range tests {
+		m := &MetricsPathMap{}
+		gotError := m.Set(test.Value)
+		if (gotError != nil) != test.WantedError || !reflect.DeepEqual(*m, test.Wanted) {
+			t.Errorf("Test error for Desc: %s. Want: %+v. Got: %+v. Wanted Error: %v, Got Error: %v", test.Desc, test.Wanted, *m, test.WantedError, gotError)
+		}
+	}
+}
+
+func TestFileModeSet(t *testing.T) {
+	tests := []struct {
+		Desc        string
+		Value       string
+		Wanted      FileMode
+		WantedError bool
+	}{
+		{
+			Desc:   "octal with leading zero",
+			Value:  "0660",
+			Wanted: 0660,
+		},
+		{
+			Desc:   "octal without leading zero",
+			Value:  "777",
+			Wanted: 0777,
+		},
+		{
+			Desc:        "not octal",
+			Value:       "0698",
+			WantedError: true,
+		},
+	}
+
+	for _, test := range tests {
+		var m FileMode
+		err := m.Set(test.Value)
+		if (err != nil) != test.WantedError || (err == nil && m != test.Wanted) {
+			t.Errorf("Test error for Desc: %s. Want: %v. Got: %v. Wanted Error: %v, Got Error: %v", test.Desc, &test.Wanted, &m, test.WantedError, err)
+		}
+	}
+}
@@ -18,6 +18,8 @@ import (
 	"os"
 	"path/filepath"
 	"runtime"
+
+	"github.com/prometheus/client_golang/prometheus"
 )
 
 var (
@@ -25,12 +27,15 @@ var (
 	Release = "UNKNOWN"
 	// Commit returns the short sha from git
 	Commit = "UNKNOWN"
+	// Branch returns the git branch
+	Branch = "UNKNOWN"
 	// BuildDate is the build date
 	BuildDate = ""
 )
 
 type Version struct {
 	GitCommit string
+	GitBranch string
 	BuildDate string
 	Release   string
 	GoVersion string
@@ -48,6 +53,7 @@ func (v Version) String() string {
 func GetVersion() Version {
 	return Version{
 		GitCommit: Commit,
+		GitBranch: Branch,
 		BuildDate: BuildDate,
 		Release:   Release,
 		GoVersion: runtime.Version(),
@@ -55,3 +61,24 @@ func GetVersion() Version {
 		Platform:  fmt.Sprintf("%s/%s", runtime.GOOS, runtime.GOARCH),
 	}
 }
+
+// NewBuildInfoCollector returns a collector exposing the
+// kube_state_metrics_build_info metric with a constant value of 1, labeled
+// with the version of the running kube-state-metrics. It mirrors the
+// prometheus.NewBuildInfoCollector of newer client_golang versions.
+func NewBuildInfoCollector() prometheus.Collector {
+	v := GetVersion()
+	return prometheus.NewGaugeFunc(
+		prometheus.GaugeOpts{
+			Name: "kube_state_metrics_build_info",
+			Help: "A metric with a constant '1' value labeled by version, revision, branch, and goversion from which kube-state-metrics was built.",
+			ConstLabels: prometheus.Labels{
+				"version":   v.Release,
+				"revision":  v.GitCommit,
+				"branch":    v.GitBranch,
+				"goversion": v.GoVersion,
+			},
+		},
+		func() float64 { return 1 },
+	)
+}
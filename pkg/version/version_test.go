@@ -0,0 +1,60 @@
+/*
+Copyright 2017 The Kubernetes Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package version
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+func TestNewBuildInfoCollector(t *testing.T) {
+	Release, Commit, Branch = "v1.5.0", "abc1234", "release-1.5"
+	defer func() { Release, Commit, Branch = "UNKNOWN", "UNKNOWN", "UNKNOWN" }()
+
+	r := prometheus.NewRegistry()
+	if err := r.Register(NewBuildInfoCollector()); err != nil {
+		t.Fatal(err)
+	}
+	mfs, err := r.Gather()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(mfs) != 1 || mfs[0].GetName() != "kube_state_metrics_build_info" || len(mfs[0].GetMetric()) != 1 {
+		t.Fatalf("expected a single kube_state_metrics_build_info metric, got %v", mfs)
+	}
+
+	m := mfs[0].GetMetric()[0]
+	if got := m.GetGauge().GetValue(); got != 1 {
+		t.Errorf("expected value 1, got %v", got)
+	}
+	got := map[string]string{}
+	for _, l := range m.GetLabel() {
+		got[l.GetName()] = l.GetValue()
+	}
+	v := GetVersion()
+	want := map[string]string{
+		"version":   v.Release,
+		"revision":  v.GitCommit,
+		"branch":    v.GitBranch,
+		"goversion": v.GoVersion,
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("expected labels %v, got %v", want, got)
+	}
+	if got["version"] != "v1.5.0" || got["revision"] != "abc1234" || got["branch"] != "release-1.5" {
+		t.Errorf("expected labels populated from the build variables, got %v", got)
+	}
+}